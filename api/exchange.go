@@ -1,6 +1,7 @@
 package api
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
@@ -88,6 +89,13 @@ type FillTrackable interface {
 	GetLatestTradeCursor() (interface{}, error)
 }
 
+// FillStreamable enables any implementing exchange to push fills to the FillTracker as they happen instead of waiting to be polled
+type FillStreamable interface {
+	// StreamFills blocks until the stream disconnects or the context is cancelled, calling onConnect once the stream is established and onFill
+	// with the cursor of every new fill after the passed in cursor
+	StreamFills(ctx context.Context, cursor string, onConnect func(), onFill func(cursor string)) error
+}
+
 // Constrainable extracts out the method that SDEX can implement for now
 type Constrainable interface {
 	// return nil if the constraint does not exist for the exchange
@@ -238,6 +246,17 @@ type ExchangeShim interface {
 	FillTrackable
 }
 
+// ManageBuyOfferBuilder wraps a txnbuild.ManageBuyOffer so it can be passed around as a build.TransactionMutator.
+// The old SDK has no manage buy offer operation, so this is only a carrier until it is converted back with ConvertSellOfferBuildersToSellOps
+type ManageBuyOfferBuilder struct {
+	Op *txnbuild.ManageBuyOffer
+}
+
+// MutateTransaction impl, the old SDK's TransactionBuilder cannot build a ManageBuyOffer so this always returns an error
+func (b ManageBuyOfferBuilder) MutateTransaction(t *build.TransactionBuilder) error {
+	return fmt.Errorf("ManageBuyOffer cannot be added to the old SDK's TransactionBuilder, convert it to a txnbuild.Operation first: %v", b.Op)
+}
+
 // Tthe basics off any type of offer (buy, sell, passive sell)
 type OfferBasics struct {
 	Selling      build.Asset
@@ -252,6 +271,12 @@ type OfferBasics struct {
 func ConvertOperation2TM(ops []txnbuild.Operation) []build.TransactionMutator {
 	muts := []build.TransactionMutator{}
 	for _, o := range ops {
+		if manageBuyOffer, ok := o.(*txnbuild.ManageBuyOffer); ok {
+			// the old SDK has no representation for a ManageBuyOffer so we carry it through as-is
+			muts = append(muts, ManageBuyOfferBuilder{Op: manageBuyOffer})
+			continue
+		}
+
 		var mob build.ManageOfferBuilder
 		log.Printf("*******************1-exchange.ConvertOperation2TM - op type: %s", fmt.Sprintf("%T", o))
 		var isPassiveSell bool
@@ -426,7 +451,7 @@ func ConvertMOB2PSO(mob build.ManageOfferBuilder) *txnbuild.CreatePassiveSellOff
 	return pso
 }
 
-// ConvertSellOfferBuildersToSellOps converts manage sell offers into Operations.
+// ConvertSellOfferBuildersToSellOps converts manage sell offers (and any wrapped manage buy offers) into Operations.
 func ConvertSellOfferBuildersToSellOps(muts []build.TransactionMutator) []txnbuild.Operation {
 	ops := []txnbuild.Operation{}
 
@@ -445,6 +470,10 @@ func ConvertSellOfferBuildersToSellOps(muts []build.TransactionMutator) []txnbui
 			} else {
 				ops = append(ops, ConvertMOB2MSO(*mob))
 			}
+		} else if mbob, ok := m.(ManageBuyOfferBuilder); ok {
+			ops = append(ops, mbob.Op)
+		} else if mbob, ok := m.(*ManageBuyOfferBuilder); ok {
+			ops = append(ops, mbob.Op)
 		} else {
 			panic(fmt.Sprintf("could not convert build.TransactionMutator to txnbuild.Operation: %v (type=%T)\n", m, m))
 		}
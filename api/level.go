@@ -13,3 +13,9 @@ type LevelProvider interface {
 	GetLevels(maxAssetBase float64, maxAssetQuote float64) ([]Level, error)
 	GetFillHandlers() ([]FillHandler, error)
 }
+
+// SpreadWidener provides an extra spread that is added to the spread of every level, such as while recovering from a halt
+type SpreadWidener interface {
+	// Widening returns the extra spread as a fraction (ex: 0.01 for 1%), which is 0.0 when spreads should not be widened
+	Widening() float64
+}
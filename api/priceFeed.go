@@ -1,12 +1,21 @@
 package api
 
-import "log"
+import (
+	"log"
+	"time"
+)
 
 // PriceFeed allows you to fetch the price of a feed
 type PriceFeed interface {
 	GetPrice() (float64, error)
 }
 
+// TimestampedPriceFeed is implemented by price feeds whose source reports when the price was last updated
+type TimestampedPriceFeed interface {
+	PriceFeed
+	GetPriceWithTimestamp() (float64, time.Time, error)
+}
+
 // TODO this should be structured as a specific impl. of the PriceFeed interface
 // FeedPair is the struct representing a price feed for a trading pair
 type FeedPair struct {
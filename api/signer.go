@@ -0,0 +1,14 @@
+package api
+
+import (
+	"github.com/stellar/go/txnbuild"
+)
+
+// Signer signs transactions on behalf of accounts so callers never need to handle the secret keys directly
+type Signer interface {
+	// Sign returns a copy of the transaction with the signatures of the passed in accounts added to it
+	Sign(tx *txnbuild.Transaction, network string, accounts ...string) (*txnbuild.Transaction, error)
+
+	// SignFeeBump returns a copy of the fee bump transaction with the signatures of the passed in accounts added to it
+	SignFeeBump(tx *txnbuild.FeeBumpTransaction, network string, accounts ...string) (*txnbuild.FeeBumpTransaction, error)
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/support/config"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/api"
+	"github.com/stellar/kelp/support/logger"
+	"github.com/stellar/kelp/support/utils"
+	"github.com/stellar/kelp/trader"
+)
+
+// maxChannelAccounts is capped by the max number of operations allowed in a single transaction
+const maxChannelAccounts = 100
+
+var channelsCmd = &cobra.Command{
+	Use:   "channels",
+	Short: "Creates and funds channel accounts used to submit transactions in parallel",
+	Long: `Creates and funds channel accounts from the source account (or trading account if no source account is set) in the trader config file.
+The secret seeds of the newly created channel accounts are printed out so they can be added to the CHANNEL_SECRET_SEEDS field in the trader config file when using the default seed signer.
+When using a keystore or remote signer, add the secret seeds to the signer instead and set the printed out CHANNEL_ACCOUNTS field in the trader config file.`,
+}
+
+func init() {
+	botConfigPath := channelsCmd.Flags().StringP("botConf", "c", "", "trading bot's basic config file path")
+	numChannels := channelsCmd.Flags().Uint8P("num", "n", 5, "number of channel accounts to create")
+	startingBalance := channelsCmd.Flags().Float64P("balance", "b", 5.0, "starting balance in XLM to fund each channel account with, this is used to pay fees")
+
+	requiredFlag := func(flag string) {
+		e := channelsCmd.MarkFlagRequired(flag)
+		if e != nil {
+			panic(e)
+		}
+	}
+	requiredFlag("botConf")
+
+	channelsCmd.Run = func(ccmd *cobra.Command, args []string) {
+		if *numChannels == 0 || *numChannels > maxChannelAccounts {
+			log.Fatalf("num needs to be between 1 and %d (inclusive), was %d\n", maxChannelAccounts, *numChannels)
+		}
+		if *startingBalance <= 1.0 {
+			log.Fatalf("balance needs to be more than the minimum account balance of 1 XLM, was %.7f\n", *startingBalance)
+		}
+
+		var botConfig trader.BotConfig
+		e := config.Read(*botConfigPath, &botConfig)
+		utils.CheckConfigError(botConfig, e, *botConfigPath)
+		e = botConfig.Init()
+		if e != nil {
+			log.Fatal(e)
+		}
+
+		fundingAccount := botConfig.SourceAccount()
+		if fundingAccount == "" {
+			fundingAccount = botConfig.TradingAccount()
+		}
+		signer := makeSigner(logger.MakeBasicLogger(), botConfig)
+
+		client := &horizonclient.Client{
+			HorizonURL: botConfig.HorizonURL,
+			HTTP:       http.DefaultClient,
+			AppName:    "kelp--cli--channels",
+			AppVersion: version,
+		}
+		network := utils.ResolveNetwork(botConfig.NetworkPassphrase, botConfig.HorizonURL)
+
+		seeds, e := createChannelAccounts(client, network, fundingAccount, signer, int(*numChannels), *startingBalance)
+		if e != nil {
+			log.Fatalf("unable to create channel accounts: %s\n", e)
+		}
+
+		log.Printf("created %d channel accounts, add the CHANNEL_SECRET_SEEDS line to your trader config file when using the default seed signer, otherwise add the secret seeds to your signer and add the CHANNEL_ACCOUNTS line:\n", len(seeds))
+		quotedSeeds := []string{}
+		quotedAddresses := []string{}
+		for _, s := range seeds {
+			quotedSeeds = append(quotedSeeds, fmt.Sprintf("%q", s))
+			quotedAddresses = append(quotedAddresses, fmt.Sprintf("%q", keypair.MustParse(s).Address()))
+		}
+		fmt.Printf("CHANNEL_SECRET_SEEDS=[%s]\n", strings.Join(quotedSeeds, ", "))
+		fmt.Printf("CHANNEL_ACCOUNTS=[%s]\n", strings.Join(quotedAddresses, ", "))
+	}
+}
+
+// createChannelAccounts creates and funds the channel accounts in a single transaction and returns their secret seeds
+func createChannelAccounts(
+	client *horizonclient.Client,
+	network string,
+	fundingAccount string,
+	signer api.Signer,
+	numChannels int,
+	startingBalance float64,
+) ([]string, error) {
+	accountDetail, e := client.AccountDetail(horizonclient.AccountRequest{AccountID: fundingAccount})
+	if e != nil {
+		return nil, fmt.Errorf("error loading account detail for funding account %s: %s", fundingAccount, e)
+	}
+
+	seeds := []string{}
+	ops := []txnbuild.Operation{}
+	for i := 0; i < numChannels; i++ {
+		kp, e := keypair.Random()
+		if e != nil {
+			return nil, fmt.Errorf("unable to generate keypair for channel account at index %d: %s", i, e)
+		}
+		seeds = append(seeds, kp.Seed())
+		ops = append(ops, &txnbuild.CreateAccount{
+			Destination: kp.Address(),
+			Amount:      strconv.FormatFloat(startingBalance, 'f', int(utils.SdexPrecision), 64),
+		})
+		log.Printf("channel account %d: %s\n", i+1, kp.Address())
+	}
+
+	tx, e := txnbuild.NewTransaction(
+		txnbuild.TransactionParams{
+			SourceAccount:        &accountDetail,
+			IncrementSequenceNum: true,
+			BaseFee:              100,
+			Operations:           ops,
+			Timebounds:           txnbuild.NewInfiniteTimeout(),
+		},
+	)
+	if e != nil {
+		return nil, fmt.Errorf("unable to make transaction to create channel accounts: %s", e)
+	}
+
+	tx, e = signer.Sign(tx, network, fundingAccount)
+	if e != nil {
+		return nil, fmt.Errorf("unable to sign transaction to create channel accounts: %s", e)
+	}
+
+	txeB64, e := tx.Base64()
+	if e != nil {
+		return nil, fmt.Errorf("unable to encode transaction to create channel accounts: %s", e)
+	}
+
+	resp, e := client.SubmitTransactionXDR(txeB64)
+	if e != nil {
+		return nil, fmt.Errorf("unable to submit transaction to create channel accounts: %s", e)
+	}
+	log.Printf("channel accounts created in tx with hash: %s\n", resp.Hash)
+
+	return seeds, nil
+}
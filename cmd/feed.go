@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/support/config"
+	"github.com/stellar/kelp/plugins"
+	"github.com/stellar/kelp/support/database"
+	"github.com/stellar/kelp/support/logger"
+	"github.com/stellar/kelp/support/utils"
+	"github.com/stellar/kelp/trader"
+)
+
+var feedCmd = &cobra.Command{
+	Use:   "feed",
+	Short: "Evaluates a price feed and prints a breakdown of its sub-feeds",
+	Long: `Evaluates any price feed that can be used in a strategy config file (including nested function, consensus and cache feeds) and prints the resulting price along with the value, latency and error of every sub-feed.
+Feeds that were not queried during an evaluation (such as unused fallbacks or cached values) are marked as skipped.`,
+	Example: `  kelp feed -t exchange -u ccxt-binance/XLM/USDT/mid
+  kelp feed -t function -u "max(exchange/ccxt-binance/XLM/USDT/mid,exchange/ccxt-kraken/XLM/USD/mid)" -i 10
+  kelp feed -t sdex -u "XLM:/USD:GDUKMGUGDZQK6YHYA5Z6AY2G4XDSZPSZ3SW5UN3ARVMO6QSRDWP5YLEX" -c trader.cfg`,
+}
+
+func init() {
+	feedType := feedCmd.Flags().StringP("type", "t", "", "type of the price feed, same as the DATA_TYPE fields in the strategy config files")
+	feedURL := feedCmd.Flags().StringP("url", "u", "", "URL of the price feed, same as the DATA_FEED_URL fields in the strategy config files")
+	botConfigPath := feedCmd.Flags().StringP("botConf", "c", "", "(optional) trading bot's basic config file path, used for the horizon URLs, network, price feed headers and postgres db")
+	intervalSeconds := feedCmd.Flags().Uint32P("interval", "i", 0, "(optional) evaluate the feed continuously every interval seconds, evaluates it once when 0")
+
+	requiredFlag := func(flag string) {
+		e := feedCmd.MarkFlagRequired(flag)
+		if e != nil {
+			panic(e)
+		}
+	}
+	requiredFlag("type")
+	requiredFlag("url")
+
+	feedCmd.Run = func(ccmd *cobra.Command, args []string) {
+		if *botConfigPath != "" {
+			var botConfig trader.BotConfig
+			e := config.Read(*botConfigPath, &botConfig)
+			utils.CheckConfigError(botConfig, e, *botConfigPath)
+			e = botConfig.Init()
+			if e != nil {
+				log.Fatal(e)
+			}
+
+			client := &horizonclient.Client{
+				HorizonURL: botConfig.HorizonURL,
+				HTTP:       makeHorizonHTTP(logger.MakeBasicLogger(), botConfig),
+				AppName:    "kelp--cli--feed",
+				AppVersion: version,
+			}
+			network := utils.ResolveNetwork(botConfig.NetworkPassphrase, botConfig.HorizonURL)
+
+			// trades feeds with the "kelpdb" source read from the db
+			var db *sql.DB
+			if botConfig.PostgresDbConfig != nil {
+				db, e = database.ConnectInitializedDatabase(botConfig.PostgresDbConfig, upgradeScripts, version)
+				if e != nil {
+					log.Fatalf("problem encountered while initializing the db: %s\n", e)
+				}
+				log.Printf("made db instance with config: %s\n", botConfig.PostgresDbConfig.MakeConnectString())
+			}
+
+			e = setPriceFeedGlobals(network, botConfig, client, db)
+			if e != nil {
+				log.Fatal(e)
+			}
+		}
+
+		feed, trace, e := plugins.MakeTracedPriceFeed(*feedType, *feedURL)
+		if e != nil {
+			log.Fatalf("unable to make price feed: %s\n", e)
+		}
+
+		for {
+			trace.Reset()
+			price, e := feed.GetPrice()
+			if e != nil {
+				log.Printf("error fetching price: %s\n", e)
+			} else {
+				log.Printf("price: %.10f\n", price)
+			}
+			log.Printf("breakdown:\n%s", formatPriceFeedTrace(trace, 0))
+
+			if *intervalSeconds == 0 {
+				break
+			}
+			time.Sleep(time.Duration(*intervalSeconds) * time.Second)
+		}
+	}
+}
+
+// formatPriceFeedTrace formats the trace as an indented tree with one line per feed
+func formatPriceFeedTrace(trace *plugins.PriceFeedTrace, depth int) string {
+	result := trace.Result()
+	var status string
+	if !result.Queried {
+		status = "skipped"
+	} else if result.Error != nil {
+		status = fmt.Sprintf("error=%s (latency=%s)", result.Error, result.Latency)
+	} else {
+		status = fmt.Sprintf("price=%.10f (latency=%s)", result.Price, result.Latency)
+	}
+
+	s := fmt.Sprintf("%s%s/%s: %s\n", strings.Repeat("    ", depth), trace.FeedType, trace.URL, status)
+	for _, c := range trace.Children() {
+		s += formatPriceFeedTrace(c, depth+1)
+	}
+	return s
+}
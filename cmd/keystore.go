@@ -0,0 +1,61 @@
+package cmd
+
+import (
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+	"github.com/stellar/go/support/config"
+	"github.com/stellar/kelp/support/signing"
+	"github.com/stellar/kelp/support/utils"
+	"github.com/stellar/kelp/trader"
+)
+
+var keystoreCmd = &cobra.Command{
+	Use:   "keystore",
+	Short: "Moves the secret seeds in a trader config file into an encrypted keystore file",
+	Long: `Encrypts the SOURCE_SECRET_SEED, TRADING_SECRET_SEED and CHANNEL_SECRET_SEEDS values in the trader config file into a keystore file, using the passphrase in the passphrase environment variable.
+Once the keystore is created, remove the secret seeds from the trader config file, set TRADING_ACCOUNT (and SOURCE_ACCOUNT and CHANNEL_ACCOUNTS if needed), and add a [SIGNER] section with TYPE="keystore".`,
+}
+
+func init() {
+	botConfigPath := keystoreCmd.Flags().StringP("botConf", "c", "", "trading bot's basic config file path")
+	outPath := keystoreCmd.Flags().StringP("out", "o", "", "path to write the encrypted keystore file to")
+	passphraseEnvVar := keystoreCmd.Flags().String("passphraseEnvVar", signing.DefaultKeystorePassphraseEnvVar, "environment variable that holds the passphrase used to encrypt the keystore")
+
+	requiredFlag := func(flag string) {
+		e := keystoreCmd.MarkFlagRequired(flag)
+		if e != nil {
+			panic(e)
+		}
+	}
+	requiredFlag("botConf")
+	requiredFlag("out")
+
+	keystoreCmd.Run = func(ccmd *cobra.Command, args []string) {
+		var botConfig trader.BotConfig
+		e := config.Read(*botConfigPath, &botConfig)
+		utils.CheckConfigError(botConfig, e, *botConfigPath)
+
+		seeds := []string{}
+		for _, s := range botConfig.SignerSeeds() {
+			if s != "" {
+				seeds = append(seeds, s)
+			}
+		}
+		if len(seeds) == 0 {
+			log.Fatalf("no secret seeds found in the trader config file %s\n", *botConfigPath)
+		}
+
+		passphrase := os.Getenv(*passphraseEnvVar)
+		if passphrase == "" {
+			log.Fatalf("environment variable %s needs to be set to the passphrase for the keystore\n", *passphraseEnvVar)
+		}
+
+		e = signing.WriteKeystore(*outPath, passphrase, seeds)
+		if e != nil {
+			log.Fatalf("unable to write keystore: %s\n", e)
+		}
+		log.Printf("wrote %d secret seeds to keystore file %s\n", len(seeds), *outPath)
+	}
+}
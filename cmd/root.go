@@ -72,6 +72,9 @@ func init() {
 	RootCmd.AddCommand(strategiesCmd)
 	RootCmd.AddCommand(exchangesCmd)
 	RootCmd.AddCommand(terminateCmd)
+	RootCmd.AddCommand(channelsCmd)
+	RootCmd.AddCommand(feedCmd)
+	RootCmd.AddCommand(keystoreCmd)
 	RootCmd.AddCommand(versionCmd)
 }
 
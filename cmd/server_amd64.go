@@ -59,14 +59,16 @@ const readyStringIndicator = "Serving frontend and API server on HTTP port"
 const downloadCcxtUpdateIntervalLogMillis = 1000
 
 type serverInputs struct {
-	port              *uint16
-	dev               *bool
-	devAPIPort        *uint16
-	horizonTestnetURI *string
-	horizonPubnetURI  *string
-	noHeaders         *bool
-	verbose           *bool
-	noElectron        *bool
+	port                    *uint16
+	dev                     *bool
+	devAPIPort              *uint16
+	horizonTestnetURI       *string
+	horizonPubnetURI        *string
+	horizonCustomURI        *string
+	customNetworkPassphrase *string
+	noHeaders               *bool
+	verbose                 *bool
+	noElectron              *bool
 }
 
 func init() {
@@ -78,6 +80,8 @@ func init() {
 	options.devAPIPort = serverCmd.Flags().Uint16("dev-api-port", 8001, "port on which to run API server when in dev mode")
 	options.horizonTestnetURI = serverCmd.Flags().String("horizon-testnet-uri", "https://horizon-testnet.stellar.org", "URI to use for the horizon instance connected to the Stellar Test Network (must contain the word 'test')")
 	options.horizonPubnetURI = serverCmd.Flags().String("horizon-pubnet-uri", "https://horizon.stellar.org", "URI to use for the horizon instance connected to the Stellar Public Network (must not contain the word 'test')")
+	options.horizonCustomURI = serverCmd.Flags().String("horizon-custom-uri", "", "URI to use for the horizon instance connected to a custom (private or standalone) network, requires custom-network-passphrase")
+	options.customNetworkPassphrase = serverCmd.Flags().String("custom-network-passphrase", "", "network passphrase of the custom network that horizon-custom-uri is connected to")
 	options.noHeaders = serverCmd.Flags().Bool("no-headers", false, "do not use Amplitude or set X-App-Name and X-App-Version headers on requests to horizon")
 	options.verbose = serverCmd.Flags().BoolP("verbose", "v", false, "enable verbose log lines typically used for debugging")
 	options.noElectron = serverCmd.Flags().Bool("no-electron", false, "open in browser instead of using electron")
@@ -198,6 +202,13 @@ func init() {
 		horizonPubnetURI := strings.TrimSuffix(*options.horizonPubnetURI, "/")
 		log.Printf("using horizonTestnetURI: %s\n", horizonTestnetURI)
 		log.Printf("using horizonPubnetURI: %s\n", horizonPubnetURI)
+		if (*options.horizonCustomURI == "") != (*options.customNetworkPassphrase == "") {
+			panic("'horizon-custom-uri' and 'custom-network-passphrase' arguments need to be set together")
+		}
+		horizonCustomURI := strings.TrimSuffix(*options.horizonCustomURI, "/")
+		if horizonCustomURI != "" {
+			log.Printf("using horizonCustomURI: %s (network passphrase: %s)\n", horizonCustomURI, *options.customNetworkPassphrase)
+		}
 
 		if *rootCcxtRestURL == "" {
 			*rootCcxtRestURL = "http://localhost:3000"
@@ -215,17 +226,24 @@ func init() {
 			HorizonURL: horizonPubnetURI,
 			HTTP:       http.DefaultClient,
 		}
+		var apiCustomNet *horizonclient.Client
+		if horizonCustomURI != "" {
+			apiCustomNet = &horizonclient.Client{
+				HorizonURL: horizonCustomURI,
+				HTTP:       http.DefaultClient,
+			}
+		}
 		if !*options.noHeaders {
+			appName := "kelp--gui-desktop--admin-electron"
 			if *options.noElectron {
-				apiTestNet.AppName = "kelp--gui-desktop--admin-browser"
-				apiPubNet.AppName = "kelp--gui-desktop--admin-browser"
-			} else {
-				apiTestNet.AppName = "kelp--gui-desktop--admin-electron"
-				apiPubNet.AppName = "kelp--gui-desktop--admin-electron"
+				appName = "kelp--gui-desktop--admin-browser"
+			}
+			for _, c := range []*horizonclient.Client{apiTestNet, apiPubNet, apiCustomNet} {
+				if c != nil {
+					c.AppName = appName
+					c.AppVersion = version
+				}
 			}
-
-			apiTestNet.AppVersion = version
-			apiPubNet.AppVersion = version
 
 			p := prefs.Make(prefsFilename)
 			if p.FirstTime() {
@@ -336,6 +354,9 @@ func init() {
 			apiTestNet,
 			*options.horizonPubnetURI,
 			apiPubNet,
+			horizonCustomURI,
+			*options.customNetworkPassphrase,
+			apiCustomNet,
 			*rootCcxtRestURL,
 			*options.noHeaders,
 			quit,
@@ -11,6 +11,7 @@ import (
 	"github.com/stellar/go/support/config"
 	"github.com/stellar/kelp/model"
 	"github.com/stellar/kelp/plugins"
+	"github.com/stellar/kelp/support/signing"
 	"github.com/stellar/kelp/support/utils"
 	"github.com/stellar/kelp/terminator"
 )
@@ -44,15 +45,18 @@ func init() {
 			AppName:    "kelp",
 			AppVersion: version,
 		}
+		signer, e := signing.MakeSeedSigner(configFile.SourceSecretSeed, configFile.TradingSecretSeed)
+		if e != nil {
+			log.Fatal(e)
+		}
 		sdex := plugins.MakeSDEX(
 			client,
 			plugins.MakeIEIF(true), // used true for now since it's only ever been tested on SDEX and uses SDEX's data for now
 			nil,
-			configFile.SourceSecretSeed,
-			configFile.TradingSecretSeed,
+			signer,
 			*configFile.SourceAccount,
 			*configFile.TradingAccount,
-			utils.ParseNetwork(configFile.HorizonURL),
+			utils.ResolveNetwork(configFile.NetworkPassphrase, configFile.HorizonURL),
 			multithreading.MakeThreadTracker(),
 			-1, // not needed here
 			-1, // not needed here
@@ -60,6 +64,7 @@ func init() {
 			nil, // not needed here
 			map[model.Asset]hProtocol.Asset{},
 			plugins.SdexFixedFeeFn(0),
+			false,
 		)
 		terminator := terminator.MakeTerminator(client, sdex, *configFile.TradingAccount, configFile.TickIntervalSeconds, configFile.AllowInactiveMinutes)
 		// --- end initialization of objects ----
@@ -30,6 +30,7 @@ import (
 	"github.com/stellar/kelp/support/networking"
 	"github.com/stellar/kelp/support/prefs"
 	"github.com/stellar/kelp/support/sdk"
+	"github.com/stellar/kelp/support/signing"
 	"github.com/stellar/kelp/support/utils"
 	"github.com/stellar/kelp/trader"
 )
@@ -58,6 +59,9 @@ var upgradeScripts = []*database.UpgradeScript{
 		kelpdb.SqlStrategyMirrorTradeTriggersTableCreate,
 		kelpdb.SqlTradesTableAlter2,
 	),
+	database.MakeUpgradeScript(7,
+		kelpdb.SqlTradesTableAlter3,
+	),
 }
 
 const tradeExamples = `  kelp trade --botConf ./path/trader.cfg --strategy buysell --stratConf ./path/buysell.cfg
@@ -65,6 +69,14 @@ const tradeExamples = `  kelp trade --botConf ./path/trader.cfg --strategy buyse
 
 const prefsFilename = "kelp.prefs"
 
+// defaults used when HORIZON_URLS is set and the corresponding health check fields are not
+const defaultHorizonMaxLedgerLag = 5
+const defaultHorizonMaxErrorRate = 0.5
+const defaultHorizonHealthCheckMillis = 10000
+
+// default used when FILTER_STATE_MAX_AGE_SECONDS is not set
+const defaultFilterStateMaxAgeSeconds = 3600
+
 var tradeCmd = &cobra.Command{
 	Use:     "trade",
 	Short:   "Trades against the Stellar universal marketplace using the specified strategy",
@@ -146,6 +158,11 @@ func validateBotConfig(l logger.Logger, botConfig trader.BotConfig) {
 	if botConfig.SleepMode != "" && botConfig.SleepMode != trader.SleepModeBegin.String() && botConfig.SleepMode != trader.SleepModeEnd.String() {
 		logger.Fatal(l, fmt.Errorf("SLEEP_MODE needs to be set to either '%s' or '%s'", trader.SleepModeBegin, trader.SleepModeEnd))
 	}
+
+	usesSeedSigner := botConfig.Signer == nil || botConfig.Signer.Type == "" || botConfig.Signer.Type == signing.SignerTypeSeed
+	if botConfig.IsTradingSdex() && usesSeedSigner && botConfig.TradingSecretSeed == "" {
+		logger.Fatal(l, fmt.Errorf("need to specify TRADING_SECRET_SEED in the trader config file when trading on SDEX without a keystore or remote SIGNER"))
+	}
 }
 
 func validatePrecisionConfig(l logger.Logger, isTradingSdex bool, precisionField *int8, name string) {
@@ -243,6 +260,69 @@ func makeFeeFn(l logger.Logger, botConfig trader.BotConfig, newClient *horizoncl
 	return feeFn
 }
 
+// makeHorizonHTTP returns the HTTP client used to talk to horizon, which fails over between instances when HORIZON_URLS is set
+func makeHorizonHTTP(l logger.Logger, botConfig trader.BotConfig) horizonclient.HTTP {
+	if len(botConfig.HorizonURLs) == 0 {
+		return http.DefaultClient
+	}
+
+	maxLedgerLag := botConfig.HorizonMaxLedgerLag
+	if maxLedgerLag <= 0 {
+		maxLedgerLag = defaultHorizonMaxLedgerLag
+	}
+	maxErrorRate := botConfig.HorizonMaxErrorRate
+	if maxErrorRate <= 0 {
+		maxErrorRate = defaultHorizonMaxErrorRate
+	}
+	healthCheckMillis := botConfig.HorizonHealthCheckMillis
+	if healthCheckMillis <= 0 {
+		healthCheckMillis = defaultHorizonHealthCheckMillis
+	}
+
+	// HORIZON_URL is always the primary instance
+	horizonURLs := append([]string{botConfig.HorizonURL}, botConfig.HorizonURLs...)
+	failover, e := networking.MakeHorizonFailover(
+		horizonURLs,
+		http.DefaultClient,
+		maxLedgerLag,
+		maxErrorRate,
+		time.Duration(healthCheckMillis)*time.Millisecond,
+	)
+	if e != nil {
+		logger.Fatal(l, fmt.Errorf("could not make horizon failover client: %s", e))
+	}
+	e = failover.Start()
+	if e != nil {
+		logger.Fatal(l, fmt.Errorf("could not start horizon health checks: %s", e))
+	}
+	l.Infof("using %d horizon instances with failover (maxLedgerLag=%d, maxErrorRate=%.2f, healthCheckMillis=%d)\n", len(horizonURLs), maxLedgerLag, maxErrorRate, healthCheckMillis)
+	return failover
+}
+
+func makeSigner(l logger.Logger, botConfig trader.BotConfig) api.Signer {
+	if botConfig.Signer == nil {
+		signer, e := signing.MakeSeedSigner(botConfig.SignerSeeds()...)
+		if e != nil {
+			logger.Fatal(l, fmt.Errorf("could not make signer: %s", e))
+		}
+		return signer
+	}
+
+	signer, e := signing.MakeSigner(
+		botConfig.Signer.Type,
+		botConfig.SignerSeeds(),
+		botConfig.Signer.KeystorePath,
+		botConfig.Signer.KeystorePassphraseEnvVar,
+		botConfig.Signer.RemoteURL,
+		botConfig.Signer.RemoteAuthToken,
+		time.Duration(botConfig.Signer.RemoteTimeoutMillis)*time.Millisecond,
+	)
+	if e != nil {
+		logger.Fatal(l, fmt.Errorf("could not make signer: %s", e))
+	}
+	return signer
+}
+
 func readBotConfig(l logger.Logger, options inputs, botStartTime time.Time) trader.BotConfig {
 	var botConfig trader.BotConfig
 	e := config.Read(*options.botConfigPath, &botConfig)
@@ -341,8 +421,7 @@ func makeExchangeShimSdex(
 		client,
 		ieif,
 		exchangeShim,
-		botConfig.SourceSecretSeed,
-		botConfig.TradingSecretSeed,
+		makeSigner(l, botConfig),
 		botConfig.SourceAccount(),
 		botConfig.TradingAccount(),
 		network,
@@ -353,7 +432,22 @@ func makeExchangeShimSdex(
 		tradingPair,
 		sdexAssetMap,
 		feeFn,
+		botConfig.SdexUseManageBuyOffer,
 	)
+	if botConfig.IsTradingSdex() {
+		if botConfig.Fee.FeeBump {
+			sdex.EnableFeeBump(botConfig.Fee.MaxOpFeeStroops)
+		}
+		if botConfig.Fee.DailyBudgetStroops > 0 {
+			sdex.SetDailyFeeBudget(botConfig.Fee.DailyBudgetStroops)
+		}
+	}
+	if len(botConfig.ChannelAccountAddresses()) > 0 {
+		e = sdex.UseChannelAccounts(botConfig.ChannelAccountAddresses())
+		if e != nil {
+			logger.Fatal(l, fmt.Errorf("unable to use channel accounts: %s", e))
+		}
+	}
 
 	if botConfig.IsTradingSdex() {
 		exchangeShim = sdex
@@ -361,13 +455,45 @@ func makeExchangeShimSdex(
 	return exchangeShim, sdex
 }
 
-func makeStrategy(
+// setPriceFeedHacks sets the temp hack variables for the price feeds, this needs to happen before any price feed is made
+func setPriceFeedHacks(
 	l logger.Logger,
 	network string,
 	botConfig trader.BotConfig,
 	client *horizonclient.Client,
 	sdex *plugins.SDEX,
 	exchangeShim api.ExchangeShim,
+	threadTracker *multithreading.ThreadTracker,
+	db *sql.DB,
+	metricsTracker *plugins.MetricsTracker,
+) {
+	e := setPriceFeedGlobals(network, botConfig, client, db)
+	if e != nil {
+		l.Info("")
+		l.Errorf("%s", e)
+		// we want to delete all the offers and exit here since there is something wrong with our setup
+		deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+	}
+}
+
+// setPriceFeedGlobals sets the package-level state used by the sdex, custom header and kelpdb trades price feeds, db can be nil
+func setPriceFeedGlobals(network string, botConfig trader.BotConfig, client *horizonclient.Client, db *sql.DB) error {
+	// setting the temp hack variables for the sdex price feeds
+	e := plugins.SetPrivateSdexHack(client, plugins.MakeIEIF(true), network)
+	if e != nil {
+		return fmt.Errorf("error setting the horizon client for sdex price feeds: %s", e)
+	}
+	plugins.SetPriceFeedHeaders(botConfig.PriceFeedHeaders.ToPriceFeedHeaders())
+	plugins.SetTradesFeedDB(db)
+	return nil
+}
+
+func makeStrategy(
+	l logger.Logger,
+	botConfig trader.BotConfig,
+	client *horizonclient.Client,
+	sdex *plugins.SDEX,
+	exchangeShim api.ExchangeShim,
 	assetBase hProtocol.Asset,
 	assetQuote hProtocol.Asset,
 	marketID string,
@@ -377,15 +503,13 @@ func makeStrategy(
 	options inputs,
 	threadTracker *multithreading.ThreadTracker,
 	db *sql.DB,
+	circuitBreaker *trader.CircuitBreaker,
 	metricsTracker *plugins.MetricsTracker,
 ) api.Strategy {
-	// setting the temp hack variables for the sdex price feeds
-	e := plugins.SetPrivateSdexHack(client, plugins.MakeIEIF(true), network)
-	if e != nil {
-		l.Info("")
-		l.Errorf("%s", e)
-		// we want to delete all the offers and exit here since there is something wrong with our setup
-		deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+	// the circuit breaker widens the spreads of the strategy while recovering from a halt, a nil pointer needs to be a nil interface
+	var spreadWidener api.SpreadWidener
+	if circuitBreaker != nil {
+		spreadWidener = circuitBreaker
 	}
 
 	strategy, e := plugins.MakeStrategy(
@@ -403,6 +527,7 @@ func makeStrategy(
 		botConfig.IsTradingSdex(),
 		filterFactory,
 		db,
+		spreadWidener,
 	)
 	if e != nil {
 		l.Info("")
@@ -413,6 +538,57 @@ func makeStrategy(
 	return strategy
 }
 
+// makeCircuitBreaker makes the circuit breaker, which is nil when it is not enabled
+func makeCircuitBreaker(
+	l logger.Logger,
+	botConfig trader.BotConfig,
+	client *horizonclient.Client,
+	sdex *plugins.SDEX,
+	exchangeShim api.ExchangeShim,
+	options inputs,
+	threadTracker *multithreading.ThreadTracker,
+	metricsTracker *plugins.MetricsTracker,
+) *trader.CircuitBreaker {
+	if botConfig.CircuitBreakerMaxMove == 0.0 {
+		return nil
+	}
+	if botConfig.CircuitBreakerWidenSpread != 0.0 && *options.strategy != "buysell" && *options.strategy != "sell" {
+		log.Println()
+		utils.PrintErrorHintf("CIRCUIT_BREAKER_WIDEN_SPREAD currently only supported on 'buysell', 'sell' strategies, remove CIRCUIT_BREAKER_WIDEN_SPREAD from the trader config file")
+		// we want to delete all the offers and exit here since there is something wrong with our setup
+		deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+	}
+
+	var e error
+	var circuitBreakerFeed api.PriceFeed
+	if botConfig.CircuitBreakerFeedType != "" {
+		circuitBreakerFeed, e = plugins.MakePriceFeed(botConfig.CircuitBreakerFeedType, botConfig.CircuitBreakerFeedURL)
+		if e != nil {
+			log.Println()
+			log.Printf("could not make the reference feed for the circuit breaker: %s\n", e)
+			// we want to delete all the offers and exit here since there is something wrong with our setup
+			deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+		}
+	}
+
+	circuitBreaker, e := trader.MakeCircuitBreaker(
+		botConfig.CircuitBreakerMaxMove,
+		time.Duration(botConfig.CircuitBreakerWindowSeconds)*time.Second,
+		time.Duration(botConfig.CircuitBreakerCooldownSeconds)*time.Second,
+		botConfig.CircuitBreakerDeleteOffers,
+		botConfig.CircuitBreakerWidenSpread,
+		time.Duration(botConfig.CircuitBreakerWidenSeconds)*time.Second,
+		circuitBreakerFeed,
+	)
+	if e != nil {
+		log.Println()
+		log.Println(e)
+		// we want to delete all the offers and exit here since there is something wrong with our setup
+		deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+	}
+	return circuitBreaker
+}
+
 func makeBot(
 	l logger.Logger,
 	botConfig trader.BotConfig,
@@ -424,6 +600,7 @@ func makeBot(
 	filterFactory *plugins.FilterFactory,
 	strategy api.Strategy,
 	fillTracker api.FillTracker,
+	circuitBreaker *trader.CircuitBreaker,
 	threadTracker *multithreading.ThreadTracker,
 	options inputs,
 	metricsTracker *plugins.MetricsTracker,
@@ -476,6 +653,59 @@ func makeBot(
 		}
 	}
 
+	var killSwitch *trader.KillSwitch
+	if botConfig.KillSwitchMaxDrawdown != 0.0 {
+		if valueBaseFeed == nil || valueQuoteFeed == nil {
+			log.Println()
+			utils.PrintErrorHintf("KILL_SWITCH_MAX_DRAWDOWN needs both DOLLAR_VALUE_FEED_BASE_ASSET and DOLLAR_VALUE_FEED_QUOTE_ASSET to value the account")
+			// we want to delete all the offers and exit here since there is something wrong with our setup
+			deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+		}
+
+		highReset, e := trader.ParseKillSwitchHighReset(botConfig.KillSwitchHighReset)
+		if e != nil {
+			log.Println()
+			log.Println(e)
+			// we want to delete all the offers and exit here since there is something wrong with our setup
+			deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+		}
+		killSwitch, e = trader.MakeKillSwitch(botConfig.KillSwitchMaxDrawdown, highReset, botConfig.KillSwitchControlFile)
+		if e != nil {
+			log.Println()
+			log.Println(e)
+			// we want to delete all the offers and exit here since there is something wrong with our setup
+			deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+		}
+	}
+
+	var opGovernor *trader.OpGovernor
+	if (botConfig.MaxOpsPerMinute != 0 || botConfig.MaxTxsPerHour != 0) && !botConfig.IsTradingSdex() {
+		log.Println()
+		utils.PrintErrorHintf("MAX_OPS_PER_MINUTE and MAX_TXS_PER_HOUR are currently only supported when trading on SDEX, remove them from the trader config file")
+		// we want to delete all the offers and exit here since there is something wrong with our setup
+		deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+	}
+	feeBudgetThreshold := 0.0
+	if botConfig.IsTradingSdex() {
+		feeBudgetThreshold = botConfig.Fee.DailyBudgetPriorityThreshold
+	}
+	if botConfig.MaxOpsPerMinute != 0 || botConfig.MaxTxsPerHour != 0 || feeBudgetThreshold != 0.0 {
+		var feeBudgetRemainingFn func() float64
+		if feeBudgetThreshold != 0.0 {
+			feeBudgetRemainingFn = sdex.FeeBudgetRemaining
+		}
+
+		opGovernor, e = trader.MakeOpGovernor(botConfig.MaxOpsPerMinute, botConfig.MaxTxsPerHour, feeBudgetThreshold, feeBudgetRemainingFn)
+		if e != nil {
+			log.Println()
+			log.Println(e)
+			// we want to delete all the offers and exit here since there is something wrong with our setup
+			deleteAllOffersAndExit(l, botConfig, client, sdex, exchangeShim, threadTracker, metricsTracker)
+		}
+		// every transaction submitted to the network counts towards the limits, including fee bumps and channel account transactions
+		sdex.SetSubmissionListener(opGovernor.Record)
+	}
+
 	// start make filters
 	submitFilters := []plugins.SubmitFilter{}
 	if submitMode == api.SubmitModeMakerOnly {
@@ -529,6 +759,9 @@ func makeBot(
 		options.fixedIterations,
 		dataKey,
 		alert,
+		killSwitch,
+		circuitBreaker,
+		opGovernor,
 		metricsTracker,
 		botStartTime,
 	)
@@ -640,7 +873,7 @@ func runTradeCmd(options inputs) {
 
 	client := &horizonclient.Client{
 		HorizonURL: botConfig.HorizonURL,
-		HTTP:       http.DefaultClient,
+		HTTP:       makeHorizonHTTP(l, botConfig),
 	}
 	if !*options.noHeaders {
 		client.AppName = "kelp--cli--bot"
@@ -670,7 +903,7 @@ func runTradeCmd(options inputs) {
 	l.Infof("using CCXT-rest URL: %s\n", sdk.GetBaseURL())
 
 	ieif := plugins.MakeIEIF(botConfig.IsTradingSdex())
-	network := utils.ParseNetwork(botConfig.HorizonURL)
+	network := utils.ResolveNetwork(botConfig.NetworkPassphrase, botConfig.HorizonURL)
 	sdexAssetMap := map[model.Asset]hProtocol.Asset{
 		tradingPair.Base:  botConfig.AssetBase(),
 		tradingPair.Quote: botConfig.AssetQuote(),
@@ -712,6 +945,10 @@ func runTradeCmd(options inputs) {
 		tradingPair,
 		sdexAssetMap,
 	)
+	filterStateMaxAgeSeconds := botConfig.FilterStateMaxAgeSeconds
+	if filterStateMaxAgeSeconds <= 0 {
+		filterStateMaxAgeSeconds = defaultFilterStateMaxAgeSeconds
+	}
 	filterFactory := &plugins.FilterFactory{
 		ExchangeName:   botConfig.TradingExchangeName(),
 		TradingPair:    tradingPair,
@@ -719,6 +956,11 @@ func runTradeCmd(options inputs) {
 		BaseAsset:      assetBase,
 		QuoteAsset:     assetQuote,
 		DB:             db,
+		IEIF:           ieif,
+		ExchangeShim:   exchangeShim,
+		FilterGroups:   botConfig.FilterGroups,
+		StateDir:       botConfig.FilterStateDir,
+		StateMaxAge:    time.Duration(filterStateMaxAgeSeconds) * time.Second,
 	}
 	baseString, e := assetDisplayFn(tradingPair.Base)
 	if e != nil {
@@ -729,9 +971,11 @@ func runTradeCmd(options inputs) {
 		logger.Fatal(l, fmt.Errorf("could not convert quote trading pair to string: %s", e))
 	}
 	marketID := plugins.MakeMarketID(botConfig.TradingExchangeName(), baseString, quoteString)
+	setPriceFeedHacks(l, network, botConfig, client, sdex, exchangeShim, threadTracker, db, metricsTracker)
+	// the circuit breaker is made before the strategy because the strategy widens its spreads when recovering from a halt
+	circuitBreaker := makeCircuitBreaker(l, botConfig, client, sdex, exchangeShim, options, threadTracker, metricsTracker)
 	strategy := makeStrategy(
 		l,
-		network,
 		botConfig,
 		client,
 		sdex,
@@ -745,8 +989,11 @@ func runTradeCmd(options inputs) {
 		options,
 		threadTracker,
 		db,
+		circuitBreaker,
 		metricsTracker,
 	)
+	// the notional feed is made after the price feed hacks are set
+	filterFactory.NotionalFeed = makeNotionalFeed(l, botConfig)
 	fillTracker := makeFillTracker(
 		l,
 		strategy,
@@ -759,6 +1006,7 @@ func runTradeCmd(options inputs) {
 		db,
 		threadTracker,
 		botConfig.DbOverrideAccountID,
+		filterFactory.NotionalFeed,
 		metricsTracker,
 	)
 	bot := makeBot(
@@ -772,6 +1020,7 @@ func runTradeCmd(options inputs) {
 		filterFactory,
 		strategy,
 		fillTracker,
+		circuitBreaker,
 		threadTracker,
 		options,
 		metricsTracker,
@@ -874,6 +1123,23 @@ func startMonitoringServer(l logger.Logger, botConfig trader.BotConfig) error {
 	return server.StartServer(botConfig.MonitoringPort, botConfig.MonitoringTLSCert, botConfig.MonitoringTLSKey)
 }
 
+// makeNotionalFeed makes the feed used to convert trades to the reference currency of notional volume caps, returns nil when not configured
+func makeNotionalFeed(l logger.Logger, botConfig trader.BotConfig) api.PriceFeed {
+	if botConfig.NotionalFeedType == "" && botConfig.NotionalFeedURL == "" {
+		return nil
+	}
+	if botConfig.NotionalFeedType == "" || botConfig.NotionalFeedURL == "" {
+		logger.Fatal(l, fmt.Errorf("invalid trader.cfg config, need to set both NOTIONAL_FEED_TYPE and NOTIONAL_FEED_URL or neither of them"))
+	}
+
+	notionalFeed, e := plugins.MakePriceFeed(botConfig.NotionalFeedType, botConfig.NotionalFeedURL)
+	if e != nil {
+		logger.Fatal(l, fmt.Errorf("could not make the notional feed: %s", e))
+	}
+	log.Printf("made notional feed (type=%s, url=%s)\n", botConfig.NotionalFeedType, botConfig.NotionalFeedURL)
+	return notionalFeed
+}
+
 func makeFillTracker(
 	l logger.Logger,
 	strategy api.Strategy,
@@ -886,6 +1152,7 @@ func makeFillTracker(
 	db *sql.DB,
 	threadTracker *multithreading.ThreadTracker,
 	accountID string,
+	notionalFeed api.PriceFeed,
 	metricsTracker *plugins.MetricsTracker,
 ) api.FillTracker {
 	strategyFillHandlers, e := strategy.GetFillHandlers()
@@ -924,11 +1191,20 @@ func makeFillTracker(
 		log.Printf("set latest trade cursor from where to start tracking fills (used override value): %v\n", lastCursor)
 	}
 
-	fillTracker := plugins.MakeFillTracker(tradingPair, threadTracker, exchangeShim, botConfig.FillTrackerSleepMillis, botConfig.FillTrackerDeleteCyclesThreshold, lastCursor)
+	var fillStreamable api.FillStreamable
+	if botConfig.FillTrackerStream {
+		if !botConfig.IsTradingSdex() {
+			logger.Fatal(l, fmt.Errorf("FILL_TRACKER_STREAM is only supported when trading on SDEX"))
+		}
+		fillStreamable = sdex
+		log.Printf("streaming fills from horizon, falling back to polling every %d millis when the stream disconnects\n", botConfig.FillTrackerSleepMillis)
+	}
+
+	fillTracker := plugins.MakeFillTracker(tradingPair, threadTracker, exchangeShim, fillStreamable, botConfig.FillTrackerSleepMillis, botConfig.FillTrackerDeleteCyclesThreshold, lastCursor)
 	fillLogger := plugins.MakeFillLogger()
 	fillTracker.RegisterHandler(fillLogger)
 	if db != nil {
-		fillDBWriter := plugins.MakeFillDBWriter(db, assetDisplayFn, botConfig.TradingExchangeName(), accountID)
+		fillDBWriter := plugins.MakeFillDBWriter(db, assetDisplayFn, botConfig.TradingExchangeName(), accountID, notionalFeed)
 		fillTracker.RegisterHandler(fillDBWriter)
 	}
 	if strategyFillHandlers != nil {
@@ -120,7 +120,7 @@ func TestTradeUpgradeScripts(t *testing.T) {
 
 	// check schema of trades table
 	columns = database.GetTableSchema(db, "trades")
-	assert.Equal(t, 11, len(columns), fmt.Sprintf("%v", columns))
+	assert.Equal(t, 12, len(columns), fmt.Sprintf("%v", columns))
 	database.AssertTableColumnsEqual(t, &database.TableColumn{
 		ColumnName:             "market_id",
 		OrdinalPosition:        1,
@@ -209,6 +209,14 @@ func TestTradeUpgradeScripts(t *testing.T) {
 		DataType:               "text",
 		CharacterMaximumLength: nil,
 	}, &columns[10])
+	database.AssertTableColumnsEqual(t, &database.TableColumn{
+		ColumnName:             "notional_value",
+		OrdinalPosition:        12,
+		ColumnDefault:          nil,
+		IsNullable:             "YES",
+		DataType:               "double precision",
+		CharacterMaximumLength: nil,
+	}, &columns[11])
 	// check indexes of trades table
 	indexes = database.GetTableIndexes(db, "trades")
 	assert.Equal(t, 3, len(indexes))
@@ -259,7 +267,7 @@ func TestTradeUpgradeScripts(t *testing.T) {
 	// check entries of db_version table
 	var allRows [][]interface{}
 	allRows = database.QueryAllRows(db, "db_version")
-	assert.Equal(t, 6, len(allRows))
+	assert.Equal(t, 7, len(allRows))
 	// first three code_version_string is nil becuase the field was not supported at the time when the upgrade script was run, and only in version 4 of
 	// the database do we add the field. See upgradeScripts and RunUpgradeScripts() for more details
 	database.ValidateDBVersionRow(t, allRows[0], 1, time.Now(), 1, 50, nil)
@@ -268,6 +276,7 @@ func TestTradeUpgradeScripts(t *testing.T) {
 	database.ValidateDBVersionRow(t, allRows[3], 4, time.Now(), 1, 50, &codeVersionString)
 	database.ValidateDBVersionRow(t, allRows[4], 5, time.Now(), 2, 100, &codeVersionString)
 	database.ValidateDBVersionRow(t, allRows[5], 6, time.Now(), 2, 100, &codeVersionString)
+	database.ValidateDBVersionRow(t, allRows[6], 7, time.Now(), 1, 50, &codeVersionString)
 
 	// check entries of markets table
 	allRows = database.QueryAllRows(db, "markets")
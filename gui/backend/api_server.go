@@ -20,20 +20,23 @@ import (
 
 // APIServer is an instance of the API service
 type APIServer struct {
-	kelpBinPath       *kelpos.OSPath
-	botConfigsPath    *kelpos.OSPath
-	botLogsPath       *kelpos.OSPath
-	kos               *kelpos.KelpOS
-	horizonTestnetURI string
-	horizonPubnetURI  string
-	ccxtRestUrl       string
-	apiTestNet        *horizonclient.Client
-	apiPubNet         *horizonclient.Client
-	noHeaders         bool
-	quitFn            func()
-	metricsTracker    *plugins.MetricsTracker
-	kelpErrorMap      map[string]KelpError
-	kelpErrorMapLock  *sync.Mutex
+	kelpBinPath             *kelpos.OSPath
+	botConfigsPath          *kelpos.OSPath
+	botLogsPath             *kelpos.OSPath
+	kos                     *kelpos.KelpOS
+	horizonTestnetURI       string
+	horizonPubnetURI        string
+	horizonCustomURI        string // empty when there is no custom network
+	customNetworkPassphrase string
+	ccxtRestUrl             string
+	apiTestNet              *horizonclient.Client
+	apiPubNet               *horizonclient.Client
+	apiCustomNet            *horizonclient.Client // nil when there is no custom network
+	noHeaders               bool
+	quitFn                  func()
+	metricsTracker          *plugins.MetricsTracker
+	kelpErrorMap            map[string]KelpError
+	kelpErrorMapLock        *sync.Mutex
 
 	cachedOptionsMetadata metadata
 }
@@ -47,6 +50,9 @@ func MakeAPIServer(
 	apiTestNet *horizonclient.Client,
 	horizonPubnetURI string,
 	apiPubNet *horizonclient.Client,
+	horizonCustomURI string,
+	customNetworkPassphrase string,
+	apiCustomNet *horizonclient.Client,
 	ccxtRestUrl string,
 	noHeaders bool,
 	quitFn func(),
@@ -62,21 +68,24 @@ func MakeAPIServer(
 	kelpErrorMap := map[string]KelpError{}
 
 	return &APIServer{
-		kelpBinPath:           kelpBinPath,
-		botConfigsPath:        botConfigsPath,
-		botLogsPath:           botLogsPath,
-		kos:                   kos,
-		horizonTestnetURI:     horizonTestnetURI,
-		horizonPubnetURI:      horizonPubnetURI,
-		ccxtRestUrl:           ccxtRestUrl,
-		apiTestNet:            apiTestNet,
-		apiPubNet:             apiPubNet,
-		noHeaders:             noHeaders,
-		cachedOptionsMetadata: optionsMetadata,
-		quitFn:                quitFn,
-		metricsTracker:        metricsTracker,
-		kelpErrorMap:          kelpErrorMap,
-		kelpErrorMapLock:      &sync.Mutex{},
+		kelpBinPath:             kelpBinPath,
+		botConfigsPath:          botConfigsPath,
+		botLogsPath:             botLogsPath,
+		kos:                     kos,
+		horizonTestnetURI:       horizonTestnetURI,
+		horizonPubnetURI:        horizonPubnetURI,
+		horizonCustomURI:        horizonCustomURI,
+		customNetworkPassphrase: customNetworkPassphrase,
+		ccxtRestUrl:             ccxtRestUrl,
+		apiTestNet:              apiTestNet,
+		apiPubNet:               apiPubNet,
+		apiCustomNet:            apiCustomNet,
+		noHeaders:               noHeaders,
+		cachedOptionsMetadata:   optionsMetadata,
+		quitFn:                  quitFn,
+		metricsTracker:          metricsTracker,
+		kelpErrorMap:            kelpErrorMap,
+		kelpErrorMapLock:        &sync.Mutex{},
 	}, nil
 }
 
@@ -5,7 +5,6 @@ import (
 	"fmt"
 	"log"
 	"net/http"
-	"strings"
 	"time"
 
 	"github.com/stellar/go/clients/horizonclient"
@@ -123,7 +122,7 @@ func (s *APIServer) autogenerateBot(w http.ResponseWriter, r *http.Request) {
 func (s *APIServer) setupTestnetAccount(address string, signer string, botName string) error {
 	// this function runs in testnet mode only
 	client := s.apiTestNet
-	fundedAccount, e := s.checkFundAccount(client, address, botName)
+	fundedAccount, e := s.checkFundAccount(networkTestnet, address, botName)
 	if e != nil {
 		return fmt.Errorf("error checking and funding account: %s", e)
 	}
@@ -193,7 +192,8 @@ func (s *APIServer) setupTestnetAccount(address string, signer string, botName s
 	return nil
 }
 
-func (s *APIServer) checkFundAccount(client *horizonclient.Client, address string, botName string) (*hProtocol.Account, error) {
+func (s *APIServer) checkFundAccount(botNetwork string, address string, botName string) (*hProtocol.Account, error) {
+	client := s.horizonClient(botNetwork)
 	account, e := client.AccountDetail(horizonclient.AccountRequest{AccountID: address})
 	if e == nil {
 		log.Printf("account already exists %s for bot '%s', no need to fund via friendbot\n", address, botName)
@@ -214,13 +214,13 @@ func (s *APIServer) checkFundAccount(client *horizonclient.Client, address strin
 		}
 	}
 
-	if !strings.Contains(client.HorizonURL, "test") {
+	if botNetwork == networkPubnet {
 		log.Printf("not attempting to create mainnet account %s for bot '%s' since mainnet account does not exist\n", address, botName)
 	}
 
 	// since it's a 404 we want to continue funding below
 	var fundResponse interface{}
-	e = networking.JSONRequest(http.DefaultClient, "GET", "https://friendbot.stellar.org/?addr="+address, "", nil, &fundResponse, "")
+	e = networking.JSONRequest(http.DefaultClient, "GET", s.friendbotURL(botNetwork, address), "", nil, &fundResponse, "")
 	if e != nil {
 		return nil, fmt.Errorf("error funding address %s for bot '%s': %s", address, botName, e)
 	}
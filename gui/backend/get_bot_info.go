@@ -6,7 +6,6 @@ import (
 	"log"
 	"net/http"
 	"strconv"
-	"strings"
 	"time"
 
 	"github.com/stellar/go/clients/horizonclient"
@@ -27,6 +26,7 @@ type botInfo struct {
 	TradingAccount string             `json:"trading_account"`
 	Strategy       string             `json:"strategy"`
 	IsTestnet      bool               `json:"is_testnet"`
+	Network        string             `json:"network"`
 	TradingPair    *model.TradingPair `json:"trading_pair"`
 	AssetBase      hProtocol.Asset    `json:"asset_base"`
 	AssetQuote     hProtocol.Asset    `json:"asset_quote"`
@@ -102,10 +102,8 @@ func (s *APIServer) runGetBotInfoDirect(w http.ResponseWriter, botName string) {
 		Quote: model.Asset(utils.Asset2CodeString(assetQuote)),
 	}
 
-	client := s.apiPubNet
-	if strings.Contains(botConfig.HorizonURL, "test") {
-		client = s.apiTestNet
-	}
+	botNetwork := s.botNetwork(botConfig.HorizonURL, botConfig.NetworkPassphrase)
+	client := s.horizonClient(botNetwork)
 
 	account, e := client.AccountDetail(horizonclient.AccountRequest{AccountID: botConfig.TradingAccount()})
 	if e != nil {
@@ -220,7 +218,8 @@ func (s *APIServer) runGetBotInfoDirect(w http.ResponseWriter, botName string) {
 		LastUpdated:    time.Now().UTC().Format("1/_2/2006 15:04:05 MST"),
 		TradingAccount: account.AccountID,
 		Strategy:       buysell,
-		IsTestnet:      strings.Contains(botConfig.HorizonURL, "test"),
+		IsTestnet:      botNetwork == networkTestnet,
+		Network:        botNetwork,
 		TradingPair:    tradingPair,
 		AssetBase:      assetBase,
 		AssetQuote:     assetQuote,
@@ -0,0 +1,105 @@
+package backend
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/network"
+)
+
+// names of the networks that bots can run on
+const (
+	networkTestnet = "testnet"
+	networkPubnet  = "pubnet"
+	networkCustom  = "custom"
+)
+
+// networkInfo describes a network that bots can be configured to use
+type networkInfo struct {
+	HorizonURL        string `json:"horizon_url"`
+	NetworkPassphrase string `json:"network_passphrase"`
+}
+
+// networksResponse lists the networks available on this server, Custom is only set when the server was started with a custom network
+type networksResponse struct {
+	Testnet networkInfo  `json:"testnet"`
+	Pubnet  networkInfo  `json:"pubnet"`
+	Custom  *networkInfo `json:"custom,omitempty"`
+}
+
+func (s *APIServer) networks(w http.ResponseWriter, r *http.Request) {
+	resp := networksResponse{
+		Testnet: networkInfo{
+			HorizonURL:        s.horizonTestnetURI,
+			NetworkPassphrase: network.TestNetworkPassphrase,
+		},
+		Pubnet: networkInfo{
+			HorizonURL:        s.horizonPubnetURI,
+			NetworkPassphrase: network.PublicNetworkPassphrase,
+		},
+	}
+	if s.apiCustomNet != nil {
+		resp.Custom = &networkInfo{
+			HorizonURL:        s.horizonCustomURI,
+			NetworkPassphrase: s.customNetworkPassphrase,
+		}
+	}
+
+	jsonBytes, e := json.MarshalIndent(resp, "", "  ")
+	if e != nil {
+		s.writeErrorJson(w, fmt.Sprintf("cannot marshal networksResponse: %s\n", e))
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	w.Write(jsonBytes)
+}
+
+// botNetwork returns the name of the network that a bot with the passed in horizon url and network passphrase runs on
+func (s *APIServer) botNetwork(horizonURL string, networkPassphrase string) string {
+	if s.apiCustomNet != nil {
+		if networkPassphrase == s.customNetworkPassphrase || strings.TrimSuffix(horizonURL, "/") == s.horizonCustomURI {
+			return networkCustom
+		}
+	}
+
+	if networkPassphrase == network.TestNetworkPassphrase || (networkPassphrase == "" && strings.Contains(horizonURL, "test")) {
+		return networkTestnet
+	}
+	return networkPubnet
+}
+
+// horizonClient returns the horizon client for the named network
+func (s *APIServer) horizonClient(botNetwork string) *horizonclient.Client {
+	switch botNetwork {
+	case networkTestnet:
+		return s.apiTestNet
+	case networkCustom:
+		return s.apiCustomNet
+	default:
+		return s.apiPubNet
+	}
+}
+
+// networkPassphrase returns the network passphrase for the named network
+func (s *APIServer) networkPassphrase(botNetwork string) string {
+	switch botNetwork {
+	case networkTestnet:
+		return network.TestNetworkPassphrase
+	case networkCustom:
+		return s.customNetworkPassphrase
+	default:
+		return network.PublicNetworkPassphrase
+	}
+}
+
+// friendbotURL returns the url of the friendbot used to fund new accounts on the named network, custom networks
+// (such as the stellar/quickstart standalone network) serve their friendbot from horizon
+func (s *APIServer) friendbotURL(botNetwork string, address string) string {
+	if botNetwork == networkCustom {
+		return s.horizonCustomURI + "/friendbot?addr=" + address
+	}
+	return "https://friendbot.stellar.org/?addr=" + address
+}
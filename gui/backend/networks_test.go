@@ -0,0 +1,68 @@
+package backend
+
+import (
+	"testing"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBotNetwork(t *testing.T) {
+	customPassphrase := "Standalone Network ; February 2017"
+	withCustom := &APIServer{
+		horizonCustomURI:        "http://localhost:8000",
+		customNetworkPassphrase: customPassphrase,
+		apiCustomNet:            &horizonclient.Client{HorizonURL: "http://localhost:8000"},
+	}
+	withoutCustom := &APIServer{}
+
+	testCases := []struct {
+		name              string
+		s                 *APIServer
+		horizonURL        string
+		networkPassphrase string
+		want              string
+	}{
+		{
+			name:       "testnet from url",
+			s:          withCustom,
+			horizonURL: "https://horizon-testnet.stellar.org",
+			want:       networkTestnet,
+		}, {
+			name:       "pubnet from url",
+			s:          withCustom,
+			horizonURL: "https://horizon.stellar.org",
+			want:       networkPubnet,
+		}, {
+			name:              "testnet passphrase overrides url",
+			s:                 withCustom,
+			horizonURL:        "https://my-horizon.example.com",
+			networkPassphrase: network.TestNetworkPassphrase,
+			want:              networkTestnet,
+		}, {
+			name:              "custom from passphrase",
+			s:                 withCustom,
+			horizonURL:        "http://other-host:8000",
+			networkPassphrase: customPassphrase,
+			want:              networkCustom,
+		}, {
+			name:       "custom from url",
+			s:          withCustom,
+			horizonURL: "http://localhost:8000/",
+			want:       networkCustom,
+		}, {
+			name:              "no custom network on server",
+			s:                 withoutCustom,
+			horizonURL:        "http://localhost:8000",
+			networkPassphrase: customPassphrase,
+			want:              networkPubnet,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.name, func(t *testing.T) {
+			assert.Equal(t, kase.want, kase.s.botNetwork(kase.horizonURL, kase.networkPassphrase))
+		})
+	}
+}
@@ -17,6 +17,7 @@ func SetRoutes(r *chi.Mux, s *APIServer) {
 		r.Get("/getNewBotConfig", http.HandlerFunc(s.getNewBotConfig))
 		r.Get("/newSecretKey", http.HandlerFunc(s.newSecretKey))
 		r.Get("/optionsMetadata", http.HandlerFunc(s.optionsMetadata))
+		r.Get("/networks", http.HandlerFunc(s.networks))
 		r.Get("/fetchKelpErrors", http.HandlerFunc(s.fetchKelpErrors))
 
 		r.Post("/removeKelpErrors", http.HandlerFunc(s.removeKelpErrors))
@@ -7,12 +7,10 @@ import (
 	"log"
 	"net/http"
 	"regexp"
-	"strings"
 	"time"
 
 	"github.com/stellar/go/clients/horizonclient"
 	"github.com/stellar/go/keypair"
-	"github.com/stellar/go/network"
 	hProtocol "github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/strkey"
 	"github.com/stellar/go/txnbuild"
@@ -218,7 +216,8 @@ func hasNewLevel(levels []plugins.StaticLevel) bool {
 }
 
 func (s *APIServer) reinitBotCheck(req upsertBotConfigRequest) {
-	isTestnet := strings.Contains(req.TraderConfig.HorizonURL, "test")
+	botNetwork := s.botNetwork(req.TraderConfig.HorizonURL, req.TraderConfig.NetworkPassphrase)
+	isTestnet := botNetwork == networkTestnet
 	bot := &model2.Bot{
 		Name:     req.Name,
 		Strategy: req.Strategy,
@@ -242,12 +241,7 @@ func (s *APIServer) reinitBotCheck(req upsertBotConfigRequest) {
 			).KelpError)
 			return
 		}
-		client := s.apiPubNet
-		if strings.Contains(req.TraderConfig.HorizonURL, "test") {
-			client = s.apiTestNet
-		}
-
-		traderAccount, e := s.checkFundAccount(client, tradingKP.Address(), bot.Name)
+		traderAccount, e := s.checkFundAccount(botNetwork, tradingKP.Address(), bot.Name)
 		if e != nil {
 			s.addKelpErrorToMap(makeKelpErrorResponseWrapper(
 				errorTypeBot,
@@ -264,7 +258,7 @@ func (s *APIServer) reinitBotCheck(req upsertBotConfigRequest) {
 			req.TraderConfig.AssetBase(),
 			req.TraderConfig.AssetQuote(),
 		}
-		e = s.checkAddTrustline(*traderAccount, tradingKP, req.TraderConfig.TradingSecretSeed, bot.Name, isTestnet, assets)
+		e = s.checkAddTrustline(*traderAccount, tradingKP, req.TraderConfig.TradingSecretSeed, bot.Name, botNetwork, assets)
 		if e != nil {
 			s.addKelpErrorToMap(makeKelpErrorResponseWrapper(
 				errorTypeBot,
@@ -289,7 +283,7 @@ func (s *APIServer) reinitBotCheck(req upsertBotConfigRequest) {
 				).KelpError)
 				return
 			}
-			_, e = s.checkFundAccount(client, sourceKP.Address(), bot.Name)
+			_, e = s.checkFundAccount(botNetwork, sourceKP.Address(), bot.Name)
 			if e != nil {
 				s.addKelpErrorToMap(makeKelpErrorResponseWrapper(
 					errorTypeBot,
@@ -317,13 +311,10 @@ func (s *APIServer) reinitBotCheck(req upsertBotConfigRequest) {
 	}()
 }
 
-func (s *APIServer) checkAddTrustline(account hProtocol.Account, kp keypair.KP, traderSeed string, botName string, isTestnet bool, assets []hProtocol.Asset) error {
-	activeNetwork := network.PublicNetworkPassphrase
-	client := s.apiPubNet
-	if isTestnet {
-		activeNetwork = network.TestNetworkPassphrase
-		client = s.apiTestNet
-	}
+func (s *APIServer) checkAddTrustline(account hProtocol.Account, kp keypair.KP, traderSeed string, botName string, botNetwork string, assets []hProtocol.Asset) error {
+	activeNetwork := s.networkPassphrase(botNetwork)
+	client := s.horizonClient(botNetwork)
+	isTestnet := botNetwork == networkTestnet
 
 	address := kp.Address()
 	// find trustlines to be added
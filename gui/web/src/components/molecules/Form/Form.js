@@ -413,9 +413,14 @@ class Form extends Component {
     //   tradingPlatform = this.props.configData.trader_config.trading_exchange;
     // }
 
-    let isTestNet = this.props.configData.trader_config.horizon_url.includes("test");
+    const customNetwork = this.props.networks ? this.props.networks.custom : null;
+    const networkPassphrase = this.props.configData.trader_config.network_passphrase;
+    let isCustomNet = customNetwork != null && (networkPassphrase === customNetwork.network_passphrase || this.props.configData.trader_config.horizon_url === customNetwork.horizon_url);
+    let isTestNet = !isCustomNet && this.props.configData.trader_config.horizon_url.includes("test");
     let network = "PubNet";
-    if (isTestNet) {
+    if (isCustomNet) {
+      network = "Custom";
+    } else if (isTestNet) {
       network = "TestNet";
     }
 
@@ -508,12 +513,23 @@ class Form extends Component {
                 segments={this.props.segmentNetworkOptions}
                 selected={network}
                 onSelect={(selected) => {
-                  // TODO use URI passed in from command line, or indicate to backend it's test/public
+                  // use the URIs passed in from the command line when the server has loaded them
                   let newValue = "https://horizon-testnet.stellar.org";
+                  let newPassphrase = "";
                   if (selected === "PubNet") {
                     newValue = "https://horizon.stellar.org";
                   }
+                  if (this.props.networks) {
+                    newValue = this.props.networks.testnet.horizon_url;
+                    if (selected === "PubNet") {
+                      newValue = this.props.networks.pubnet.horizon_url;
+                    } else if (selected === "Custom" && customNetwork) {
+                      newValue = customNetwork.horizon_url;
+                      newPassphrase = customNetwork.network_passphrase;
+                    }
+                  }
                   this.props.onChange("trader_config.horizon_url", { target: { value: newValue } });
+                  this.props.onChange("trader_config.network_passphrase", { target: { value: newPassphrase } });
                 }}
               />
             </FieldItem>
@@ -4,6 +4,7 @@ import getBotConfig from '../../../kelp-ops-api/getBotConfig';
 import getNewBotConfig from '../../../kelp-ops-api/getNewBotConfig';
 import upsertBotConfig from '../../../kelp-ops-api/upsertBotConfig';
 import fetchOptionsMetadata from '../../../kelp-ops-api/fetchOptionsMetadata';
+import fetchNetworks from '../../../kelp-ops-api/fetchNetworks';
 import LoadingAnimation from '../../atoms/LoadingAnimation/LoadingAnimation';
 
 class NewBot extends Component {
@@ -14,6 +15,7 @@ class NewBot extends Component {
       configData: null,
       errorResp: null,
       optionsMetadata: null,
+      networks: null,
     };
 
     this.saveNew = this.saveNew.bind(this);
@@ -23,12 +25,14 @@ class NewBot extends Component {
     this.onChangeForm = this.onChangeForm.bind(this);
     this.updateUsingDotNotation = this.updateUsingDotNotation.bind(this);
     this.loadOptionsMetadata = this.loadOptionsMetadata.bind(this);
+    this.loadNetworks = this.loadNetworks.bind(this);
 
     this._asyncRequests = {};
   }
 
   componentDidMount() {
     this.loadOptionsMetadata();
+    this.loadNetworks();
   }
 
   componentWillUnmount() {
@@ -37,6 +41,30 @@ class NewBot extends Component {
     }
   }
 
+  loadNetworks() {
+    if (this._asyncRequests["networks"]) {
+      return
+    }
+
+    var _this = this;
+    this._asyncRequests["networks"] = fetchNetworks(this.props.baseUrl).then(networks => {
+      if (!_this._asyncRequests["networks"]) {
+        // if it has been deleted it means we don't want to process the result
+        return
+      }
+
+      delete _this._asyncRequests["networks"];
+      if (networks.hasOwnProperty('error')) {
+        console.log("error when loading networks: " + networks.error);
+        setTimeout(_this.loadNetworks, 1000);
+      } else {
+        _this.setState(prevState => ({
+          networks: networks,
+        }))
+      }
+    });
+  }
+
   loadOptionsMetadata() {
     if (this._asyncRequests["optionsMetadata"]) {
       return
@@ -177,6 +205,9 @@ class NewBot extends Component {
     if (this.props.enablePubnetBots) {
       segmentNetworkOptions.push("PubNet");
     }
+    if (this.state.networks && this.state.networks.custom) {
+      segmentNetworkOptions.push("Custom");
+    }
 
     if (this.props.location.pathname === "/new") {
       if (!this.state.configData) {
@@ -189,6 +220,7 @@ class NewBot extends Component {
         baseUrl={this.props.baseUrl}
         title="New Bot"
         segmentNetworkOptions={segmentNetworkOptions}
+        networks={this.state.networks}
         optionsMetadata={this.state.optionsMetadata}
         onChange={this.onChangeForm}
         configData={this.state.configData}
@@ -233,6 +265,7 @@ class NewBot extends Component {
       baseUrl={this.props.baseUrl}
       title={formTitle}
       segmentNetworkOptions={segmentNetworkOptions}
+      networks={this.state.networks}
       optionsMetadata={this.state.optionsMetadata}
       onChange={this.onChangeForm}
       configData={this.state.configData}
@@ -0,0 +1,7 @@
+export default (baseUrl) => {
+    return fetch(baseUrl + "/api/v1/networks", {
+        method: "GET",
+    }).then(resp => {
+        return resp.json();
+    });
+};
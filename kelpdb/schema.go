@@ -8,6 +8,7 @@ const SqlTradesTableCreate = "CREATE TABLE IF NOT EXISTS trades (market_id TEXT
 const SqlTradesTableAlter1 = "ALTER TABLE trades ADD COLUMN account_id TEXT"
 const SqlStrategyMirrorTradeTriggersTableCreate = "CREATE TABLE IF NOT EXISTS strategy_mirror_trade_triggers (market_id TEXT NOT NULL, txid TEXT NOT NULL, backing_market_id TEXT NOT NULL, backing_order_id TEXT NOT NULL, PRIMARY KEY (market_id, txid))"
 const SqlTradesTableAlter2 = "ALTER TABLE trades ADD COLUMN order_id TEXT"
+const SqlTradesTableAlter3 = "ALTER TABLE trades ADD COLUMN notional_value DOUBLE PRECISION"
 
 /*
 	indexes
@@ -28,7 +29,8 @@ const SqlTradesIndexCreate3 = "CREATE UNIQUE INDEX IF NOT EXISTS trades_amt ON t
 const SqlMarketsInsertTemplate = "INSERT INTO markets (market_id, exchange_name, base, quote) VALUES ('%s', '%s', '%s', '%s')"
 
 // SqlTradesInsertTemplate inserts into the trades table
-const SqlTradesInsertTemplate = "INSERT INTO trades (market_id, txid, date_utc, action, type, counter_price, base_volume, counter_cost, fee, account_id, order_id) VALUES ('%s', '%s', '%s', '%s', '%s', %.15f, %.15f, %.15f, %.15f, '%s', '%s')"
+// the notional_value is formatted as a string so it can be NULL
+const SqlTradesInsertTemplate = "INSERT INTO trades (market_id, txid, date_utc, action, type, counter_price, base_volume, counter_cost, fee, account_id, order_id, notional_value) VALUES ('%s', '%s', '%s', '%s', '%s', %.15f, %.15f, %.15f, %.15f, '%s', '%s', %s)"
 
 // SqlStrategyMirrorTradeTriggersInsertTemplate inserts into the strategy_mirror_trade_triggers table
 const SqlStrategyMirrorTradeTriggersInsertTemplate = "INSERT INTO strategy_mirror_trade_triggers (market_id, txid, backing_market_id, backing_order_id) VALUES ('%s', '%s', '%s', '%s')"
@@ -38,3 +40,9 @@ const SqlStrategyMirrorTradeTriggersInsertTemplate = "INSERT INTO strategy_mirro
 */
 // SqlQueryMarketsById queries the markets table
 const SqlQueryMarketsById = "SELECT market_id, exchange_name, base, quote FROM markets WHERE market_id = $1 LIMIT 1"
+
+// SqlQueryTradesSince queries the trades table for the trades of a market since a given time, oldest first
+const SqlQueryTradesSince = "SELECT date_utc, counter_price, base_volume FROM trades WHERE market_id = $1 AND date_utc >= $2 ORDER BY date_utc ASC"
+
+// SqlQueryLatestTrades queries the trades table for the latest trades of a market, newest first
+const SqlQueryLatestTrades = "SELECT date_utc, counter_price, base_volume FROM trades WHERE market_id = $1 ORDER BY date_utc DESC LIMIT $2"
@@ -0,0 +1,157 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/support/config"
+	"github.com/stellar/kelp/api"
+)
+
+// basketConfig is the format of the file that defines the components and weights of a basket feed
+type basketConfig struct {
+	Components []basketComponentConfig `valid:"-" toml:"COMPONENTS"`
+}
+
+// basketComponentConfig is a single component of a basket, the weight is the amount of the component in one unit of the basket
+type basketComponentConfig struct {
+	Name     string  `valid:"-" toml:"NAME"`
+	FeedType string  `valid:"-" toml:"FEED_TYPE"`
+	FeedURL  string  `valid:"-" toml:"FEED_URL"`
+	Weight   float64 `valid:"-" toml:"WEIGHT"`
+}
+
+// basketComponent is a component of the basket with its price feed
+type basketComponent struct {
+	name   string
+	feed   api.PriceFeed
+	weight float64
+}
+
+// basketFeed computes the value of a basket as the weighted sum of the prices of its components, the weights file is reloaded
+// whenever it is modified so the basket can be rebalanced without restarting the bot
+type basketFeed struct {
+	weightsFilePath string
+	factory         priceFeedFactory
+
+	// uses mutex for the fields below
+	mutex      *sync.Mutex
+	components []basketComponent
+	modTime    time.Time
+}
+
+// ensure that it implements PriceFeed and compositePriceFeed
+var _ api.PriceFeed = &basketFeed{}
+var _ compositePriceFeed = &basketFeed{}
+
+// makeBasketFeed makes a basket feed from the path to its weights file, the feeds of the components are made with the factory
+func makeBasketFeed(weightsFilePath string, factory priceFeedFactory) (*basketFeed, error) {
+	f := &basketFeed{
+		weightsFilePath: weightsFilePath,
+		factory:         factory,
+		mutex:           &sync.Mutex{},
+	}
+
+	e := f.reloadIfModified()
+	if e != nil {
+		return nil, fmt.Errorf("unable to load basket weights file: %s", e)
+	}
+	return f, nil
+}
+
+// reloadIfModified reloads the weights file if it was modified since it was last read, must be called with the mutex held
+// or before the feed is shared
+func (f *basketFeed) reloadIfModified() error {
+	info, e := os.Stat(f.weightsFilePath)
+	if e != nil {
+		return fmt.Errorf("unable to stat basket weights file '%s': %s", f.weightsFilePath, e)
+	}
+	if info.ModTime().Equal(f.modTime) {
+		return nil
+	}
+
+	// record the modification time even when the file is invalid so we only retry once the file is modified again
+	f.modTime = info.ModTime()
+	components, e := loadBasketComponents(f.weightsFilePath, f.factory)
+	if e != nil {
+		return e
+	}
+
+	if f.components != nil {
+		log.Printf("basket feed: rebalanced using weights file '%s' modified at %s\n", f.weightsFilePath, info.ModTime())
+	}
+	for _, c := range components {
+		log.Printf("basket feed: component '%s' has weight %.10f\n", c.name, c.weight)
+	}
+	f.components = components
+	return nil
+}
+
+func loadBasketComponents(weightsFilePath string, factory priceFeedFactory) ([]basketComponent, error) {
+	var cfg basketConfig
+	e := config.Read(weightsFilePath, &cfg)
+	if e != nil {
+		return nil, fmt.Errorf("unable to read basket weights file '%s': %s", weightsFilePath, e)
+	}
+	if len(cfg.Components) == 0 {
+		return nil, fmt.Errorf("basket weights file '%s' needs at least 1 component in COMPONENTS", weightsFilePath)
+	}
+
+	components := []basketComponent{}
+	for i, c := range cfg.Components {
+		if c.Weight <= 0.0 {
+			return nil, fmt.Errorf("weight of basket component '%s' (index=%d) needs to be > 0.0, was %.10f", c.Name, i, c.Weight)
+		}
+
+		feed, e := factory.makePriceFeed(c.FeedType, c.FeedURL)
+		if e != nil {
+			return nil, fmt.Errorf("error creating a price feed for basket component '%s' (typ='%s', url='%s'): %s", c.Name, c.FeedType, c.FeedURL, e)
+		}
+		components = append(components, basketComponent{
+			name:   c.Name,
+			feed:   feed,
+			weight: c.Weight,
+		})
+	}
+	return components, nil
+}
+
+// subFeeds impl, returns the feeds of the components from the weights file that was last loaded successfully
+func (f *basketFeed) subFeeds() []api.PriceFeed {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	feeds := []api.PriceFeed{}
+	for _, c := range f.components {
+		feeds = append(feeds, c.feed)
+	}
+	return feeds
+}
+
+// GetPrice impl
+func (f *basketFeed) GetPrice() (float64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	e := f.reloadIfModified()
+	if e != nil {
+		// keep quoting against the last good weights rather than failing when the file is being edited
+		log.Printf("basket feed: unable to reload weights, continuing with the previous weights: %s\n", e)
+	}
+
+	value := 0.0
+	for _, c := range f.components {
+		price, e := c.feed.GetPrice()
+		if e != nil {
+			return 0, fmt.Errorf("error fetching price of basket component '%s': %s", c.name, e)
+		}
+		if price <= 0.0 {
+			return 0, fmt.Errorf("price of basket component '%s' was <= 0.0 (%.10f)", c.name, price)
+		}
+		value += c.weight * price
+	}
+	return value, nil
+}
@@ -0,0 +1,109 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func writeTestBasketFile(t *testing.T, path string, contents string, modTime time.Time) {
+	e := ioutil.WriteFile(path, []byte(contents), 0644)
+	if !assert.NoError(t, e) {
+		t.FailNow()
+	}
+	e = os.Chtimes(path, modTime, modTime)
+	if !assert.NoError(t, e) {
+		t.FailNow()
+	}
+}
+
+func TestBasketFeed(t *testing.T) {
+	dir, e := ioutil.TempDir("", "basketFeed")
+	if !assert.NoError(t, e) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/basket.cfg"
+	modTime := time.Unix(1600000000, 0)
+
+	writeTestBasketFile(t, path, `
+[[COMPONENTS]]
+NAME="USD"
+FEED_TYPE="fixed"
+FEED_URL="1.0"
+WEIGHT=0.6
+[[COMPONENTS]]
+NAME="EUR"
+FEED_TYPE="fixed"
+FEED_URL="1.2"
+WEIGHT=0.4
+`, modTime)
+	f, e := makeBasketFeed(path, priceFeedFactory{})
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	price, e := f.GetPrice()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.InDelta(t, 0.6*1.0+0.4*1.2, price, 0.0000001)
+
+	// rebalance
+	writeTestBasketFile(t, path, `
+[[COMPONENTS]]
+NAME="USD"
+FEED_TYPE="fixed"
+FEED_URL="1.0"
+WEIGHT=0.5
+[[COMPONENTS]]
+NAME="EUR"
+FEED_TYPE="fixed"
+FEED_URL="1.2"
+WEIGHT=0.5
+`, modTime.Add(time.Minute))
+	price, e = f.GetPrice()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.InDelta(t, 0.5*1.0+0.5*1.2, price, 0.0000001)
+
+	// an invalid file keeps the previous weights
+	writeTestBasketFile(t, path, `
+[[COMPONENTS]]
+NAME="USD"
+FEED_TYPE="fixed"
+FEED_URL="1.0"
+WEIGHT=-1.0
+`, modTime.Add(2*time.Minute))
+	price, e = f.GetPrice()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.InDelta(t, 0.5*1.0+0.5*1.2, price, 0.0000001)
+
+	// the invalid file is not read again until it is modified, so a file with the same modification time is not picked up
+	validContents := `
+[[COMPONENTS]]
+NAME="USD"
+FEED_TYPE="fixed"
+FEED_URL="1.0"
+WEIGHT=1.0
+`
+	writeTestBasketFile(t, path, validContents, modTime.Add(2*time.Minute))
+	price, e = f.GetPrice()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.InDelta(t, 0.5*1.0+0.5*1.2, price, 0.0000001)
+
+	writeTestBasketFile(t, path, validContents, modTime.Add(3*time.Minute))
+	price, e = f.GetPrice()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.InDelta(t, 1.0, price, 0.0000001)
+}
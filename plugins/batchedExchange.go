@@ -445,6 +445,16 @@ func Ops2CommandsHack(
 				return nil, fmt.Errorf("unable to convert *txnbuild.ManageSellOffer to a Command: %s", e)
 			}
 			commands = append(commands, c...)
+		case *txnbuild.ManageBuyOffer:
+			mso, e := convertMBO2MSO(manageOffer)
+			if e != nil {
+				return nil, fmt.Errorf("unable to convert *txnbuild.ManageBuyOffer to a *txnbuild.ManageSellOffer: %s", e)
+			}
+			c, e := op2CommandsHack(mso, baseAsset, quoteAsset, offerID2OrderID, orderConstraints)
+			if e != nil {
+				return nil, fmt.Errorf("unable to convert *txnbuild.ManageBuyOffer to a Command: %s", e)
+			}
+			commands = append(commands, c...)
 		default:
 			return nil, fmt.Errorf("unable to recognize transaction mutator op (%s): %v", reflect.TypeOf(op), manageOffer)
 		}
@@ -12,17 +12,18 @@ import (
 
 // BuySellConfig contains the configuration params for this strategy
 type BuySellConfig struct {
-	PriceTolerance         float64       `valid:"-" toml:"PRICE_TOLERANCE" json:"price_tolerance"`
-	AmountTolerance        float64       `valid:"-" toml:"AMOUNT_TOLERANCE" json:"amount_tolerance"`
-	RateOffsetPercent      float64       `valid:"-" toml:"RATE_OFFSET_PERCENT" json:"rate_offset_percent"`
-	RateOffset             float64       `valid:"-" toml:"RATE_OFFSET" json:"rate_offset"`
-	RateOffsetPercentFirst bool          `valid:"-" toml:"RATE_OFFSET_PERCENT_FIRST" json:"rate_offset_percent_first"`
-	AmountOfABase          float64       `valid:"-" toml:"AMOUNT_OF_A_BASE" json:"amount_of_a_base"` // the size of order to keep on either side
-	DataTypeA              string        `valid:"-" toml:"DATA_TYPE_A" json:"data_type_a"`
-	DataFeedAURL           string        `valid:"-" toml:"DATA_FEED_A_URL" json:"data_feed_a_url"`
-	DataTypeB              string        `valid:"-" toml:"DATA_TYPE_B" json:"data_type_b"`
-	DataFeedBURL           string        `valid:"-" toml:"DATA_FEED_B_URL" json:"data_feed_b_url"`
-	Levels                 []StaticLevel `valid:"-" toml:"LEVELS" json:"levels"`
+	PriceTolerance         float64          `valid:"-" toml:"PRICE_TOLERANCE" json:"price_tolerance"`
+	AmountTolerance        float64          `valid:"-" toml:"AMOUNT_TOLERANCE" json:"amount_tolerance"`
+	RateOffsetPercent      float64          `valid:"-" toml:"RATE_OFFSET_PERCENT" json:"rate_offset_percent"`
+	RateOffset             float64          `valid:"-" toml:"RATE_OFFSET" json:"rate_offset"`
+	RateOffsetPercentFirst bool             `valid:"-" toml:"RATE_OFFSET_PERCENT_FIRST" json:"rate_offset_percent_first"`
+	AmountOfABase          float64          `valid:"-" toml:"AMOUNT_OF_A_BASE" json:"amount_of_a_base"` // the size of order to keep on either side
+	DataTypeA              string           `valid:"-" toml:"DATA_TYPE_A" json:"data_type_a"`
+	DataFeedAURL           string           `valid:"-" toml:"DATA_FEED_A_URL" json:"data_feed_a_url"`
+	DataTypeB              string           `valid:"-" toml:"DATA_TYPE_B" json:"data_type_b"`
+	DataFeedBURL           string           `valid:"-" toml:"DATA_FEED_B_URL" json:"data_feed_b_url"`
+	Levels                 []StaticLevel    `valid:"-" toml:"LEVELS" json:"levels"`
+	Randomize              *RandomizeConfig `valid:"-" toml:"RANDOMIZE" json:"randomize"` // can be nil, in which case levels are not randomized
 }
 
 // MakeBuysellConfig factory method
@@ -67,6 +68,7 @@ func makeBuySellStrategy(
 	assetBase *hProtocol.Asset,
 	assetQuote *hProtocol.Asset,
 	config *BuySellConfig,
+	spreadWidener api.SpreadWidener,
 ) (api.Strategy, error) {
 	log.Printf("1-#################################################################")
 	offsetSell := rateOffset{
@@ -84,19 +86,29 @@ func makeBuySellStrategy(
 		return nil, fmt.Errorf("cannot make the buysell strategy because we could not make the sell side feed pair: %s", e)
 	}
 	orderConstraints := sdex.GetOrderConstraints(pair)
-	sellSideStrategy := makeSellSideStrategy(
-		sdex,
-		orderConstraints,
-		ieif,
-		assetBase,
-		assetQuote,
+	sellSideLevelProvider, e := maybeRandomizeLevelProvider(
 		makeStaticSpreadLevelProvider(
 			config.Levels,
 			config.AmountOfABase,
 			offsetSell,
 			sellSideFeedPair,
 			orderConstraints,
+			spreadWidener,
 		),
+		config.Randomize,
+		orderConstraints,
+		false,
+	)
+	if e != nil {
+		return nil, fmt.Errorf("cannot make the buysell strategy because we could not randomize the sell side levels: %s", e)
+	}
+	sellSideStrategy := makeSellSideStrategy(
+		sdex,
+		orderConstraints,
+		ieif,
+		assetBase,
+		assetQuote,
+		sellSideLevelProvider,
 		config.PriceTolerance,
 		config.AmountTolerance,
 		false,
@@ -128,6 +140,29 @@ func makeBuySellStrategy(
 
 	log.Printf("************buysellStrategy.makeBuySellStrategy overridden buy levels: %s", buyLevels)
 
+	// the buy side levels are inverted, so keep more digits of their price when bids are placed as ManageBuyOffer ops since the bid price is
+	// rounded to the precision of the pair only once it is converted back from the level
+	buySideLevelConstraints := orderConstraints
+	if sdex.UsesManageBuyOffer() {
+		buySideLevelConstraints = invertedLevelConstraints(orderConstraints)
+	}
+	buySideLevelProvider, e := maybeRandomizeLevelProvider(
+		makeStaticSpreadLevelProvider(
+			buyLevels,
+			config.AmountOfABase,
+			offsetBuy,
+			buySideFeedPair,
+			buySideLevelConstraints,
+			spreadWidener,
+		),
+		config.Randomize,
+		buySideLevelConstraints,
+		true,
+	)
+	if e != nil {
+		return nil, fmt.Errorf("cannot make the buysell strategy because we could not randomize the buy side levels: %s", e)
+	}
+
 	// switch sides of base/quote here for buy side
 	buySideStrategy := makeSellSideStrategy(
 		sdex,
@@ -135,13 +170,7 @@ func makeBuySellStrategy(
 		ieif,
 		assetQuote,
 		assetBase,
-		makeStaticSpreadLevelProvider(
-			buyLevels,
-			config.AmountOfABase,
-			offsetBuy,
-			buySideFeedPair,
-			orderConstraints,
-		),
+		buySideLevelProvider,
 		config.PriceTolerance,
 		config.AmountTolerance,
 		true,
@@ -0,0 +1,175 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sync"
+	"time"
+
+	"github.com/stellar/kelp/api"
+)
+
+const defaultCachedFeedTTL = 1 * time.Second
+
+// cachedFeeds holds the cached feeds that have been made keyed by their URL so strategies and filters that use the same cached feed
+// share it and only hit the upstream API once per TTL
+var cachedFeeds = map[string]*cachedFeed{}
+var cachedFeedsMutex = &sync.Mutex{}
+
+// cachedFeed caches the price of an ordered list of fallback feeds, falling back to the last known good price when all of them fail.
+// The feeds are queried at most once per TTL, including when all of them failed, so an outage does not multiply the upstream requests
+type cachedFeed struct {
+	feeds          []api.PriceFeed
+	ttl            time.Duration
+	maxStale       time.Duration
+	decayPerMinute float64
+	now            func() time.Time
+
+	// uses mutex for the fields below
+	mutex             *sync.Mutex
+	lastPrice         float64
+	lastPriceAsOf     time.Time
+	lastAttemptAt     time.Time
+	lastAttemptFailed bool
+}
+
+// ensure that it implements PriceFeed and compositePriceFeed
+var _ api.PriceFeed = &cachedFeed{}
+var _ compositePriceFeed = &cachedFeed{}
+
+// makeCachedFeed makes a cached feed from a URL formatted like so:
+// ttl_millis=1000,max_stale_millis=60000,decay_per_minute=0.001|primary_feed_type/feed_url[|fallback_feed_type/feed_url]
+// all the options are optional, the same URL always returns the same cached feed unless the feed is traced, in which case its traced
+// sub-feeds are not shared with the untraced feeds
+func makeCachedFeed(url string, factory priceFeedFactory) (*cachedFeed, error) {
+	cachedFeedsMutex.Lock()
+	defer cachedFeedsMutex.Unlock()
+
+	if f, ok := cachedFeeds[url]; ok && !factory.trace {
+		return f, nil
+	}
+
+	options, feeds, e := parseCompositeFeedURL(url, []string{"ttl_millis", "max_stale_millis", "decay_per_minute"}, factory)
+	if e != nil {
+		return nil, fmt.Errorf("unable to parse cache feed URL: %s", e)
+	}
+
+	ttl := defaultCachedFeedTTL
+	if v, ok := options["ttl_millis"]; ok {
+		ttl = time.Duration(v) * time.Millisecond
+	}
+	maxStale := time.Duration(0)
+	if v, ok := options["max_stale_millis"]; ok {
+		maxStale = time.Duration(v) * time.Millisecond
+	}
+	decayPerMinute := 0.0
+	if v, ok := options["decay_per_minute"]; ok {
+		decayPerMinute = v
+	}
+
+	f, e := newCachedFeed(feeds, ttl, maxStale, decayPerMinute)
+	if e != nil {
+		return nil, e
+	}
+	if !factory.trace {
+		cachedFeeds[url] = f
+	}
+	return f, nil
+}
+
+func newCachedFeed(feeds []api.PriceFeed, ttl time.Duration, maxStale time.Duration, decayPerMinute float64) (*cachedFeed, error) {
+	if len(feeds) == 0 {
+		return nil, fmt.Errorf("need to provide at least 1 price feed to the cache feed")
+	}
+	if ttl < 0 || maxStale < 0 {
+		return nil, fmt.Errorf("ttl (%s) and max stale (%s) cannot be negative", ttl, maxStale)
+	}
+	if decayPerMinute < 0.0 || decayPerMinute >= 1.0 {
+		return nil, fmt.Errorf("decay_per_minute (%.6f) needs to be >= 0.0 and < 1.0", decayPerMinute)
+	}
+
+	return &cachedFeed{
+		feeds:          feeds,
+		ttl:            ttl,
+		maxStale:       maxStale,
+		decayPerMinute: decayPerMinute,
+		now:            time.Now,
+		mutex:          &sync.Mutex{},
+	}, nil
+}
+
+// subFeeds impl
+func (f *cachedFeed) subFeeds() []api.PriceFeed {
+	return f.feeds
+}
+
+// GetPrice impl
+func (f *cachedFeed) GetPrice() (float64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	now := f.now()
+	if f.lastAttemptAt.IsZero() || now.Sub(f.lastAttemptAt) >= f.ttl {
+		f.lastAttemptAt = now
+		f.lastAttemptFailed = !f.fetchFromFeeds(now)
+	} else if f.lastAttemptFailed {
+		log.Printf("cache feed: not querying the feeds again until the ttl (%s) has passed since all of them failed %s ago\n", f.ttl, now.Sub(f.lastAttemptAt))
+	}
+
+	if !f.lastAttemptFailed {
+		return f.lastPrice, nil
+	}
+
+	if f.lastPriceAsOf.IsZero() || f.maxStale == 0 {
+		return 0.0, fmt.Errorf("all %d feeds in the cache feed failed and there is no last known good price to fall back to", len(f.feeds))
+	}
+
+	age := now.Sub(f.lastPriceAsOf)
+	if age > f.maxStale {
+		return 0.0, fmt.Errorf("all %d feeds in the cache feed failed and the last known good price (%.10f) is stale, it is %s old which is older than the max stale %s", len(f.feeds), f.lastPrice, age, f.maxStale)
+	}
+
+	decayed := f.lastPrice * math.Pow(1.0-f.decayPerMinute, age.Minutes())
+	log.Printf("cache feed: all %d feeds failed, falling back to the last known good price %.10f from %s ago decayed to %.10f\n", len(f.feeds), f.lastPrice, age, decayed)
+	return decayed, nil
+}
+
+// fetchFromFeeds tries the feeds in order and updates the last known good price from the first one that succeeds, returns false when all of them fail
+func (f *cachedFeed) fetchFromFeeds(now time.Time) bool {
+	for i, feed := range f.feeds {
+		price, asOf, e := f.fetch(feed, now)
+		if e != nil {
+			log.Printf("cache feed: feed at index %d failed, trying the next fallback: %s\n", i, e)
+			continue
+		}
+
+		f.lastPrice = price
+		f.lastPriceAsOf = asOf
+		return true
+	}
+	return false
+}
+
+// fetch gets the price from a single feed along with the time the price is as of, it fails prices that are already stale
+func (f *cachedFeed) fetch(feed api.PriceFeed, now time.Time) (float64, time.Time, error) {
+	var price float64
+	asOf := now
+	var e error
+	if tsFeed, ok := feed.(api.TimestampedPriceFeed); ok {
+		price, asOf, e = tsFeed.GetPriceWithTimestamp()
+	} else {
+		price, e = feed.GetPrice()
+	}
+	if e != nil {
+		return 0.0, time.Time{}, e
+	}
+
+	if price <= 0.0 {
+		return 0.0, time.Time{}, fmt.Errorf("price was <= 0.0 (%.10f)", price)
+	}
+	if f.maxStale > 0 && now.Sub(asOf) > f.maxStale {
+		return 0.0, time.Time{}, fmt.Errorf("stale price, last updated %s ago which is older than the max stale %s", now.Sub(asOf), f.maxStale)
+	}
+	return price, asOf, nil
+}
@@ -0,0 +1,132 @@
+package plugins
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stellar/kelp/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// testScriptedFeed returns the next price or error from its script on each call and counts the calls
+type testScriptedFeed struct {
+	prices []float64
+	errors []error
+	calls  int
+}
+
+func (f *testScriptedFeed) GetPrice() (float64, error) {
+	i := f.calls
+	f.calls++
+	return f.prices[i], f.errors[i]
+}
+
+func TestCachedFeed(t *testing.T) {
+	downErr := fmt.Errorf("provider down")
+	testCases := []struct {
+		name           string
+		primary        *testScriptedFeed
+		secondary      *testScriptedFeed
+		decayPerMinute float64
+		advances       []time.Duration
+		wantPrices     []float64
+		wantErrors     []bool
+		wantCalls      int // number of calls to the primary feed
+	}{
+		{
+			name:       "cached within ttl",
+			primary:    &testScriptedFeed{prices: []float64{1.0, 2.0}, errors: []error{nil, nil}},
+			secondary:  &testScriptedFeed{},
+			advances:   []time.Duration{0, 500 * time.Millisecond, time.Second},
+			wantPrices: []float64{1.0, 1.0, 2.0},
+			wantErrors: []bool{false, false, false},
+			wantCalls:  2,
+		}, {
+			name:       "falls back to secondary",
+			primary:    &testScriptedFeed{prices: []float64{0.0}, errors: []error{downErr}},
+			secondary:  &testScriptedFeed{prices: []float64{3.0}, errors: []error{nil}},
+			advances:   []time.Duration{0},
+			wantPrices: []float64{3.0},
+			wantErrors: []bool{false},
+			wantCalls:  1,
+		}, {
+			name:       "falls back to last known good then errors when stale",
+			primary:    &testScriptedFeed{prices: []float64{1.0, 0.0, 0.0}, errors: []error{nil, downErr, downErr}},
+			secondary:  &testScriptedFeed{prices: []float64{0.0, 0.0}, errors: []error{downErr, downErr}},
+			advances:   []time.Duration{0, time.Minute, 10 * time.Minute},
+			wantPrices: []float64{1.0, 1.0, 0.0},
+			wantErrors: []bool{false, false, true},
+			wantCalls:  3,
+		}, {
+			name:           "falls back to decayed last known good",
+			primary:        &testScriptedFeed{prices: []float64{1.0, 0.0, 0.0}, errors: []error{nil, downErr, downErr}},
+			secondary:      &testScriptedFeed{prices: []float64{0.0, 0.0}, errors: []error{downErr, downErr}},
+			decayPerMinute: 0.1,
+			advances:       []time.Duration{0, time.Minute, time.Minute},
+			wantPrices:     []float64{1.0, 0.9, 0.81},
+			wantErrors:     []bool{false, false, false},
+			wantCalls:      3,
+		}, {
+			name:       "does not query the feeds again within the ttl of a failure",
+			primary:    &testScriptedFeed{prices: []float64{1.0, 0.0, 2.0}, errors: []error{nil, downErr, nil}},
+			secondary:  &testScriptedFeed{prices: []float64{0.0}, errors: []error{downErr}},
+			advances:   []time.Duration{0, time.Second, 200 * time.Millisecond, 200 * time.Millisecond, 600 * time.Millisecond},
+			wantPrices: []float64{1.0, 1.0, 1.0, 1.0, 2.0},
+			wantErrors: []bool{false, false, false, false, false},
+			wantCalls:  3,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.name, func(t *testing.T) {
+			f, e := newCachedFeed([]api.PriceFeed{kase.primary, kase.secondary}, time.Second, 5*time.Minute, kase.decayPerMinute)
+			if !assert.NoError(t, e) {
+				return
+			}
+			now := time.Unix(1600000000, 0)
+			f.now = func() time.Time { return now }
+
+			for i, advance := range kase.advances {
+				now = now.Add(advance)
+				price, e := f.GetPrice()
+				if kase.wantErrors[i] {
+					assert.Error(t, e)
+					continue
+				}
+				if !assert.NoError(t, e) {
+					return
+				}
+				assert.InDelta(t, kase.wantPrices[i], price, 0.0000001)
+			}
+			assert.Equal(t, kase.wantCalls, kase.primary.calls)
+		})
+	}
+}
+
+func TestNewCachedFeedDecayValidation(t *testing.T) {
+	feeds := []api.PriceFeed{&testScriptedFeed{}}
+	for _, decayPerMinute := range []float64{-0.1, 1.0} {
+		_, e := newCachedFeed(feeds, time.Second, time.Minute, decayPerMinute)
+		assert.Error(t, e, "decay_per_minute=%.1f", decayPerMinute)
+	}
+
+	_, e := newCachedFeed(feeds, time.Second, time.Minute, 0.5)
+	assert.NoError(t, e)
+}
+
+func TestMakeCachedFeedIsShared(t *testing.T) {
+	url := "ttl_millis=2000|fixed/1.0|fixed/2.0"
+	f1, e := makeCachedFeed(url, priceFeedFactory{})
+	if !assert.NoError(t, e) {
+		return
+	}
+	f2, e := makeCachedFeed(url, priceFeedFactory{})
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	assert.True(t, f1 == f2)
+	assert.Equal(t, 2*time.Second, f1.ttl)
+	assert.Equal(t, 2, len(f1.feeds))
+}
@@ -0,0 +1,441 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/support/utils"
+)
+
+// filterConditionSeparator separates the conditions of a conditional filter, all of which need to hold for the inner filter to apply
+const filterConditionSeparator = ":"
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday,
+	"mon": time.Monday,
+	"tue": time.Tuesday,
+	"wed": time.Wednesday,
+	"thu": time.Thursday,
+	"fri": time.Friday,
+	"sat": time.Saturday,
+}
+
+// sideFilter applies the inner filter only to the ops and offers on one side of the book, ops on the other side are kept as-is
+type sideFilter struct {
+	name       string
+	baseAsset  hProtocol.Asset
+	quoteAsset hProtocol.Asset
+	isSell     bool
+	inner      SubmitFilter
+}
+
+var _ SubmitFilter = &sideFilter{}
+
+func (f *sideFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer) ([]txnbuild.Operation, error) {
+	// buy and sell ops are not always contiguous (ex: earlier filters move the delete ops of both sides to the front) so we remember
+	// which positions hold the ops on our side and put the filtered ops back in those positions
+	sideOps := []txnbuild.Operation{}
+	isSide := make([]bool, len(ops))
+	lastSideIdx := -1
+	for i, op := range ops {
+		isSideOp, e := f.isSideOp(op)
+		if e != nil {
+			return nil, fmt.Errorf("could not determine side of op: %s", e)
+		}
+
+		if isSideOp {
+			isSide[i] = true
+			lastSideIdx = i
+			sideOps = append(sideOps, op)
+		}
+	}
+
+	var filteredOps []txnbuild.Operation
+	var e error
+	if f.isSell {
+		filteredOps, e = f.inner.Apply(sideOps, sellingOffers, []hProtocol.Offer{})
+	} else {
+		filteredOps, e = f.inner.Apply(sideOps, []hProtocol.Offer{}, buyingOffers)
+	}
+	if e != nil {
+		return nil, fmt.Errorf("could not apply inner filter: %s", e)
+	}
+
+	return mergeSideOps(ops, isSide, lastSideIdx, filteredOps), nil
+}
+
+// mergeSideOps puts the filtered ops in the positions of the ops on our side in order, any filtered ops beyond the number of positions go
+// after the last position and any positions beyond the number of filtered ops are dropped
+func mergeSideOps(ops []txnbuild.Operation, isSide []bool, lastSideIdx int, filteredOps []txnbuild.Operation) []txnbuild.Operation {
+	mergedOps := []txnbuild.Operation{}
+	next := 0
+	for i, op := range ops {
+		if !isSide[i] {
+			mergedOps = append(mergedOps, op)
+			continue
+		}
+
+		if i == lastSideIdx {
+			mergedOps = append(mergedOps, filteredOps[next:]...)
+			next = len(filteredOps)
+		} else if next < len(filteredOps) {
+			mergedOps = append(mergedOps, filteredOps[next])
+			next++
+		}
+	}
+	// there were no ops on our side
+	return append(mergedOps, filteredOps[next:]...)
+}
+
+// isSideOp returns true if the op is an offer on the side of this filter
+func (f *sideFilter) isSideOp(op txnbuild.Operation) (bool, error) {
+	var isSell bool
+	var e error
+	switch o := op.(type) {
+	case *txnbuild.ManageSellOffer:
+		isSell, e = utils.IsSelling(f.baseAsset, f.quoteAsset, o.Selling, o.Buying)
+	case *txnbuild.ManageBuyOffer:
+		isSell, e = utils.IsSelling(f.baseAsset, f.quoteAsset, o.Selling, o.Buying)
+	default:
+		return false, nil
+	}
+	if e != nil {
+		return false, e
+	}
+	return isSell == f.isSell, nil
+}
+
+// String is the Stringer method
+func (f *sideFilter) String() string {
+	return f.name
+}
+
+// filterCondition decides whether a conditional filter applies in the current update cycle
+type filterCondition struct {
+	description string
+	isMet       func(now time.Time) (bool, error)
+}
+
+// conditionalFilter applies the inner filter only when all its conditions hold, otherwise ops are kept as-is
+type conditionalFilter struct {
+	name       string
+	conditions []filterCondition
+	inner      SubmitFilter
+	now        func() time.Time
+}
+
+var _ SubmitFilter = &conditionalFilter{}
+
+func (f *conditionalFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer) ([]txnbuild.Operation, error) {
+	now := f.now()
+	for _, c := range f.conditions {
+		isMet, e := c.isMet(now)
+		if e != nil {
+			return nil, fmt.Errorf("could not evaluate condition '%s': %s", c.description, e)
+		}
+		if !isMet {
+			log.Printf("conditionalFilter: condition '%s' does not hold, skipping filter (%s)\n", c.description, f.name)
+			return ops, nil
+		}
+	}
+
+	ops, e := f.inner.Apply(ops, sellingOffers, buyingOffers)
+	if e != nil {
+		return nil, fmt.Errorf("could not apply inner filter: %s", e)
+	}
+	return ops, nil
+}
+
+// String is the Stringer method
+func (f *conditionalFilter) String() string {
+	return f.name
+}
+
+// groupFilter applies a list of filters sequentially, same as when they are listed in the FILTERS config
+type groupFilter struct {
+	name    string
+	filters []SubmitFilter
+}
+
+var _ SubmitFilter = &groupFilter{}
+
+func (f *groupFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer) ([]txnbuild.Operation, error) {
+	var e error
+	for i, filter := range f.filters {
+		ops, e = filter.Apply(ops, sellingOffers, buyingOffers)
+		if e != nil {
+			return nil, fmt.Errorf("could not apply filter at index %d of group '%s': %s", i, f.name, e)
+		}
+	}
+	return ops, nil
+}
+
+// String is the Stringer method
+func (f *groupFilter) String() string {
+	return f.name
+}
+
+func filterSide(f *FilterFactory, configInput string) (SubmitFilter, error) {
+	// parts[0] = "side", parts[1] = sell or buy, parts[2] = inner filter which can have more "/" chars
+	parts := strings.SplitN(configInput, "/", 3)
+	if len(parts) != 3 {
+		return nil, fmt.Errorf("\"side\" filter needs 3 parts separated by the '/' delimiter (side/<sell|buy>/<filter>) but we received %s", configInput)
+	}
+
+	var isSell bool
+	if parts[1] == "sell" {
+		isSell = true
+	} else if parts[1] == "buy" {
+		isSell = false
+	} else {
+		return nil, fmt.Errorf("invalid side '%s' in config input (%s), needs to be either \"sell\" or \"buy\"", parts[1], configInput)
+	}
+
+	inner, e := f.MakeFilter(parts[2])
+	if e != nil {
+		return nil, fmt.Errorf("could not make inner filter for config input (%s): %s", configInput, e)
+	}
+
+	return &sideFilter{
+		name:       configInput,
+		baseAsset:  f.BaseAsset,
+		quoteAsset: f.QuoteAsset,
+		isSell:     isSell,
+		inner:      inner,
+	}, nil
+}
+
+func filterWhen(f *FilterFactory, configInput string) (SubmitFilter, error) {
+	// "when/" is followed by the conditions, which can contain "/" chars within parentheses, and then the inner filter
+	rest := strings.TrimPrefix(configInput, "when/")
+	conditionsPart, innerPart, e := splitFirstTopLevel(rest, "/")
+	if e != nil {
+		return nil, fmt.Errorf("invalid config input (%s): %s", configInput, e)
+	}
+	if conditionsPart == "" || innerPart == "" {
+		return nil, fmt.Errorf("\"when\" filter needs 3 parts separated by the '/' delimiter (when/<conditions>/<filter>) but we received %s", configInput)
+	}
+
+	conditions := []filterCondition{}
+	for conditionsPart != "" {
+		var conditionString string
+		conditionString, conditionsPart, e = splitFirstTopLevel(conditionsPart, filterConditionSeparator)
+		if e != nil {
+			return nil, fmt.Errorf("invalid conditions in config input (%s): %s", configInput, e)
+		}
+
+		condition, e := parseFilterCondition(conditionString)
+		if e != nil {
+			return nil, fmt.Errorf("could not parse condition '%s' in config input (%s): %s", conditionString, configInput, e)
+		}
+		conditions = append(conditions, condition)
+	}
+
+	inner, e := f.MakeFilter(innerPart)
+	if e != nil {
+		return nil, fmt.Errorf("could not make inner filter for config input (%s): %s", configInput, e)
+	}
+
+	return &conditionalFilter{
+		name:       configInput,
+		conditions: conditions,
+		inner:      inner,
+		now:        time.Now,
+	}, nil
+}
+
+func filterGroup(f *FilterFactory, configInput string) (SubmitFilter, error) {
+	parts := strings.Split(configInput, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("\"group\" filter needs 2 parts separated by the '/' delimiter (group/<name>) but we received %s", configInput)
+	}
+	groupName := parts[1]
+
+	filterStrings, ok := f.FilterGroups[groupName]
+	if !ok {
+		return nil, fmt.Errorf("could not find filter group '%s' in FILTER_GROUPS", groupName)
+	}
+	if f.activeGroups == nil {
+		f.activeGroups = map[string]bool{}
+	}
+	if f.activeGroups[groupName] {
+		return nil, fmt.Errorf("filter group '%s' includes itself", groupName)
+	}
+	f.activeGroups[groupName] = true
+	defer delete(f.activeGroups, groupName)
+
+	filters := []SubmitFilter{}
+	for _, filterString := range filterStrings {
+		filter, e := f.MakeFilter(filterString)
+		if e != nil {
+			return nil, fmt.Errorf("could not make filter '%s' in group '%s': %s", filterString, groupName, e)
+		}
+		filters = append(filters, filter)
+	}
+
+	return &groupFilter{
+		name:    configInput,
+		filters: filters,
+	}, nil
+}
+
+// splitFirstTopLevel splits s around the first occurrence of sep that is not within parentheses, the second value is empty if there is
+// no such occurrence
+func splitFirstTopLevel(s string, sep string) (string, string, error) {
+	depth := 0
+	for i := 0; i < len(s); i++ {
+		switch {
+		case s[i] == '(':
+			depth++
+		case s[i] == ')':
+			depth--
+			if depth < 0 {
+				return "", "", fmt.Errorf("unbalanced parentheses in '%s'", s)
+			}
+		case depth == 0 && strings.HasPrefix(s[i:], sep):
+			return s[:i], s[i+len(sep):], nil
+		}
+	}
+	if depth != 0 {
+		return "", "", fmt.Errorf("unbalanced parentheses in '%s'", s)
+	}
+	return s, "", nil
+}
+
+// parseFilterCondition parses one of the following conditions, where days and hours are in UTC:
+//   - days=<day>[,<day>...] where day is one of sun, mon, tue, wed, thu, fri, sat, weekdays or weekends
+//   - hours=<start>-<end> which holds from the start hour (inclusive) to the end hour (exclusive), can wrap around midnight
+//   - feed(<feedType>/<feedURL>)<comparison><value> where comparison is one of <, <=, >, >=
+func parseFilterCondition(condition string) (filterCondition, error) {
+	if strings.HasPrefix(condition, "days=") {
+		return parseDaysCondition(condition)
+	} else if strings.HasPrefix(condition, "hours=") {
+		return parseHoursCondition(condition)
+	} else if strings.HasPrefix(condition, "feed(") {
+		return parseFeedCondition(condition)
+	}
+	return filterCondition{}, fmt.Errorf("unknown condition, needs to start with one of \"days=\", \"hours=\" or \"feed(\"")
+}
+
+func parseDaysCondition(condition string) (filterCondition, error) {
+	days := map[time.Weekday]bool{}
+	for _, day := range strings.Split(strings.TrimPrefix(condition, "days="), ",") {
+		switch day {
+		case "weekdays":
+			for d := time.Monday; d <= time.Friday; d++ {
+				days[d] = true
+			}
+		case "weekends":
+			days[time.Saturday] = true
+			days[time.Sunday] = true
+		default:
+			d, ok := weekdayNames[day]
+			if !ok {
+				return filterCondition{}, fmt.Errorf("invalid day '%s'", day)
+			}
+			days[d] = true
+		}
+	}
+
+	return filterCondition{
+		description: condition,
+		isMet: func(now time.Time) (bool, error) {
+			return days[now.UTC().Weekday()], nil
+		},
+	}, nil
+}
+
+func parseHoursCondition(condition string) (filterCondition, error) {
+	hours := strings.Split(strings.TrimPrefix(condition, "hours="), "-")
+	if len(hours) != 2 {
+		return filterCondition{}, fmt.Errorf("hours need to be formatted as <start>-<end>")
+	}
+	start, e := strconv.Atoi(hours[0])
+	if e != nil || start < 0 || start > 23 {
+		return filterCondition{}, fmt.Errorf("invalid start hour '%s', needs to be between 0 and 23", hours[0])
+	}
+	end, e := strconv.Atoi(hours[1])
+	if e != nil || end < 0 || end > 24 {
+		return filterCondition{}, fmt.Errorf("invalid end hour '%s', needs to be between 0 and 24", hours[1])
+	}
+	if start == end {
+		return filterCondition{}, fmt.Errorf("start hour and end hour cannot be the same")
+	}
+
+	return filterCondition{
+		description: condition,
+		isMet: func(now time.Time) (bool, error) {
+			hour := now.UTC().Hour()
+			if start < end {
+				return hour >= start && hour < end, nil
+			}
+			// wraps around midnight
+			return hour >= start || hour < end, nil
+		},
+	}, nil
+}
+
+func parseFeedCondition(condition string) (filterCondition, error) {
+	// find the parenthesis that closes "feed(" since the feed URL can contain parentheses of its own
+	rest := strings.TrimPrefix(condition, "feed(")
+	closeIdx := -1
+	depth := 1
+	for i := 0; i < len(rest) && closeIdx < 0; i++ {
+		if rest[i] == '(' {
+			depth++
+		} else if rest[i] == ')' {
+			depth--
+			if depth == 0 {
+				closeIdx = i
+			}
+		}
+	}
+	if closeIdx < 0 {
+		return filterCondition{}, fmt.Errorf("unbalanced parentheses")
+	}
+	feedSpec, comparison := rest[:closeIdx], rest[closeIdx+1:]
+
+	feedSpecParts := strings.SplitN(feedSpec, "/", 2)
+	if len(feedSpecParts) != 2 {
+		return filterCondition{}, fmt.Errorf("feed needs to be formatted as <feedType>/<feedURL>: %s", feedSpec)
+	}
+	pf, e := MakePriceFeed(feedSpecParts[0], feedSpecParts[1])
+	if e != nil {
+		return filterCondition{}, fmt.Errorf("could not make price feed: %s", e)
+	}
+
+	var compare func(price float64, threshold float64) bool
+	var thresholdString string
+	switch {
+	case strings.HasPrefix(comparison, "<="):
+		compare, thresholdString = func(p float64, t float64) bool { return p <= t }, comparison[2:]
+	case strings.HasPrefix(comparison, ">="):
+		compare, thresholdString = func(p float64, t float64) bool { return p >= t }, comparison[2:]
+	case strings.HasPrefix(comparison, "<"):
+		compare, thresholdString = func(p float64, t float64) bool { return p < t }, comparison[1:]
+	case strings.HasPrefix(comparison, ">"):
+		compare, thresholdString = func(p float64, t float64) bool { return p > t }, comparison[1:]
+	default:
+		return filterCondition{}, fmt.Errorf("invalid comparison '%s', needs to start with one of <, <=, >, >=", comparison)
+	}
+	threshold, e := strconv.ParseFloat(thresholdString, 64)
+	if e != nil {
+		return filterCondition{}, fmt.Errorf("could not parse threshold '%s' as a float value: %s", thresholdString, e)
+	}
+
+	return filterCondition{
+		description: condition,
+		isMet: func(now time.Time) (bool, error) {
+			price, e := pf.GetPrice()
+			if e != nil {
+				return false, fmt.Errorf("could not get price from feed: %s", e)
+			}
+			return compare(price, threshold), nil
+		},
+	}, nil
+}
@@ -0,0 +1,252 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/support/utils"
+)
+
+// dropAllFilter is a SubmitFilter that drops all ops, used to check which ops are passed to an inner filter
+type dropAllFilter struct {
+	numOpsSeen int
+}
+
+func (f *dropAllFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer) ([]txnbuild.Operation, error) {
+	f.numOpsSeen += len(ops)
+	return []txnbuild.Operation{}, nil
+}
+
+func TestSplitFirstTopLevel(t *testing.T) {
+	testCases := []struct {
+		s         string
+		sep       string
+		wantFirst string
+		wantRest  string
+		wantErr   bool
+	}{
+		{s: "days=mon/volume/daily", sep: "/", wantFirst: "days=mon", wantRest: "volume/daily"},
+		{s: "feed(exchange/kraken/XXLM/ZUSD/mid)>0.1/price/min/0.1", sep: "/", wantFirst: "feed(exchange/kraken/XXLM/ZUSD/mid)>0.1", wantRest: "price/min/0.1"},
+		{s: "feed(sdex/XLM:/USD:GABC)>0.1:days=mon", sep: ":", wantFirst: "feed(sdex/XLM:/USD:GABC)>0.1", wantRest: "days=mon"},
+		{s: "days=mon", sep: ":", wantFirst: "days=mon", wantRest: ""},
+		{s: "feed(fixed/1.0", sep: "/", wantErr: true},
+		{s: "feed)fixed/1.0(", sep: "/", wantErr: true},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.s, func(t *testing.T) {
+			first, rest, e := splitFirstTopLevel(kase.s, kase.sep)
+			if kase.wantErr {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, kase.wantFirst, first)
+			assert.Equal(t, kase.wantRest, rest)
+		})
+	}
+}
+
+func TestParseFilterCondition(t *testing.T) {
+	// 2020-05-01 is a Friday
+	friday := time.Date(2020, 5, 1, 23, 30, 0, 0, time.UTC)
+	saturday := time.Date(2020, 5, 2, 12, 0, 0, 0, time.UTC)
+	testCases := []struct {
+		condition string
+		now       time.Time
+		wantMet   bool
+		wantErr   bool
+	}{
+		{condition: "days=weekdays", now: friday, wantMet: true},
+		{condition: "days=weekdays", now: saturday, wantMet: false},
+		{condition: "days=sat,sun", now: saturday, wantMet: true},
+		{condition: "days=weekends", now: friday, wantMet: false},
+		{condition: "hours=9-17", now: saturday, wantMet: true},
+		{condition: "hours=9-17", now: friday, wantMet: false},
+		{condition: "hours=22-6", now: friday, wantMet: true},
+		{condition: "hours=22-6", now: saturday, wantMet: false},
+		{condition: "feed(fixed/1.5)>1.0", now: friday, wantMet: true},
+		{condition: "feed(fixed/1.5)<=1.0", now: friday, wantMet: false},
+		{condition: "feed(function/max(fixed/0.5,fixed/2.0))>=2.0", now: friday, wantMet: true},
+		{condition: "days=someday", wantErr: true},
+		{condition: "hours=9", wantErr: true},
+		{condition: "hours=9-9", wantErr: true},
+		{condition: "hours=25-3", wantErr: true},
+		{condition: "feed(fixed/1.5)=1.0", wantErr: true},
+		{condition: "feed(fixed/1.5>1.0", wantErr: true},
+		{condition: "weekdays", wantErr: true},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.condition, func(t *testing.T) {
+			c, e := parseFilterCondition(kase.condition)
+			if kase.wantErr {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			isMet, e := c.isMet(kase.now)
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, kase.wantMet, isMet)
+		})
+	}
+}
+
+func TestSideFilter(t *testing.T) {
+	base := utils.Asset2Asset2(testBaseAsset)
+	quote := utils.Asset2Asset2(testQuoteAsset)
+	ops := []txnbuild.Operation{
+		makeBuyOpAmtPrice(10.0, 1.0),
+		makeBuyOpAmtPrice(10.0, 0.9),
+		makeSellOpAmtPrice(10.0, 1.1),
+	}
+
+	testCases := []struct {
+		isSell      bool
+		wantOps     []txnbuild.Operation
+		wantNumSeen int
+	}{
+		{
+			isSell:      true,
+			wantOps:     ops[:2],
+			wantNumSeen: 1,
+		}, {
+			isSell:      false,
+			wantOps:     ops[2:],
+			wantNumSeen: 2,
+		},
+	}
+
+	for _, kase := range testCases {
+		inner := &dropAllFilter{}
+		f := &sideFilter{
+			baseAsset:  base,
+			quoteAsset: quote,
+			isSell:     kase.isSell,
+			inner:      inner,
+		}
+		actual, e := f.Apply(ops, []hProtocol.Offer{}, []hProtocol.Offer{})
+		if !assert.NoError(t, e) {
+			return
+		}
+		assert.Equal(t, kase.wantOps, actual)
+		assert.Equal(t, kase.wantNumSeen, inner.numOpsSeen)
+	}
+}
+
+// scriptedFilter is a SubmitFilter that always returns the same ops, used to check where the ops of an inner filter are placed
+type scriptedFilter struct {
+	ops []txnbuild.Operation
+}
+
+func (f *scriptedFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer) ([]txnbuild.Operation, error) {
+	return f.ops, nil
+}
+
+func TestSideFilterKeepsPositions(t *testing.T) {
+	base := utils.Asset2Asset2(testBaseAsset)
+	quote := utils.Asset2Asset2(testQuoteAsset)
+	deleteSell := makeSellOpAmtPrice(0.0, 1.2)
+	deleteBuy := makeBuyOpAmtPrice(0.0, 0.8)
+	sell := makeSellOpAmtPrice(10.0, 1.1)
+	buy := makeBuyOpAmtPrice(10.0, 0.9)
+	// earlier filters put the delete ops of both sides at the front
+	ops := []txnbuild.Operation{deleteSell, deleteBuy, sell, buy}
+	filteredSell := makeSellOpAmtPrice(5.0, 1.1)
+	extraSell := makeSellOpAmtPrice(5.0, 1.15)
+
+	testCases := []struct {
+		name     string
+		isSell   bool
+		filtered []txnbuild.Operation
+		wantOps  []txnbuild.Operation
+	}{
+		{
+			name:     "same number of ops",
+			isSell:   true,
+			filtered: []txnbuild.Operation{deleteSell, filteredSell},
+			wantOps:  []txnbuild.Operation{deleteSell, deleteBuy, filteredSell, buy},
+		}, {
+			name:     "fewer ops",
+			isSell:   true,
+			filtered: []txnbuild.Operation{filteredSell},
+			wantOps:  []txnbuild.Operation{filteredSell, deleteBuy, buy},
+		}, {
+			name:     "more ops",
+			isSell:   true,
+			filtered: []txnbuild.Operation{deleteSell, filteredSell, extraSell},
+			wantOps:  []txnbuild.Operation{deleteSell, deleteBuy, filteredSell, extraSell, buy},
+		}, {
+			name:     "buy side",
+			isSell:   false,
+			filtered: []txnbuild.Operation{buy},
+			wantOps:  []txnbuild.Operation{deleteSell, buy, sell},
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.name, func(t *testing.T) {
+			f := &sideFilter{
+				baseAsset:  base,
+				quoteAsset: quote,
+				isSell:     kase.isSell,
+				inner:      &scriptedFilter{ops: kase.filtered},
+			}
+			actual, e := f.Apply(ops, []hProtocol.Offer{}, []hProtocol.Offer{})
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, kase.wantOps, actual)
+		})
+	}
+}
+
+func TestMakeCompositeFilter(t *testing.T) {
+	factory := &FilterFactory{
+		BaseAsset:  utils.Asset2Asset2(testBaseAsset),
+		QuoteAsset: utils.Asset2Asset2(testQuoteAsset),
+		FilterGroups: map[string][]string{
+			"limits":    {"price/min/0.1", "when/days=weekdays/side/sell/price/max/2.0"},
+			"nested":    {"group/limits"},
+			"recursive": {"price/min/0.1", "group/recursive"},
+		},
+	}
+
+	testCases := []struct {
+		configInput string
+		wantErr     bool
+	}{
+		{configInput: "side/sell/price/min/0.1"},
+		{configInput: "when/days=weekdays:hours=9-17/price/max/2.0"},
+		{configInput: "when/feed(fixed/1.0)>0.5/side/buy/priceFeed/outside-exclude/fixed/1.0"},
+		{configInput: "group/limits"},
+		{configInput: "group/nested"},
+		{configInput: "side/both/price/min/0.1", wantErr: true},
+		{configInput: "side/sell", wantErr: true},
+		{configInput: "when/days=weekdays", wantErr: true},
+		{configInput: "when/days=weekdays/unknown/filter", wantErr: true},
+		{configInput: "group/missing", wantErr: true},
+		{configInput: "group/recursive", wantErr: true},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.configInput, func(t *testing.T) {
+			_, e := factory.MakeFilter(kase.configInput)
+			if kase.wantErr {
+				assert.Error(t, e)
+			} else {
+				assert.NoError(t, e)
+			}
+		})
+	}
+}
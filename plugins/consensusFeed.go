@@ -0,0 +1,174 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"time"
+
+	"github.com/stellar/kelp/api"
+)
+
+const defaultConsensusMaxDeviation = 0.02
+const defaultConsensusTimeout = 5 * time.Second
+
+// consensusFeed queries all of its feeds concurrently and returns the median of the responses that agree with each other
+type consensusFeed struct {
+	feeds        []api.PriceFeed
+	quorum       int
+	maxDeviation float64
+	timeout      time.Duration
+	maxAge       time.Duration
+	now          func() time.Time
+}
+
+// ensure that it implements PriceFeed and compositePriceFeed
+var _ api.PriceFeed = &consensusFeed{}
+var _ compositePriceFeed = &consensusFeed{}
+
+// consensusResponse is the response of a single feed, index is the position of the feed in the consensus feed
+type consensusResponse struct {
+	index int
+	price float64
+	e     error
+}
+
+// makeConsensusFeed makes a consensus feed from a URL formatted like so:
+// quorum=2,max_deviation=0.01,timeout_millis=3000,max_age_seconds=3600|feed_type/feed_url|feed_type/feed_url[|...]
+// all the options are optional, quorum defaults to a majority of the feeds
+func makeConsensusFeed(url string, factory priceFeedFactory) (*consensusFeed, error) {
+	options, feeds, e := parseCompositeFeedURL(url, []string{"quorum", "max_deviation", "timeout_millis", "max_age_seconds"}, factory)
+	if e != nil {
+		return nil, fmt.Errorf("unable to parse consensus feed URL: %s", e)
+	}
+
+	quorum := len(feeds)/2 + 1
+	if v, ok := options["quorum"]; ok {
+		quorum = int(v)
+	}
+	maxDeviation := defaultConsensusMaxDeviation
+	if v, ok := options["max_deviation"]; ok {
+		maxDeviation = v
+	}
+	timeout := defaultConsensusTimeout
+	if v, ok := options["timeout_millis"]; ok {
+		timeout = time.Duration(v) * time.Millisecond
+	}
+	maxAge := time.Duration(0)
+	if v, ok := options["max_age_seconds"]; ok {
+		maxAge = time.Duration(v) * time.Second
+	}
+
+	return newConsensusFeed(feeds, quorum, maxDeviation, timeout, maxAge)
+}
+
+func newConsensusFeed(feeds []api.PriceFeed, quorum int, maxDeviation float64, timeout time.Duration, maxAge time.Duration) (*consensusFeed, error) {
+	if quorum < 1 || quorum > len(feeds) {
+		return nil, fmt.Errorf("quorum (%d) needs to be between 1 and the number of feeds (%d)", quorum, len(feeds))
+	}
+	if maxDeviation <= 0.0 {
+		return nil, fmt.Errorf("max_deviation (%.4f) needs to be > 0.0", maxDeviation)
+	}
+	if timeout <= 0 {
+		return nil, fmt.Errorf("timeout (%s) needs to be > 0", timeout)
+	}
+
+	return &consensusFeed{
+		feeds:        feeds,
+		quorum:       quorum,
+		maxDeviation: maxDeviation,
+		timeout:      timeout,
+		maxAge:       maxAge,
+		now:          time.Now,
+	}, nil
+}
+
+// subFeeds impl
+func (f *consensusFeed) subFeeds() []api.PriceFeed {
+	return f.feeds
+}
+
+// GetPrice impl
+func (f *consensusFeed) GetPrice() (float64, error) {
+	// buffered so feeds that respond after the timeout do not block
+	responses := make(chan consensusResponse, len(f.feeds))
+	for i, feed := range f.feeds {
+		go func(i int, feed api.PriceFeed) {
+			price, e := f.fetch(feed)
+			responses <- consensusResponse{index: i, price: price, e: e}
+		}(i, feed)
+	}
+
+	prices := []float64{}
+	indices := []int{}
+	timer := time.NewTimer(f.timeout)
+	defer timer.Stop()
+	for received := 0; received < len(f.feeds); received++ {
+		select {
+		case r := <-responses:
+			if r.e != nil {
+				log.Printf("consensus feed: discarding feed at index %d: %s\n", r.index, r.e)
+				continue
+			}
+			if r.price <= 0.0 {
+				log.Printf("consensus feed: discarding feed at index %d because price was <= 0.0 (%.10f)\n", r.index, r.price)
+				continue
+			}
+			prices = append(prices, r.price)
+			indices = append(indices, r.index)
+		case <-timer.C:
+			log.Printf("consensus feed: timed out after %s with %d of %d feeds responding, discarding the rest\n", f.timeout, received, len(f.feeds))
+			received = len(f.feeds)
+		}
+	}
+
+	if len(prices) < f.quorum {
+		return 0.0, fmt.Errorf("consensus feed did not reach quorum, only %d of %d feeds responded with a valid price but quorum is %d", len(prices), len(f.feeds), f.quorum)
+	}
+
+	m := medianOf(prices)
+	agreeing := []float64{}
+	for i, p := range prices {
+		deviation := math.Abs(p-m) / m
+		if deviation > f.maxDeviation {
+			log.Printf("consensus feed: discarding outlier from feed at index %d, price %.10f deviates %.4f from the median %.10f (max_deviation=%.4f)\n", indices[i], p, deviation, m, f.maxDeviation)
+			continue
+		}
+		agreeing = append(agreeing, p)
+	}
+
+	if len(agreeing) < f.quorum {
+		return 0.0, fmt.Errorf("consensus feed did not reach quorum, only %d of %d feeds agreed within max_deviation=%.4f of the median %.10f but quorum is %d", len(agreeing), len(f.feeds), f.maxDeviation, m, f.quorum)
+	}
+	return medianOf(agreeing), nil
+}
+
+// fetch gets the price from a single feed, discarding it if the feed reports a price older than the max age
+func (f *consensusFeed) fetch(feed api.PriceFeed) (float64, error) {
+	tsFeed, ok := feed.(api.TimestampedPriceFeed)
+	if !ok || f.maxAge == 0 {
+		return feed.GetPrice()
+	}
+
+	price, ts, e := tsFeed.GetPriceWithTimestamp()
+	if e != nil {
+		return 0.0, e
+	}
+	age := f.now().Sub(ts)
+	if age > f.maxAge {
+		return 0.0, fmt.Errorf("stale price, last updated %s ago which is older than the max age %s", age, f.maxAge)
+	}
+	return price, nil
+}
+
+// medianOf returns the median of the non-empty list of values without modifying it
+func medianOf(values []float64) float64 {
+	sorted := append([]float64{}, values...)
+	sort.Float64s(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
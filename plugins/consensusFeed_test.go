@@ -0,0 +1,158 @@
+package plugins
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stellar/kelp/api"
+	"github.com/stretchr/testify/assert"
+)
+
+// testTimestampedFeed is a feed that reports a fixed price that was last updated at a fixed time
+type testTimestampedFeed struct {
+	price       float64
+	lastUpdated time.Time
+}
+
+func (f *testTimestampedFeed) GetPrice() (float64, error) {
+	return f.price, nil
+}
+
+func (f *testTimestampedFeed) GetPriceWithTimestamp() (float64, time.Time, error) {
+	return f.price, f.lastUpdated, nil
+}
+
+func makeTestPriceFeed(price float64, delay time.Duration, e error) api.PriceFeed {
+	return makeFunctionFeed(func() (float64, error) {
+		time.Sleep(delay)
+		return price, e
+	})
+}
+
+func TestConsensusFeed(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	testCases := []struct {
+		name      string
+		feeds     []api.PriceFeed
+		quorum    int
+		wantPrice float64
+		wantError bool
+	}{
+		{
+			name: "all agree",
+			feeds: []api.PriceFeed{
+				makeTestPriceFeed(1.00, 0, nil),
+				makeTestPriceFeed(1.01, 0, nil),
+				makeTestPriceFeed(0.99, 0, nil),
+			},
+			quorum:    2,
+			wantPrice: 1.00,
+		}, {
+			name: "outlier discarded",
+			feeds: []api.PriceFeed{
+				makeTestPriceFeed(1.00, 0, nil),
+				makeTestPriceFeed(1.02, 0, nil),
+				makeTestPriceFeed(5.00, 0, nil),
+			},
+			quorum:    2,
+			wantPrice: 1.01,
+		}, {
+			name: "error and timeout discarded",
+			feeds: []api.PriceFeed{
+				makeTestPriceFeed(1.00, 0, nil),
+				makeTestPriceFeed(1.02, 0, nil),
+				makeTestPriceFeed(1.01, 0, fmt.Errorf("provider down")),
+				makeTestPriceFeed(1.01, time.Second, nil),
+			},
+			quorum:    2,
+			wantPrice: 1.01,
+		}, {
+			name: "stale feed discarded",
+			feeds: []api.PriceFeed{
+				makeTestPriceFeed(1.00, 0, nil),
+				&testTimestampedFeed{price: 1.02, lastUpdated: now.Add(-time.Minute)},
+				&testTimestampedFeed{price: 1.50, lastUpdated: now.Add(-2 * time.Hour)},
+			},
+			quorum:    2,
+			wantPrice: 1.01,
+		}, {
+			name: "no quorum after timeout",
+			feeds: []api.PriceFeed{
+				makeTestPriceFeed(1.00, 0, nil),
+				makeTestPriceFeed(1.00, time.Second, nil),
+				makeTestPriceFeed(1.00, time.Second, nil),
+			},
+			quorum:    2,
+			wantError: true,
+		}, {
+			name: "no quorum after outliers",
+			feeds: []api.PriceFeed{
+				makeTestPriceFeed(1.00, 0, nil),
+				makeTestPriceFeed(2.00, 0, nil),
+				makeTestPriceFeed(3.00, 0, nil),
+			},
+			quorum:    2,
+			wantError: true,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.name, func(t *testing.T) {
+			f, e := newConsensusFeed(kase.feeds, kase.quorum, 0.05, 100*time.Millisecond, time.Hour)
+			if !assert.NoError(t, e) {
+				return
+			}
+			f.now = func() time.Time { return now }
+
+			price, e := f.GetPrice()
+			if kase.wantError {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.InDelta(t, kase.wantPrice, price, 0.0000001)
+		})
+	}
+}
+
+func TestMakeConsensusFeed(t *testing.T) {
+	testCases := []struct {
+		url        string
+		wantQuorum int
+		wantError  bool
+	}{
+		{
+			url:        "|fixed/1.0|fixed/1.0|fixed/1.0",
+			wantQuorum: 2,
+		}, {
+			url:        "quorum=3,max_deviation=0.01,timeout_millis=2000|fixed/1.0|fixed/1.0|fixed/1.0",
+			wantQuorum: 3,
+		}, {
+			url:       "quorum=4|fixed/1.0|fixed/1.0|fixed/1.0",
+			wantError: true,
+		}, {
+			url:       "unknown=1|fixed/1.0",
+			wantError: true,
+		}, {
+			url:       "fixed/1.0",
+			wantError: true,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.url, func(t *testing.T) {
+			f, e := makeConsensusFeed(kase.url, priceFeedFactory{})
+			if kase.wantError {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, kase.wantQuorum, f.quorum)
+		})
+	}
+}
@@ -8,32 +8,44 @@ import (
 	"github.com/stellar/kelp/model"
 )
 
-// encapsulates a priceFeed from a tickerAPI
+// encapsulates a priceFeed from a tickerAPI, or from the orderbook when the modifier is an orderbook price mode
 type exchangeFeed struct {
-	name      string
-	tickerAPI *api.TickerAPI
-	pairs     []model.TradingPair
-	modifier  string
+	name             string
+	tickerAPI        *api.TickerAPI
+	orderbookFetcher api.OrderbookFetcher
+	pairs            []model.TradingPair
+	modifier         string
+	orderbookMode    *orderbookPriceMode
 }
 
 // ensure that it implements PriceFeed
 var _ api.PriceFeed = &exchangeFeed{}
 
-func newExchangeFeed(name string, tickerAPI *api.TickerAPI, pair *model.TradingPair, modifier string) (*exchangeFeed, error) {
-	if modifier != "mid" && modifier != "ask" && modifier != "bid" && modifier != "last" {
+func newExchangeFeed(name string, tickerAPI *api.TickerAPI, orderbookFetcher api.OrderbookFetcher, pair *model.TradingPair, modifier string) (*exchangeFeed, error) {
+	orderbookMode, e := parseOrderbookPriceMode(modifier)
+	if e != nil {
+		return nil, fmt.Errorf("invalid modifier on exchange type URL: %s", e)
+	}
+	if orderbookMode == nil && modifier != "mid" && modifier != "ask" && modifier != "bid" && modifier != "last" {
 		return nil, fmt.Errorf("unsupported modifier '%s' on exchange type URL", modifier)
 	}
 
 	return &exchangeFeed{
-		name:      name,
-		tickerAPI: tickerAPI,
-		pairs:     []model.TradingPair{*pair},
-		modifier:  modifier,
+		name:             name,
+		tickerAPI:        tickerAPI,
+		orderbookFetcher: orderbookFetcher,
+		pairs:            []model.TradingPair{*pair},
+		modifier:         modifier,
+		orderbookMode:    orderbookMode,
 	}, nil
 }
 
 // GetPrice impl
 func (f *exchangeFeed) GetPrice() (float64, error) {
+	if f.orderbookMode != nil {
+		return f.getOrderbookPrice()
+	}
+
 	tickerAPI := *f.tickerAPI
 	m, e := tickerAPI.GetTickerPrice(f.pairs)
 	if e != nil {
@@ -69,3 +81,18 @@ func (f *exchangeFeed) GetPrice() (float64, error) {
 	)
 	return price.AsFloat(), nil
 }
+
+func (f *exchangeFeed) getOrderbookPrice() (float64, error) {
+	ob, e := f.orderbookFetcher.GetOrderBook(&f.pairs[0], orderbookPriceDepth)
+	if e != nil {
+		return 0, fmt.Errorf("error while getting orderbook from exchange feed: %s", e)
+	}
+
+	price, e := f.orderbookMode.price(ob)
+	if e != nil {
+		return 0, fmt.Errorf("error while getting price from exchange feed (%s): %s", f.name, e)
+	}
+
+	log.Printf("(modifier: %s) price from exchange feed (%s) using %d bids and %d asks: price=%.10f", f.modifier, f.name, len(ob.Bids()), len(ob.Asks()), price)
+	return price, nil
+}
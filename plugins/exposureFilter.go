@@ -0,0 +1,233 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/api"
+	"github.com/stellar/kelp/support/utils"
+)
+
+// ExposureFilterConfig limits the exposure of the account to the base asset, all values are in the reference currency of the feed
+type ExposureFilterConfig struct {
+	// MinInventoryNotional is the lowest value the base inventory can drop to if all resting sell offers are taken
+	MinInventoryNotional *float64
+	// MaxInventoryNotional is the highest value the base inventory can rise to if all resting buy offers are taken
+	MaxInventoryNotional *float64
+	// MaxRestingNotionalPerSide is the highest value of the base asset that can be resting on each side of the book
+	MaxRestingNotionalPerSide *float64
+	mode                      volumeFilterMode
+	feed                      api.PriceFeed // price of the base asset in the reference currency
+}
+
+// exposureState is the inventory of the base asset that is used to compute the remaining capacity on each side, in base units
+type exposureState struct {
+	balance         float64 // current balance of the base asset
+	externalSelling float64 // selling liabilities from offers on other trading pairs
+	externalBuying  float64 // buying liabilities from offers on other trading pairs
+	restingSelling  float64 // accumulates the amounts of sell ops and offers kept so far
+	restingBuying   float64 // accumulates the amounts of buy ops and offers kept so far
+	notionalPrice   float64 // price of the base asset in the reference currency
+}
+
+type exposureFilter struct {
+	name        string
+	configValue string
+	baseAsset   hProtocol.Asset
+	quoteAsset  hProtocol.Asset
+	ieif        *IEIF
+	config      *ExposureFilterConfig
+}
+
+// makeFilterExposure makes a submit filter that limits the net position of the account and the value of resting offers on each side
+func makeFilterExposure(
+	configValue string,
+	baseAsset hProtocol.Asset,
+	quoteAsset hProtocol.Asset,
+	ieif *IEIF,
+	config *ExposureFilterConfig,
+) (SubmitFilter, error) {
+	if ieif == nil {
+		return nil, fmt.Errorf("the exposure filter needs an IEIF to fetch balances and liabilities")
+	}
+	if config.feed == nil {
+		return nil, fmt.Errorf("the exposure filter needs a price feed to value the base asset")
+	}
+	if e := config.Validate(); e != nil {
+		return nil, fmt.Errorf("invalid exposure filter config: %s", e)
+	}
+
+	return &exposureFilter{
+		name:        "exposureFilter",
+		configValue: configValue,
+		baseAsset:   baseAsset,
+		quoteAsset:  quoteAsset,
+		ieif:        ieif,
+		config:      config,
+	}, nil
+}
+
+var _ SubmitFilter = &exposureFilter{}
+
+// Validate ensures validity
+func (c *ExposureFilterConfig) Validate() error {
+	if c.MinInventoryNotional == nil && c.MaxInventoryNotional == nil && c.MaxRestingNotionalPerSide == nil {
+		return fmt.Errorf("at least one of the limits needs to be set")
+	}
+	if c.MinInventoryNotional != nil && c.MaxInventoryNotional != nil && *c.MinInventoryNotional > *c.MaxInventoryNotional {
+		return fmt.Errorf("min inventory (%.8f) cannot be greater than max inventory (%.8f)", *c.MinInventoryNotional, *c.MaxInventoryNotional)
+	}
+	if c.MaxRestingNotionalPerSide != nil && *c.MaxRestingNotionalPerSide < 0.0 {
+		return fmt.Errorf("max resting value per side (%.8f) cannot be negative", *c.MaxRestingNotionalPerSide)
+	}
+	if _, e := parseVolumeFilterMode(string(c.mode)); e != nil {
+		return fmt.Errorf("could not parse mode: %s", e)
+	}
+	return nil
+}
+
+// String is the stringer method
+func (c *ExposureFilterConfig) String() string {
+	return fmt.Sprintf("ExposureFilterConfig[MinInventoryNotional=%s, MaxInventoryNotional=%s, MaxRestingNotionalPerSide=%s, mode=%s]",
+		utils.CheckedFloatPtr(c.MinInventoryNotional), utils.CheckedFloatPtr(c.MaxInventoryNotional), utils.CheckedFloatPtr(c.MaxRestingNotionalPerSide), c.mode)
+}
+
+func (f *exposureFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer) ([]txnbuild.Operation, error) {
+	notionalPrice, e := f.config.feed.GetPrice()
+	if e != nil {
+		return nil, fmt.Errorf("could not fetch the price of the base asset from the feed: %s", e)
+	}
+	if notionalPrice <= 0.0 {
+		return nil, fmt.Errorf("price of the base asset from the feed needs to be > 0.0, was %.10f", notionalPrice)
+	}
+
+	balance, e := f.ieif.GetAssetBalance(f.baseAsset)
+	if e != nil {
+		return nil, fmt.Errorf("could not fetch balance of the base asset: %s", e)
+	}
+	// offers on this trading pair are accumulated by the filter function since they are all passed through it
+	externalLiabilities, e := f.ieif.ExternalLiabilities(f.baseAsset, f.quoteAsset)
+	if e != nil {
+		return nil, fmt.Errorf("could not fetch liabilities of the base asset on other trading pairs: %s", e)
+	}
+
+	state := &exposureState{
+		balance:         balance.Balance,
+		externalSelling: externalLiabilities.Selling,
+		externalBuying:  externalLiabilities.Buying,
+		notionalPrice:   notionalPrice,
+	}
+	log.Printf("exposureFilter: baseBalance=%.8f, externalSellingLiabilities=%.8f, externalBuyingLiabilities=%.8f, notionalPrice=%.10f (%s)\n",
+		state.balance, state.externalSelling, state.externalBuying, state.notionalPrice, f.config)
+
+	innerFn := func(op *txnbuild.ManageSellOffer) (*txnbuild.ManageSellOffer, error) {
+		return exposureFilterFn(state, op, f.baseAsset, f.quoteAsset, f.config)
+	}
+	ops, e = filterOps(f.name, f.baseAsset, f.quoteAsset, sellingOffers, buyingOffers, ops, innerFn)
+	if e != nil {
+		return nil, fmt.Errorf("could not apply filter: %s", e)
+	}
+
+	log.Printf("exposureFilter: restingSelling=%.8f, restingBuying=%.8f, worstCaseInventory=[%.8f, %.8f] in base units\n",
+		state.restingSelling, state.restingBuying, state.minInventory(), state.maxInventory())
+	return ops, nil
+}
+
+// minInventory is the base inventory remaining if all resting sell offers are taken
+func (s *exposureState) minInventory() float64 {
+	return s.balance - s.externalSelling - s.restingSelling
+}
+
+// maxInventory is the base inventory held if all resting buy offers are taken
+func (s *exposureState) maxInventory() float64 {
+	return s.balance + s.externalBuying + s.restingBuying
+}
+
+// capacity returns the amount of base that can still be added to the side of the op in base units, and false if no limits apply to that side
+func (s *exposureState) capacity(isSell bool, config *ExposureFilterConfig) (float64, bool) {
+	limits := []float64{}
+	if isSell {
+		if config.MinInventoryNotional != nil {
+			limits = append(limits, s.minInventory()-*config.MinInventoryNotional/s.notionalPrice)
+		}
+		if config.MaxRestingNotionalPerSide != nil {
+			limits = append(limits, *config.MaxRestingNotionalPerSide/s.notionalPrice-s.restingSelling)
+		}
+	} else {
+		if config.MaxInventoryNotional != nil {
+			limits = append(limits, *config.MaxInventoryNotional/s.notionalPrice-s.maxInventory())
+		}
+		if config.MaxRestingNotionalPerSide != nil {
+			limits = append(limits, *config.MaxRestingNotionalPerSide/s.notionalPrice-s.restingBuying)
+		}
+	}
+
+	if len(limits) == 0 {
+		return 0.0, false
+	}
+	capacity := limits[0]
+	for _, l := range limits[1:] {
+		if l < capacity {
+			capacity = l
+		}
+	}
+	return capacity, true
+}
+
+func exposureFilterFn(state *exposureState, op *txnbuild.ManageSellOffer, baseAsset hProtocol.Asset, quoteAsset hProtocol.Asset, config *ExposureFilterConfig) (*txnbuild.ManageSellOffer, error) {
+	isSell, e := utils.IsSelling(baseAsset, quoteAsset, op.Selling, op.Buying)
+	if e != nil {
+		return nil, fmt.Errorf("error when running the isSelling check for offer '%+v': %s", *op, e)
+	}
+
+	offerPrice, e := strconv.ParseFloat(op.Price, 64)
+	if e != nil {
+		return nil, fmt.Errorf("could not convert price (%s) to float: %s", op.Price, e)
+	}
+	offerAmount, e := strconv.ParseFloat(op.Amount, 64)
+	if e != nil {
+		return nil, fmt.Errorf("could not convert amount (%s) to float: %s", op.Amount, e)
+	}
+	// a "buy" op has amount in quote units and an inverted price, so we convert the amount to base units like in volumeFilterFn
+	if !isSell {
+		offerAmount = offerAmount * offerPrice
+	}
+
+	addResting := func(amount float64) {
+		if isSell {
+			state.restingSelling += amount
+		} else {
+			state.restingBuying += amount
+		}
+	}
+
+	capacity, hasLimit := state.capacity(isSell, config)
+	if !hasLimit || offerAmount <= capacity {
+		addResting(offerAmount)
+		return op, nil
+	}
+
+	// for ignore type of filters we want to drop the operations when the limit is exceeded
+	if config.mode == volumeFilterModeIgnore || capacity <= 0.0 {
+		log.Printf("exposureFilter: dropping op (isSell=%v, baseAmount=%.8f) since the remaining capacity is %.8f\n", isSell, offerAmount, capacity)
+		return nil, nil
+	}
+
+	addResting(capacity)
+	newOpAmount := capacity
+	if !isSell {
+		// undo the conversion to base units done above
+		newOpAmount = newOpAmount / offerPrice
+	}
+	log.Printf("exposureFilter: reducing op (isSell=%v) from baseAmount=%.8f to baseAmount=%.8f\n", isSell, offerAmount, capacity)
+	op.Amount = fmt.Sprintf("%.7f", newOpAmount)
+	return op, nil
+}
+
+// String is the Stringer method
+func (f *exposureFilter) String() string {
+	return f.configValue
+}
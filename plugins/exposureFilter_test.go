@@ -0,0 +1,133 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/openlyinc/pointy"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/support/utils"
+)
+
+func TestExposureFilterFn(t *testing.T) {
+	// the base asset is valued at 2.0 units of the reference currency in all cases
+	testCases := []struct {
+		name            string
+		mode            volumeFilterMode
+		minInventory    *float64
+		maxInventory    *float64
+		maxResting      *float64
+		restingSelling  float64
+		restingBuying   float64
+		externalSelling float64
+		inputOp         *txnbuild.ManageSellOffer
+		wantOp          *txnbuild.ManageSellOffer
+		wantSelling     float64
+		wantBuying      float64
+	}{
+		{
+			name:         "sell within band",
+			mode:         volumeFilterModeExact,
+			minInventory: pointy.Float64(100.0),
+			inputOp:      makeSellOpAmtPrice(10.0, 3.0),
+			wantOp:       makeSellOpAmtPrice(10.0, 3.0),
+			wantSelling:  10.0,
+		}, {
+			// balance of 100 base can drop to 50 base (100.0 / 2.0), 30 are already resting so 20 are left
+			name:           "sell exact below band",
+			mode:           volumeFilterModeExact,
+			minInventory:   pointy.Float64(100.0),
+			restingSelling: 30.0,
+			inputOp:        makeSellOpAmtPrice(40.0, 3.0),
+			wantOp:         makeSellOpAmtPrice(20.0, 3.0),
+			wantSelling:    50.0,
+		}, {
+			name:           "sell ignore below band",
+			mode:           volumeFilterModeIgnore,
+			minInventory:   pointy.Float64(100.0),
+			restingSelling: 30.0,
+			inputOp:        makeSellOpAmtPrice(40.0, 3.0),
+			wantOp:         nil,
+			wantSelling:    30.0,
+		}, {
+			// offers from other trading pairs count towards the inventory
+			name:            "sell exact with external liabilities",
+			mode:            volumeFilterModeExact,
+			minInventory:    pointy.Float64(100.0),
+			externalSelling: 45.0,
+			inputOp:         makeSellOpAmtPrice(40.0, 3.0),
+			wantOp:          makeSellOpAmtPrice(5.0, 3.0),
+			wantSelling:     5.0,
+		}, {
+			name:         "sell already outside band",
+			mode:         volumeFilterModeExact,
+			minInventory: pointy.Float64(300.0),
+			inputOp:      makeSellOpAmtPrice(1.0, 3.0),
+			wantOp:       nil,
+			wantSelling:  0.0,
+		}, {
+			// balance of 100 base can rise to 150 base (300.0 / 2.0)
+			name:         "buy exact above band",
+			mode:         volumeFilterModeExact,
+			maxInventory: pointy.Float64(300.0),
+			inputOp:      makeBuyOpAmtPrice(80.0, 1.0),
+			wantOp:       makeBuyOpAmtPrice(50.0, 1.0),
+			wantBuying:   50.0,
+		}, {
+			// max inventory only applies to buys
+			name:         "sell unaffected by max inventory",
+			mode:         volumeFilterModeExact,
+			maxInventory: pointy.Float64(300.0),
+			inputOp:      makeSellOpAmtPrice(80.0, 1.0),
+			wantOp:       makeSellOpAmtPrice(80.0, 1.0),
+			wantSelling:  80.0,
+		}, {
+			// 40.0 / 2.0 = 20 base per side
+			name:          "buy exact over max resting",
+			mode:          volumeFilterModeExact,
+			maxResting:    pointy.Float64(40.0),
+			restingBuying: 5.0,
+			inputOp:       makeBuyOpAmtPrice(30.0, 0.5),
+			wantOp:        makeBuyOpAmtPrice(15.0, 0.5),
+			wantBuying:    20.0,
+		}, {
+			// the tighter of the two limits is used
+			name:         "sell exact uses tighter limit",
+			mode:         volumeFilterModeExact,
+			minInventory: pointy.Float64(100.0),
+			maxResting:   pointy.Float64(40.0),
+			inputOp:      makeSellOpAmtPrice(40.0, 3.0),
+			wantOp:       makeSellOpAmtPrice(20.0, 3.0),
+			wantSelling:  20.0,
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			config := &ExposureFilterConfig{
+				MinInventoryNotional:      k.minInventory,
+				MaxInventoryNotional:      k.maxInventory,
+				MaxRestingNotionalPerSide: k.maxResting,
+				mode:                      k.mode,
+			}
+			state := &exposureState{
+				balance:         100.0,
+				externalSelling: k.externalSelling,
+				restingSelling:  k.restingSelling,
+				restingBuying:   k.restingBuying,
+				notionalPrice:   2.0,
+			}
+
+			base := utils.Asset2Asset2(testBaseAsset)
+			quote := utils.Asset2Asset2(testQuoteAsset)
+			actual, e := exposureFilterFn(state, k.inputOp, base, quote, config)
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, k.wantOp, actual)
+			assert.InDelta(t, k.wantSelling, state.restingSelling, 0.0000001)
+			assert.InDelta(t, k.wantBuying, state.restingBuying, 0.0000001)
+		})
+	}
+}
@@ -39,9 +39,10 @@ func (e ErrFiatAPI) Error() string {
 }
 
 type fiatAPIReturn struct {
-	Success bool
-	Quotes  map[string]float64
-	Error   ErrFiatAPI
+	Success   bool
+	Timestamp int64
+	Quotes    map[string]float64
+	Error     ErrFiatAPI
 }
 
 type fiatFeed struct {
@@ -51,6 +52,7 @@ type fiatFeed struct {
 
 // ensure that it implements PriceFeed
 var _ api.PriceFeed = &fiatFeed{}
+var _ api.TimestampedPriceFeed = &fiatFeed{}
 
 func newFiatFeed(url string) *fiatFeed {
 	m := new(fiatFeed)
@@ -62,22 +64,28 @@ func newFiatFeed(url string) *fiatFeed {
 
 // GetPrice impl
 func (f *fiatFeed) GetPrice() (float64, error) {
+	price, _, e := f.GetPriceWithTimestamp()
+	return price, e
+}
+
+// GetPriceWithTimestamp impl, the timestamp is when the provider last updated its quotes
+func (f *fiatFeed) GetPriceWithTimestamp() (float64, time.Time, error) {
 	var ret fiatAPIReturn
 	e := utils.GetJSON(f.client, f.url, &ret)
 	if e != nil {
-		return 0, fmt.Errorf("unable to get price from fiat feed: %s", e)
+		return 0, time.Time{}, fmt.Errorf("unable to get price from fiat feed: %s", e)
 	}
 
 	if !ret.Success {
-		return -1, errors.Wrap(ret.Error, "call to get price from fiat feed failed")
+		return -1, time.Time{}, errors.Wrap(ret.Error, "call to get price from fiat feed failed")
 	}
 
 	if len(ret.Quotes) != 1 {
-		return 0, fmt.Errorf("incorrect number of quotes returned (%d), was expecting only 1", len(ret.Quotes))
+		return 0, time.Time{}, fmt.Errorf("incorrect number of quotes returned (%d), was expecting only 1", len(ret.Quotes))
 	}
 
 	for _, price := range ret.Quotes {
-		return (1.0 / price), nil
+		return (1.0 / price), time.Unix(ret.Timestamp, 0), nil
 	}
-	return -1, fmt.Errorf("unexpected error, should not have reached here")
+	return -1, time.Time{}, fmt.Errorf("unexpected error, should not have reached here")
 }
@@ -19,6 +19,10 @@ import (
 
 const marketIdHashLength = 10
 
+// maxNotionalTradeAge is the age after which a trade is written without a notional value because the current price of the notional feed
+// would misstate the value of older trades, such as the ones backfilled from the last cursor on startup
+const maxNotionalTradeAge = 5 * time.Minute
+
 type tradingMarket struct {
 	ID           string
 	ExchangeName string
@@ -43,6 +47,7 @@ type FillDBWriter struct {
 	assetDisplayFn model.AssetDisplayFn
 	exchangeName   string
 	accountID      string
+	notionalFeed   api.PriceFeed // can be nil
 
 	// uninitialized
 	market *tradingMarket
@@ -75,13 +80,15 @@ func (m *tradingMarket) String() string {
 
 var _ api.FillHandler = &FillDBWriter{}
 
-// MakeFillDBWriter is a factory method
-func MakeFillDBWriter(db *sql.DB, assetDisplayFn model.AssetDisplayFn, exchangeName string, accountID string) api.FillHandler {
+// MakeFillDBWriter is a factory method, the notionalFeed is the price of the quote asset in the reference currency used for notional
+// volume caps and can be nil
+func MakeFillDBWriter(db *sql.DB, assetDisplayFn model.AssetDisplayFn, exchangeName string, accountID string, notionalFeed api.PriceFeed) api.FillHandler {
 	return &FillDBWriter{
 		db:             db,
 		assetDisplayFn: assetDisplayFn,
 		exchangeName:   exchangeName,
 		accountID:      accountID,
+		notionalFeed:   notionalFeed,
 	}
 }
 
@@ -201,6 +208,7 @@ func (f *FillDBWriter) HandleFill(trade model.Trade) error {
 		f.checkedFloat(trade.Fee),
 		f.accountID,
 		trade.OrderID,
+		f.notionalValueString(trade, time.Now()),
 	)
 	_, e = f.db.Exec(sqlInsert)
 	if e != nil {
@@ -217,6 +225,36 @@ func (f *FillDBWriter) HandleFill(trade model.Trade) error {
 	return nil
 }
 
+// notionalValueString converts the cost of the trade to the reference currency using the current price, the trade is written with
+// a NULL notional value when that is not possible or the trade is too old to be valued at the current price since we do not want to
+// lose the trade. Volume filters value trades without a notional value at the notional price when the filter is applied
+func (f *FillDBWriter) notionalValueString(trade model.Trade, now time.Time) string {
+	if f.notionalFeed == nil {
+		return "NULL"
+	}
+
+	txid := utils.CheckedString(trade.TransactionID)
+	if trade.Timestamp == nil {
+		log.Printf("trade (txid=%s) has no timestamp, writing it without a notional value\n", txid)
+		return "NULL"
+	}
+	age := now.Sub(time.Unix(0, trade.Timestamp.AsInt64()*int64(time.Millisecond)))
+	if age > maxNotionalTradeAge {
+		log.Printf("trade (txid=%s) is %s old which is older than %s, writing it without a notional value\n", txid, age, maxNotionalTradeAge)
+		return "NULL"
+	}
+	if trade.Cost == nil {
+		log.Printf("trade (txid=%s) has no cost, writing it without a notional value\n", txid)
+		return "NULL"
+	}
+	notionalPrice, e := f.notionalFeed.GetPrice()
+	if e != nil {
+		log.Printf("could not fetch notional price for trade (txid=%s), writing it without a notional value: %s\n", txid, e)
+		return "NULL"
+	}
+	return fmt.Sprintf("%.15f", trade.Cost.AsFloat()*notionalPrice)
+}
+
 func (f *FillDBWriter) checkedFloat(n *model.Number) interface{} {
 	if n == nil {
 		return nil
@@ -3,8 +3,11 @@ package plugins
 import (
 	"fmt"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/kelp/model"
 )
 
 func TestMarketID(t *testing.T) {
@@ -60,3 +63,56 @@ func TestMarketID(t *testing.T) {
 		})
 	}
 }
+
+func TestNotionalValueString(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	notionalFeed, e := newFixedFeed("2.0")
+	if !assert.NoError(t, e) {
+		return
+	}
+	makeTrade := func(age time.Duration, cost *model.Number) model.Trade {
+		return model.Trade{
+			Order: model.Order{Timestamp: model.MakeTimestampFromTime(now.Add(-age))},
+			Cost:  cost,
+		}
+	}
+
+	testCases := []struct {
+		name     string
+		withFeed bool
+		trade    model.Trade
+		want     string
+	}{
+		{
+			name:     "recent trade",
+			withFeed: true,
+			trade:    makeTrade(time.Minute, model.NumberFromFloat(10.0, 7)),
+			want:     "20.000000000000000",
+		}, {
+			name:     "backfilled trade",
+			withFeed: true,
+			trade:    makeTrade(time.Hour, model.NumberFromFloat(10.0, 7)),
+			want:     "NULL",
+		}, {
+			name:     "no cost",
+			withFeed: true,
+			trade:    makeTrade(time.Minute, nil),
+			want:     "NULL",
+		}, {
+			name:     "no notional feed",
+			withFeed: false,
+			trade:    makeTrade(time.Minute, model.NumberFromFloat(10.0, 7)),
+			want:     "NULL",
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			f := &FillDBWriter{}
+			if k.withFeed {
+				f.notionalFeed = notionalFeed
+			}
+			assert.Equal(t, k.want, f.notionalValueString(k.trade, now))
+		})
+	}
+}
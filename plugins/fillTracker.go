@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"runtime/debug"
@@ -13,11 +14,15 @@ import (
 	"github.com/stellar/kelp/model"
 )
 
+// fillStreamReconnectDelay is how long we wait before reconnecting to the fill stream after it disconnects
+const fillStreamReconnectDelay = 5 * time.Second
+
 // FillTracker tracks fills
 type FillTracker struct {
 	pair                             *model.TradingPair
 	threadTracker                    *multithreading.ThreadTracker
 	fillTrackable                    api.FillTrackable
+	fillStreamable                   api.FillStreamable // nil when fills are only polled
 	fillTrackerSleepMillis           uint32
 	fillTrackerDeleteCyclesThreshold int64
 	lastCursor                       interface{}
@@ -26,6 +31,9 @@ type FillTracker struct {
 	fillTrackerDeleteCycles int64
 	lockFill                *sync.Mutex
 	isRunningInBackground   bool
+	fillNotifications       chan bool
+	lockStream              *sync.Mutex
+	isStreamConnected       bool
 
 	// uninitialized
 	handlers []api.FillHandler
@@ -39,6 +47,7 @@ func MakeFillTracker(
 	pair *model.TradingPair,
 	threadTracker *multithreading.ThreadTracker,
 	fillTrackable api.FillTrackable,
+	fillStreamable api.FillStreamable,
 	fillTrackerSleepMillis uint32,
 	fillTrackerDeleteCyclesThreshold int64,
 	lastCursor interface{},
@@ -47,6 +56,7 @@ func MakeFillTracker(
 		pair:                             pair,
 		threadTracker:                    threadTracker,
 		fillTrackable:                    fillTrackable,
+		fillStreamable:                   fillStreamable,
 		fillTrackerSleepMillis:           fillTrackerSleepMillis,
 		fillTrackerDeleteCyclesThreshold: fillTrackerDeleteCyclesThreshold,
 		lastCursor:                       lastCursor,
@@ -54,6 +64,10 @@ func MakeFillTracker(
 		fillTrackerDeleteCycles: 0,
 		lockFill:                &sync.Mutex{},
 		isRunningInBackground:   false,
+		// buffer of 1 so notifications that arrive while an iteration is running are not lost
+		fillNotifications: make(chan bool, 1),
+		lockStream:        &sync.Mutex{},
+		isStreamConnected: false,
 	}
 }
 
@@ -91,6 +105,12 @@ func (f *FillTracker) TrackFills() error {
 		f.isRunningInBackground = false
 	}()
 
+	if f.fillStreamable != nil {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		go f.streamFills(ctx)
+	}
+
 	for {
 		_, e := f.FillTrackSingleIteration()
 		if e != nil {
@@ -101,7 +121,69 @@ func (f *FillTracker) TrackFills() error {
 			log.Printf("%s\n", eMsg)
 		}
 
+		f.waitForNextIteration()
+	}
+}
+
+// streamFills keeps a stream of fills open and notifies the fill tracking loop of new fills, reconnecting from the last seen cursor when the stream
+// disconnects. The fill tracking loop falls back to polling while the stream is disconnected
+func (f *FillTracker) streamFills(ctx context.Context) {
+	cursor := "now"
+	for {
+		log.Printf("streaming fills starting from cursor %s\n", cursor)
+		e := f.fillStreamable.StreamFills(ctx, cursor, func() {
+			f.setStreamConnected(true)
+		}, func(fillCursor string) {
+			cursor = fillCursor
+			f.notifyFill()
+		})
+		f.setStreamConnected(false)
+		if ctx.Err() != nil {
+			return
+		}
+		log.Printf("fill stream disconnected (error=%v), polling for fills every %d millis until the stream reconnects in %s\n", e, f.fillTrackerSleepMillis, fillStreamReconnectDelay)
+		// poll right away in case we missed any fills while the stream was going down
+		f.notifyFill()
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(fillStreamReconnectDelay):
+		}
+	}
+}
+
+func (f *FillTracker) notifyFill() {
+	select {
+	case f.fillNotifications <- true:
+	default:
+		// a notification is already pending, the next iteration will pick up this fill too
+	}
+}
+
+func (f *FillTracker) setStreamConnected(isConnected bool) {
+	f.lockStream.Lock()
+	defer f.lockStream.Unlock()
+	f.isStreamConnected = isConnected
+}
+
+func (f *FillTracker) streamConnected() bool {
+	f.lockStream.Lock()
+	defer f.lockStream.Unlock()
+	return f.isStreamConnected
+}
+
+// waitForNextIteration waits for a fill notification from the stream, or for the polling interval when no fill arrives in time. We always
+// poll at the interval even when the stream is connected so a failed iteration is retried without waiting for the next fill
+func (f *FillTracker) waitForNextIteration() {
+	if f.fillStreamable == nil {
 		f.sleep()
+		return
+	}
+
+	select {
+	case <-f.fillNotifications:
+	case <-time.After(time.Duration(f.fillTrackerSleepMillis) * time.Millisecond):
 	}
 }
 
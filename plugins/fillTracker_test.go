@@ -0,0 +1,117 @@
+package plugins
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/nikhilsaraf/go-tools/multithreading"
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/kelp/model"
+)
+
+// testFillStreamable connects, sends a single fill once released and then stays connected until the context is cancelled, or fails to connect
+// when disconnect is set
+type testFillStreamable struct {
+	disconnect bool
+	cursors    chan string
+	sendFill   chan bool
+}
+
+func (s *testFillStreamable) StreamFills(ctx context.Context, cursor string, onConnect func(), onFill func(cursor string)) error {
+	s.cursors <- cursor
+	if s.disconnect {
+		return fmt.Errorf("disconnected")
+	}
+
+	onConnect()
+	<-s.sendFill
+	onFill("123")
+	<-ctx.Done()
+	return nil
+}
+
+func TestFillTrackerStreamNotifiesFill(t *testing.T) {
+	streamable := &testFillStreamable{cursors: make(chan string, 10), sendFill: make(chan bool, 1)}
+	// use a long polling interval so the test only passes if the stream wakes up the fill tracker
+	f := MakeFillTracker(&model.TradingPair{}, multithreading.MakeThreadTracker(), nil, streamable, 3600000, 0, nil).(*FillTracker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.streamFills(ctx)
+	assert.Equal(t, "now", <-streamable.cursors)
+	// the stream is only marked as connected once it is established
+	for i := 0; i < 100 && !f.streamConnected(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, f.streamConnected())
+	streamable.sendFill <- true
+
+	done := make(chan bool)
+	go func() {
+		f.waitForNextIteration()
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "fill tracker was not woken up by the fill stream")
+	}
+}
+
+func TestFillTrackerStreamConnectedStillPolls(t *testing.T) {
+	// the stream connects but never sends a fill
+	streamable := &testFillStreamable{cursors: make(chan string, 10), sendFill: make(chan bool)}
+	f := MakeFillTracker(&model.TradingPair{}, multithreading.MakeThreadTracker(), nil, streamable, 10, 0, nil).(*FillTracker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.streamFills(ctx)
+	<-streamable.cursors
+	for i := 0; i < 100 && !f.streamConnected(); i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+	assert.True(t, f.streamConnected())
+
+	// the wait is bounded by the polling interval so a failed iteration is retried without a fill
+	done := make(chan bool)
+	go func() {
+		f.waitForNextIteration()
+		done <- true
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		assert.Fail(t, "fill tracker did not poll while the stream was connected")
+	}
+}
+
+func TestFillTrackerStreamDisconnectFallsBackToPolling(t *testing.T) {
+	streamable := &testFillStreamable{disconnect: true, cursors: make(chan string, 10)}
+	f := MakeFillTracker(&model.TradingPair{}, multithreading.MakeThreadTracker(), nil, streamable, 10, 0, nil).(*FillTracker)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go f.streamFills(ctx)
+
+	// the disconnect triggers an immediate poll and every wait after that is bounded by the polling interval
+	for i := 0; i < 3; i++ {
+		done := make(chan bool)
+		go func() {
+			f.waitForNextIteration()
+			done <- true
+		}()
+
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			assert.Fail(t, fmt.Sprintf("fill tracker did not fall back to polling on iteration %d", i))
+			return
+		}
+	}
+	assert.False(t, f.streamConnected())
+}
@@ -6,8 +6,10 @@ import (
 	"regexp"
 	"strconv"
 	"strings"
+	"time"
 
 	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/kelp/api"
 	"github.com/stellar/kelp/model"
 	"github.com/stellar/kelp/queries"
 )
@@ -26,6 +28,11 @@ var filterMap = map[string]func(f *FilterFactory, configInput string) (SubmitFil
 	"volume":    filterVolume,
 	"price":     filterPrice,
 	"priceFeed": filterPriceFeed,
+	"exposure":  filterExposure,
+	"selfTrade": filterSelfTrade,
+	"side":      filterSide,
+	"when":      filterWhen,
+	"group":     filterGroup,
 }
 
 // FilterFactory is a struct that handles creating all the filters
@@ -36,6 +43,21 @@ type FilterFactory struct {
 	BaseAsset      hProtocol.Asset
 	QuoteAsset     hProtocol.Asset
 	DB             *sql.DB
+	// NotionalFeed is the price of the quote asset in the reference currency used by notional volume caps, can be nil
+	NotionalFeed api.PriceFeed
+	// IEIF is used to fetch balances and liabilities by the exposure filter
+	IEIF *IEIF
+	// ExchangeShim is used to load the open offers of the account by the self-trade prevention filter
+	ExchangeShim api.ExchangeShim
+	// FilterGroups are named lists of filters that can be referenced with the "group" filter
+	FilterGroups map[string][]string
+	// StateDir is the directory where filters persist their state across restarts, state is not persisted when empty
+	StateDir string
+	// StateMaxAge is the max age of persisted state that is loaded on a restart, older state is discarded
+	StateMaxAge time.Duration
+
+	// uninitialized runtime vars
+	activeGroups map[string]bool // groups being made, used to catch groups that include themselves
 }
 
 // MakeFilter is the function that makes the required filters
@@ -59,6 +81,7 @@ func filterVolume(f *FilterFactory, configInput string) (SubmitFilter, error) {
 	if e != nil {
 		return nil, fmt.Errorf("could not make VolumeFilterConfig for configInput (%s): %s", configInput, e)
 	}
+	config.notionalFeed = f.NotionalFeed
 
 	return makeFilterVolume(
 		configInput,
@@ -87,14 +110,19 @@ func makeRawVolumeFilterConfig(
 		mode:                     mode,
 		additionalMarketIDs:      additionalMarketIDs,
 		optionalAccountIDs:       optionalAccountIDs,
+		window:                   makeDefaultVolumeFilterWindow(),
 	}
 }
 
 func makeVolumeFilterConfig(configInput string) (*VolumeFilterConfig, error) {
+	// the window in the second part can contain "/" when it has a timezone modifier (ex: tz=America/New_York) so we take the last 4 parts
+	// from the end and join everything in between back together, leaving us with the 6 parts of the filter
 	parts := strings.Split(configInput, "/")
-	if len(parts) != 6 {
+	if len(parts) < 6 {
 		return nil, fmt.Errorf("invalid input (%s), needs 6 parts separated by the delimiter (/)", configInput)
 	}
+	windowPart := strings.Join(parts[1:len(parts)-4], "/")
+	parts = append([]string{parts[0], windowPart}, parts[len(parts)-4:]...)
 
 	mode, e := parseVolumeFilterMode(parts[5])
 	if e != nil {
@@ -103,9 +131,11 @@ func makeVolumeFilterConfig(configInput string) (*VolumeFilterConfig, error) {
 	config := &VolumeFilterConfig{mode: mode}
 
 	limitWindowParts := strings.Split(parts[1], ":")
-	if limitWindowParts[0] != "daily" {
-		return nil, fmt.Errorf("invalid input (%s), the second part needs to equal or start with \"daily\"", configInput)
+	window, e := parseVolumeFilterWindow(limitWindowParts[0])
+	if e != nil {
+		return nil, fmt.Errorf("invalid input (%s), could not parse the window in the second part: %s", configInput, e)
 	}
+	config.window = window
 
 	action, e := queries.ParseDailyVolumeAction(parts[2])
 	if e != nil {
@@ -113,24 +143,23 @@ func makeVolumeFilterConfig(configInput string) (*VolumeFilterConfig, error) {
 	}
 	config.action = action
 
-	errInvalid := fmt.Errorf("invalid input (%s), the modifier for \"daily\" can be either \"market_ids\" or \"account_ids\" like so 'daily:market_ids=[4c19915f47,db4531d586]' or 'daily:account_ids=[account1,account2]' or 'daily:market_ids=[4c19915f47,db4531d586]:account_ids=[account1,account2]'", configInput)
-	if len(limitWindowParts) == 2 {
-		e = addModifierToConfig(config, limitWindowParts[1])
-		if e != nil {
-			return nil, fmt.Errorf("%s: could not addModifierToConfig for %s: %s", errInvalid, limitWindowParts[1], e)
-		}
-	} else if len(limitWindowParts) == 3 {
-		e = addModifierToConfig(config, limitWindowParts[1])
-		if e != nil {
-			return nil, fmt.Errorf("%s: could not addModifierToConfig for %s: %s", errInvalid, limitWindowParts[1], e)
+	errInvalid := fmt.Errorf("invalid input (%s), the modifiers for the window can be \"market_ids\", \"account_ids\" or \"tz\" like so 'daily:market_ids=[4c19915f47,db4531d586]' or 'daily:account_ids=[account1,account2]' or 'daily:market_ids=[4c19915f47,db4531d586]:account_ids=[account1,account2]:tz=America/New_York'", configInput)
+	if len(limitWindowParts) > 4 {
+		return nil, fmt.Errorf("%s: can have at most 3 modifiers", errInvalid)
+	}
+	for _, modifier := range limitWindowParts[1:] {
+		if strings.HasPrefix(modifier, volumeFilterTimezonePrefix) {
+			e = config.window.setTimezone(modifier)
+			if e != nil {
+				return nil, fmt.Errorf("%s: could not set timezone for %s: %s", errInvalid, modifier, e)
+			}
+			continue
 		}
 
-		e = addModifierToConfig(config, limitWindowParts[2])
+		e = addModifierToConfig(config, modifier)
 		if e != nil {
-			return nil, fmt.Errorf("%s: could not addModifierToConfig for %s: %s", errInvalid, limitWindowParts[2], e)
+			return nil, fmt.Errorf("%s: could not addModifierToConfig for %s: %s", errInvalid, modifier, e)
 		}
-	} else if len(limitWindowParts) != 1 {
-		return nil, fmt.Errorf("invalid input (%s), the second part needs to be \"daily\" and can have only one modifier \"market_ids\" like so 'daily:market_ids=[4c19915f47,db4531d586]'", configInput)
 	}
 
 	limit, e := strconv.ParseFloat(parts[4], 64)
@@ -141,8 +170,10 @@ func makeVolumeFilterConfig(configInput string) (*VolumeFilterConfig, error) {
 		config.BaseAssetCapInBaseUnits = &limit
 	} else if parts[3] == "quote" {
 		config.BaseAssetCapInQuoteUnits = &limit
+	} else if parts[3] == "notional" {
+		config.BaseAssetCapInNotionalUnits = &limit
 	} else {
-		return nil, fmt.Errorf("invalid input (%s), the third part needs to be \"base\" or \"quote\"", configInput)
+		return nil, fmt.Errorf("invalid input (%s), the third part needs to be \"base\", \"quote\" or \"notional\"", configInput)
 	}
 
 	if e = config.Validate(); e != nil {
@@ -246,6 +277,9 @@ func filterPrice(f *FilterFactory, configInput string) (SubmitFilter, error) {
 func filterPriceFeed(f *FilterFactory, configInput string) (SubmitFilter, error) {
 	// parts[0] = "priceFeed", parts[1] = comparisonMode, parts[2] = feedDataType, parts[3] = feedURL which can have more "/" chars
 	parts := strings.Split(configInput, "/")
+	if len(parts) > 1 && parts[1] == "band" {
+		return filterPriceBand(f, configInput)
+	}
 	if len(parts) < 4 {
 		return nil, fmt.Errorf("\"priceFeed\" filter needs at least 4 parts separated by the '/' delimiter (priceFeed/<comparisonMode>/<feedDataType>/<feedURL>) but we received %s", configInput)
 	}
@@ -265,3 +299,153 @@ func filterPriceFeed(f *FilterFactory, configInput string) (SubmitFilter, error)
 
 	return filter, nil
 }
+
+func filterPriceBand(f *FilterFactory, configInput string) (SubmitFilter, error) {
+	config, e := makePriceBandFilterConfig(configInput)
+	if e != nil {
+		return nil, fmt.Errorf("could not make PriceBandFilterConfig for configInput (%s): %s", configInput, e)
+	}
+
+	return makeFilterPriceBand(
+		configInput,
+		f.BaseAsset,
+		f.QuoteAsset,
+		config,
+		f.StateDir,
+		f.StateMaxAge,
+		time.Now,
+	)
+}
+
+func filterExposure(f *FilterFactory, configInput string) (SubmitFilter, error) {
+	config, e := makeExposureFilterConfig(configInput)
+	if e != nil {
+		return nil, fmt.Errorf("could not make ExposureFilterConfig for configInput (%s): %s", configInput, e)
+	}
+
+	return makeFilterExposure(
+		configInput,
+		f.BaseAsset,
+		f.QuoteAsset,
+		f.IEIF,
+		config,
+	)
+}
+
+func filterSelfTrade(f *FilterFactory, configInput string) (SubmitFilter, error) {
+	parts := strings.Split(configInput, "/")
+	if len(parts) != 2 {
+		return nil, fmt.Errorf("\"selfTrade\" filter needs 2 parts separated by the '/' delimiter (selfTrade/<drop|reprice>) but we received %s", configInput)
+	}
+	if f.ExchangeShim == nil {
+		return nil, fmt.Errorf("cannot make \"selfTrade\" filter without an exchange to load open offers from")
+	}
+
+	var reprice bool
+	if parts[1] == "drop" {
+		reprice = false
+	} else if parts[1] == "reprice" {
+		reprice = true
+	} else {
+		return nil, fmt.Errorf("invalid action '%s' in config input (%s), needs to be either \"drop\" or \"reprice\"", parts[1], configInput)
+	}
+
+	return MakeFilterSelfTrade(f.ExchangeShim, f.TradingPair, f.BaseAsset, f.QuoteAsset, reprice)
+}
+
+func makeExposureFilterConfig(configInput string) (*ExposureFilterConfig, error) {
+	// parts[0] = "exposure", parts[1] = minInventory, parts[2] = maxInventory, parts[3] = maxRestingPerSide, parts[4] = mode,
+	// parts[5] = feedDataType, parts[6] = feedURL which can have more "/" chars
+	parts := strings.Split(configInput, "/")
+	if len(parts) < 7 {
+		return nil, fmt.Errorf("\"exposure\" filter needs at least 7 parts separated by the '/' delimiter (exposure/<minInventory>/<maxInventory>/<maxRestingPerSide>/<mode>/<feedDataType>/<feedURL>) but we received %s", configInput)
+	}
+
+	limits := []*float64{}
+	for i, name := range []string{"minInventory", "maxInventory", "maxRestingPerSide"} {
+		limit, e := parseOptionalFilterLimit(parts[i+1])
+		if e != nil {
+			return nil, fmt.Errorf("could not parse %s from config value (%s): %s", name, configInput, e)
+		}
+		limits = append(limits, limit)
+	}
+
+	mode, e := parseVolumeFilterMode(parts[4])
+	if e != nil {
+		return nil, fmt.Errorf("could not parse exposure filter mode from input (%s): %s", configInput, e)
+	}
+
+	feedType := parts[5]
+	feedURL := strings.Join(parts[6:len(parts)], "/")
+	pf, e := MakePriceFeed(feedType, feedURL)
+	if e != nil {
+		return nil, fmt.Errorf("could not make price feed for config input string '%s': %s", configInput, e)
+	}
+
+	config := &ExposureFilterConfig{
+		MinInventoryNotional:      limits[0],
+		MaxInventoryNotional:      limits[1],
+		MaxRestingNotionalPerSide: limits[2],
+		mode:                      mode,
+		feed:                      pf,
+	}
+	if e = config.Validate(); e != nil {
+		return nil, fmt.Errorf("invalid input (%s), did not pass validation: %s", configInput, e)
+	}
+	return config, nil
+}
+
+func makePriceBandFilterConfig(configInput string) (*PriceBandFilterConfig, error) {
+	// parts[0] = "priceFeed", parts[1] = "band", parts[2] = movingAverageType, parts[3] = numSamples, parts[4] = maxDeviation,
+	// parts[5] = feedDataType, parts[6] = feedURL which can have more "/" chars
+	parts := strings.Split(configInput, "/")
+	if len(parts) < 7 {
+		return nil, fmt.Errorf("\"priceFeed/band\" filter needs at least 7 parts separated by the '/' delimiter (priceFeed/band/<sma|ema>/<numSamples>/<maxDeviation>/<feedDataType>/<feedURL>) but we received %s", configInput)
+	}
+
+	maType, e := parseMovingAverageType(parts[2])
+	if e != nil {
+		return nil, fmt.Errorf("could not parse moving average type from input (%s): %s", configInput, e)
+	}
+
+	numSamples, e := strconv.Atoi(parts[3])
+	if e != nil {
+		return nil, fmt.Errorf("could not parse the number of samples as an int value from config value (%s): %s", configInput, e)
+	}
+
+	maxDeviation, e := strconv.ParseFloat(parts[4], 64)
+	if e != nil {
+		return nil, fmt.Errorf("could not parse the max deviation as a float value from config value (%s): %s", configInput, e)
+	}
+
+	feedType := parts[5]
+	feedURL := strings.Join(parts[6:len(parts)], "/")
+	pf, e := MakePriceFeed(feedType, feedURL)
+	if e != nil {
+		return nil, fmt.Errorf("could not make price feed for config input string '%s': %s", configInput, e)
+	}
+
+	config := &PriceBandFilterConfig{
+		MovingAverage: maType,
+		NumSamples:    numSamples,
+		MaxDeviation:  maxDeviation,
+		feed:          pf,
+	}
+	if e = config.Validate(); e != nil {
+		return nil, fmt.Errorf("invalid input (%s), did not pass validation: %s", configInput, e)
+	}
+	return config, nil
+}
+
+// parseOptionalFilterLimit parses a float value, or returns nil when the value is "none" to indicate that the limit is not set
+func parseOptionalFilterLimit(value string) (*float64, error) {
+	if value == "none" {
+		return nil, nil
+	}
+
+	limit, e := strconv.ParseFloat(value, 64)
+	if e != nil {
+		return nil, fmt.Errorf("could not parse '%s' as a float value or \"none\": %s", value, e)
+	}
+	return &limit, nil
+}
@@ -195,6 +195,15 @@ func TestMakeVolumeFilterConfig(t *testing.T) {
 				additionalMarketIDs:      []string{"4c19915f47", "db4531d586"},
 				optionalAccountIDs:       []string{"account1", "account2"},
 			},
+		}, {
+			configInput: "volume/daily/%s/notional/5000.0/%s",
+			wantConfig: &VolumeFilterConfig{
+				BaseAssetCapInBaseUnits:     nil,
+				BaseAssetCapInQuoteUnits:    nil,
+				BaseAssetCapInNotionalUnits: pointy.Float64(5000.0),
+				additionalMarketIDs:         nil,
+				optionalAccountIDs:          nil,
+			},
 		},
 	}
 
@@ -223,6 +232,54 @@ func TestMakeVolumeFilterConfig(t *testing.T) {
 	}
 }
 
+func TestMakeVolumeFilterConfig_Window(t *testing.T) {
+	testCases := []struct {
+		configInput string
+		wantWindow  string
+		wantIDs     []string
+		wantError   bool
+	}{
+		{
+			configInput: "volume/daily/sell/base/3500.0/exact",
+			wantWindow:  "daily(UTC)",
+		}, {
+			configInput: "volume/rolling24h/sell/quote/1000.0/exact",
+			wantWindow:  "rolling(24h0m0s)",
+		}, {
+			configInput: "volume/weekly:tz=America/New_York/buy/base/3500.0/ignore",
+			wantWindow:  "weekly(America/New_York)",
+		}, {
+			configInput: "volume/daily:tz=America/New_York:market_ids=[4c19915f47,db4531d586]/sell/base/3500.0/exact",
+			wantWindow:  "daily(America/New_York)",
+			wantIDs:     []string{"4c19915f47", "db4531d586"},
+		}, {
+			configInput: "volume/rolling24h:tz=America/New_York/sell/base/3500.0/exact",
+			wantError:   true,
+		}, {
+			configInput: "volume/daily:tz=Invalid/Timezone/sell/base/3500.0/exact",
+			wantError:   true,
+		}, {
+			configInput: "volume/yearly/sell/base/3500.0/exact",
+			wantError:   true,
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.configInput, func(t *testing.T) {
+			actual, e := makeVolumeFilterConfig(k.configInput)
+			if k.wantError {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, k.wantWindow, actual.window.String())
+			assert.Equal(t, k.wantIDs, actual.additionalMarketIDs)
+		})
+	}
+}
+
 func assertVolumeFilterConfigEqual(t *testing.T, want *VolumeFilterConfig, actual *VolumeFilterConfig) {
 	if want == nil {
 		assert.Nil(t, actual)
@@ -231,9 +288,130 @@ func assertVolumeFilterConfigEqual(t *testing.T, want *VolumeFilterConfig, actua
 	} else {
 		assert.Equal(t, want.BaseAssetCapInBaseUnits, actual.BaseAssetCapInBaseUnits)
 		assert.Equal(t, want.BaseAssetCapInQuoteUnits, actual.BaseAssetCapInQuoteUnits)
+		assert.Equal(t, want.BaseAssetCapInNotionalUnits, actual.BaseAssetCapInNotionalUnits)
 		assert.Equal(t, want.action, actual.action)
 		assert.Equal(t, want.mode, actual.mode)
 		assert.Equal(t, want.additionalMarketIDs, actual.additionalMarketIDs)
 		assert.Equal(t, want.optionalAccountIDs, actual.optionalAccountIDs)
 	}
 }
+
+func TestMakeExposureFilterConfig(t *testing.T) {
+	testCases := []struct {
+		configInput string
+		wantMin     *float64
+		wantMax     *float64
+		wantResting *float64
+		wantMode    volumeFilterMode
+		wantErr     bool
+	}{
+		{
+			configInput: "exposure/1000.0/5000.0/500.0/exact/fixed/0.5",
+			wantMin:     pointy.Float64(1000.0),
+			wantMax:     pointy.Float64(5000.0),
+			wantResting: pointy.Float64(500.0),
+			wantMode:    volumeFilterModeExact,
+		}, {
+			configInput: "exposure/none/5000.0/none/ignore/fixed/0.5",
+			wantMin:     nil,
+			wantMax:     pointy.Float64(5000.0),
+			wantResting: nil,
+			wantMode:    volumeFilterModeIgnore,
+		}, {
+			// feed URL can contain the delimiter
+			configInput: "exposure/none/none/500.0/exact/function/max(fixed/0.5,fixed/0.4)",
+			wantMin:     nil,
+			wantMax:     nil,
+			wantResting: pointy.Float64(500.0),
+			wantMode:    volumeFilterModeExact,
+		}, {
+			configInput: "exposure/none/none/none/exact/fixed/0.5",
+			wantErr:     true,
+		}, {
+			configInput: "exposure/5000.0/1000.0/none/exact/fixed/0.5",
+			wantErr:     true,
+		}, {
+			configInput: "exposure/1000.0/5000.0/500.0/hello/fixed/0.5",
+			wantErr:     true,
+		}, {
+			configInput: "exposure/abc/5000.0/500.0/exact/fixed/0.5",
+			wantErr:     true,
+		}, {
+			configInput: "exposure/1000.0/5000.0/500.0/exact",
+			wantErr:     true,
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.configInput, func(t *testing.T) {
+			actual, e := makeExposureFilterConfig(k.configInput)
+			if k.wantErr {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, k.wantMin, actual.MinInventoryNotional)
+			assert.Equal(t, k.wantMax, actual.MaxInventoryNotional)
+			assert.Equal(t, k.wantResting, actual.MaxRestingNotionalPerSide)
+			assert.Equal(t, k.wantMode, actual.mode)
+			assert.NotNil(t, actual.feed)
+		})
+	}
+}
+
+func TestMakePriceBandFilterConfig(t *testing.T) {
+	testCases := []struct {
+		configInput      string
+		wantType         movingAverageType
+		wantNumSamples   int
+		wantMaxDeviation float64
+		wantErr          bool
+	}{
+		{
+			configInput:      "priceFeed/band/ema/20/0.02/fixed/0.5",
+			wantType:         movingAverageEMA,
+			wantNumSamples:   20,
+			wantMaxDeviation: 0.02,
+		}, {
+			// feed URL can contain the delimiter
+			configInput:      "priceFeed/band/sma/5/0.1/function/max(fixed/0.5,fixed/0.4)",
+			wantType:         movingAverageSMA,
+			wantNumSamples:   5,
+			wantMaxDeviation: 0.1,
+		}, {
+			configInput: "priceFeed/band/wma/5/0.1/fixed/0.5",
+			wantErr:     true,
+		}, {
+			configInput: "priceFeed/band/sma/0/0.1/fixed/0.5",
+			wantErr:     true,
+		}, {
+			configInput: "priceFeed/band/sma/5/1.5/fixed/0.5",
+			wantErr:     true,
+		}, {
+			configInput: "priceFeed/band/sma/abc/0.1/fixed/0.5",
+			wantErr:     true,
+		}, {
+			configInput: "priceFeed/band/sma/5/0.1",
+			wantErr:     true,
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.configInput, func(t *testing.T) {
+			actual, e := makePriceBandFilterConfig(k.configInput)
+			if k.wantErr {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, k.wantType, actual.MovingAverage)
+			assert.Equal(t, k.wantNumSamples, actual.NumSamples)
+			assert.Equal(t, k.wantMaxDeviation, actual.MaxDeviation)
+			assert.NotNil(t, actual.feed)
+		})
+	}
+}
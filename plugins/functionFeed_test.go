@@ -6,36 +6,91 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
-func TestExtractFunctionParts(t *testing.T) {
+func TestMakeFunctionPriceFeed(t *testing.T) {
 	testCases := []struct {
-		inputURL string
-		wantName string
-		wantArgs string
+		url       string
+		wantPrice float64
 	}{
 		{
-			inputURL: "max(test)",
-			wantName: "max",
-			wantArgs: "test",
+			url:       "max(fixed/1.0,fixed/2.0)",
+			wantPrice: 2.0,
 		}, {
-			inputURL: "invert(max(test))",
-			wantName: "invert",
-			wantArgs: "max(test)",
+			url:       "min(fixed/1.0, fixed/2.0)",
+			wantPrice: 1.0,
 		}, {
-			inputURL: "max(fixed/0.02,crypto/https://api.coinmarketcap.com/v1/ticker/stellar/)",
-			wantName: "max",
-			wantArgs: "fixed/0.02,crypto/https://api.coinmarketcap.com/v1/ticker/stellar/",
+			url:       "invert(max(fixed/0.5,fixed/0.25))",
+			wantPrice: 2.0,
+		}, {
+			url:       "median(fixed/1.0, fixed/2.0, fixed/4.0) * 1.5",
+			wantPrice: 3.0,
+		}, {
+			url:       "median(fixed/1.0, fixed/2.0, fixed/4.0, fixed/8.0)",
+			wantPrice: 3.0,
+		}, {
+			url:       "avg(fixed/1.0, fixed/2.0, fixed/6.0)",
+			wantPrice: 3.0,
+		}, {
+			url:       "wavg(3, fixed/1.0, 1, fixed/5.0)",
+			wantPrice: 2.0,
+		}, {
+			url:       "(fixed/1.0 + 2) * 3 - 1 / 2",
+			wantPrice: 8.5,
+		}, {
+			url:       "-fixed/1.0 + 4",
+			wantPrice: 3.0,
+		}, {
+			url:       "a = fixed/2.0; b = max(a, fixed/3.0); b / a",
+			wantPrice: 1.5,
+		}, {
+			url:       "function/max(fixed/1.0,fixed/2.0) * 2",
+			wantPrice: 4.0,
 		},
 	}
 
 	for _, k := range testCases {
-		t.Run(k.inputURL, func(t *testing.T) {
-			fnName, fnArgs, e := extractFunctionParts(k.inputURL)
+		t.Run(k.url, func(t *testing.T) {
+			pf, e := makeFunctionPriceFeed(k.url, priceFeedFactory{})
+			if !assert.NoError(t, e) {
+				return
+			}
+
+			price, e := pf.GetPrice()
 			if !assert.NoError(t, e) {
 				return
 			}
+			assert.InDelta(t, k.wantPrice, price, 0.0000001)
+		})
+	}
+}
+
+func TestMakeFunctionPriceFeedErrors(t *testing.T) {
+	testCases := []struct {
+		url string
+	}{
+		{url: "unknown(fixed/1.0)"},
+		{url: "max(fixed/1.0)"},
+		{url: "wavg(1, fixed/1.0, 2)"},
+		{url: "max(fixed/1.0, fixed/2.0"},
+		{url: "fixed/1.0 +"},
+		{url: "missing * 2"},
+		{url: "a = fixed/1.0; a = fixed/2.0; a"},
+		{url: "fixed/1.0 fixed/2.0"},
+	}
 
-			assert.Equal(t, k.wantName, fnName)
-			assert.Equal(t, k.wantArgs, fnArgs)
+	for _, k := range testCases {
+		t.Run(k.url, func(t *testing.T) {
+			_, e := makeFunctionPriceFeed(k.url, priceFeedFactory{})
+			assert.Error(t, e)
 		})
 	}
 }
+
+func TestFunctionPriceFeedDivisionByZero(t *testing.T) {
+	pf, e := makeFunctionPriceFeed("fixed/1.0 / (fixed/1.0 - 1)", priceFeedFactory{})
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	_, e = pf.GetPrice()
+	assert.Error(t, e)
+}
@@ -3,9 +3,11 @@ package plugins
 import (
 	"fmt"
 	"log"
+	"strconv"
 	"strings"
 
 	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
 	"github.com/stellar/kelp/api"
 	"github.com/stellar/kelp/support/utils"
 )
@@ -63,6 +65,23 @@ func (ieif *IEIF) AddLiabilities(selling hProtocol.Asset, buying hProtocol.Asset
 	}
 }
 
+// AddManageBuyOfferLiabilities updates the cached liabilities for a ManageBuyOffer op. The amount of a buy offer is in units of the buying
+// asset and its price is in units of the selling asset, so the liabilities are computed from the op itself instead of the equivalent sell offer.
+// Liabilities loaded from the network do not need this because horizon always represents offers as sell offers
+func (ieif *IEIF) AddManageBuyOfferLiabilities(selling hProtocol.Asset, buying hProtocol.Asset, op *txnbuild.ManageBuyOffer, incrementalNativeAmountRaw float64) error {
+	amount, e := strconv.ParseFloat(op.Amount, 64)
+	if e != nil {
+		return fmt.Errorf("could not parse amount of ManageBuyOffer (%s) as float: %s", op.Amount, e)
+	}
+	price, e := strconv.ParseFloat(op.Price, 64)
+	if e != nil {
+		return fmt.Errorf("could not parse price of ManageBuyOffer (%s) as float: %s", op.Price, e)
+	}
+
+	ieif.AddLiabilities(selling, buying, amount*price, amount, incrementalNativeAmountRaw)
+	return nil
+}
+
 // RecomputeAndLogCachedLiabilities clears the cached liabilities and recomputes from the network before logging
 func (ieif *IEIF) RecomputeAndLogCachedLiabilities(assetBase hProtocol.Asset, assetQuote hProtocol.Asset) {
 	ieif.cachedLiabilities = map[hProtocol.Asset]Liabilities{}
@@ -236,7 +255,30 @@ func (ieif *IEIF) assetLiabilities(asset hProtocol.Asset) (*Liabilities, error)
 	}
 
 	assetLiabilities, _, e := ieif._liabilities(offers, asset, asset) // pass in the same asset, we ignore the returned object anyway
-	return assetLiabilities, e
+	if e != nil {
+		return nil, e
+	}
+	ieif.cachedLiabilities[asset] = *assetLiabilities
+	return assetLiabilities, nil
+}
+
+// ExternalLiabilities returns the liabilities for the asset from offers that are not on the trading pair formed with otherAsset, i.e. offers
+// placed by other bots sharing the same account. It always loads offers from the network and does not modify the cached liabilities
+func (ieif *IEIF) ExternalLiabilities(asset hProtocol.Asset, otherAsset hProtocol.Asset) (*Liabilities, error) {
+	offers, e := ieif.exchangeShim.LoadOffersHack()
+	if e != nil {
+		assetString := utils.Asset2String(asset)
+		return nil, fmt.Errorf("cannot load offers to compute external liabilities for asset (%s): %s", assetString, e)
+	}
+
+	assetLiabilities, pairLiabilities, e := ieif._liabilities(offers, asset, otherAsset)
+	if e != nil {
+		return nil, e
+	}
+	return &Liabilities{
+		Buying:  assetLiabilities.Buying - pairLiabilities.Buying,
+		Selling: assetLiabilities.Selling - pairLiabilities.Selling,
+	}, nil
 }
 
 // pairLiabilities returns the liabilities for the asset along with the pairLiabilities
@@ -282,7 +324,6 @@ func (ieif *IEIF) _liabilities(offers []hProtocol.Offer, asset hProtocol.Asset,
 		}
 	}
 
-	ieif.cachedLiabilities[asset] = liabilities
 	return &liabilities, &pairLiabilities, nil
 }
 
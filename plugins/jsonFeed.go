@@ -0,0 +1,199 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/kelp/api"
+)
+
+// priceFeedHeaders are the named sets of http headers that json feeds can reference, set from the bot config
+var priceFeedHeaders = map[string]map[string]string{}
+var priceFeedHeadersMutex = &sync.Mutex{}
+
+// SetPriceFeedHeaders sets the named sets of http headers that json feeds can reference with the "headers" option
+func SetPriceFeedHeaders(headers map[string]map[string]string) {
+	priceFeedHeadersMutex.Lock()
+	defer priceFeedHeadersMutex.Unlock()
+
+	priceFeedHeaders = headers
+}
+
+func getPriceFeedHeaders(name string) (map[string]string, bool) {
+	priceFeedHeadersMutex.Lock()
+	defer priceFeedHeadersMutex.Unlock()
+
+	headers, ok := priceFeedHeaders[name]
+	return headers, ok
+}
+
+// jsonFeed fetches a price from any http endpoint that returns JSON
+type jsonFeed struct {
+	endpoint string
+	path     []string
+	scale    float64
+	invert   bool
+	headers  map[string]string
+	client   http.Client
+}
+
+// ensure that it implements PriceFeed
+var _ api.PriceFeed = &jsonFeed{}
+
+// newJSONFeed makes a json feed from a URL formatted like so:
+// https://endpoint#path=data.rates.0.price[&scale=0.01][&invert=true][&headers=name]
+// the options are in the URL fragment so they are never sent to the endpoint and do not clash with the separators used by the
+// function, consensus and cache feeds. path is a dot-separated path to the price in the response, array elements are referenced
+// by their index and a leading "$." is allowed
+func newJSONFeed(url string) (*jsonFeed, error) {
+	i := strings.LastIndex(url, "#")
+	if i == -1 {
+		return nil, fmt.Errorf("json feed URL needs options after a '#' following the endpoint: %s", url)
+	}
+
+	f := &jsonFeed{
+		endpoint: url[:i],
+		scale:    1.0,
+		invert:   false,
+		headers:  map[string]string{},
+		client:   http.Client{Timeout: 10 * time.Second},
+	}
+	for _, option := range strings.Split(url[i+1:], "&") {
+		kv := strings.SplitN(option, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("invalid option '%s' in json feed URL, needs to be formatted as key=value", option)
+		}
+
+		switch kv[0] {
+		case "path":
+			f.path = parseJSONPath(kv[1])
+		case "scale":
+			scale, e := strconv.ParseFloat(kv[1], 64)
+			if e != nil {
+				return nil, fmt.Errorf("unable to parse scale in json feed URL: %s", e)
+			}
+			if scale <= 0.0 {
+				return nil, fmt.Errorf("scale in json feed URL needs to be > 0.0, was %.10f", scale)
+			}
+			f.scale = scale
+		case "invert":
+			invert, e := strconv.ParseBool(kv[1])
+			if e != nil {
+				return nil, fmt.Errorf("unable to parse invert in json feed URL: %s", e)
+			}
+			f.invert = invert
+		case "headers":
+			headers, ok := getPriceFeedHeaders(kv[1])
+			if !ok {
+				return nil, fmt.Errorf("json feed URL references headers '%s' which are not defined in PRICE_FEED_HEADERS", kv[1])
+			}
+			f.headers = headers
+		default:
+			return nil, fmt.Errorf("unknown option '%s' in json feed URL", kv[0])
+		}
+	}
+
+	if len(f.path) == 0 {
+		return nil, fmt.Errorf("json feed URL needs a path option: %s", url)
+	}
+	return f, nil
+}
+
+// parseJSONPath splits a path such as "$.data[0].price" or "data.0.price" into its keys
+func parseJSONPath(path string) []string {
+	path = strings.TrimPrefix(path, "$")
+	path = strings.Replace(path, "[", ".", -1)
+	path = strings.Replace(path, "]", "", -1)
+
+	keys := []string{}
+	for _, key := range strings.Split(path, ".") {
+		if key != "" {
+			keys = append(keys, key)
+		}
+	}
+	return keys
+}
+
+// GetPrice impl
+func (f *jsonFeed) GetPrice() (float64, error) {
+	req, e := http.NewRequest("GET", f.endpoint, nil)
+	if e != nil {
+		return 0, fmt.Errorf("unable to make request for json feed: %s", e)
+	}
+	for header, value := range f.headers {
+		req.Header.Set(header, value)
+	}
+
+	resp, e := f.client.Do(req)
+	if e != nil {
+		return 0, fmt.Errorf("unable to get price from json feed: %s", e)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return 0, fmt.Errorf("json feed endpoint responded with status code %d", resp.StatusCode)
+	}
+
+	var body interface{}
+	e = json.NewDecoder(resp.Body).Decode(&body)
+	if e != nil {
+		return 0, fmt.Errorf("unable to decode json feed response: %s", e)
+	}
+
+	price, e := extractJSONPrice(body, f.path)
+	if e != nil {
+		return 0, fmt.Errorf("unable to extract price from json feed response: %s", e)
+	}
+
+	price = price * f.scale
+	if f.invert {
+		if price == 0.0 {
+			return 0, fmt.Errorf("cannot invert a price of 0.0 from the json feed")
+		}
+		price = 1.0 / price
+	}
+	return price, nil
+}
+
+// extractJSONPrice follows the path through the decoded JSON and parses the value found as a number, numeric strings are allowed
+func extractJSONPrice(body interface{}, path []string) (float64, error) {
+	current := body
+	for i, key := range path {
+		switch node := current.(type) {
+		case map[string]interface{}:
+			next, ok := node[key]
+			if !ok {
+				return 0, fmt.Errorf("key '%s' not found at '%s'", key, strings.Join(path[:i], "."))
+			}
+			current = next
+		case []interface{}:
+			index, e := strconv.Atoi(key)
+			if e != nil {
+				return 0, fmt.Errorf("key '%s' is not a valid index into the array at '%s'", key, strings.Join(path[:i], "."))
+			}
+			if index < 0 || index >= len(node) {
+				return 0, fmt.Errorf("index %d is out of bounds of the array of length %d at '%s'", index, len(node), strings.Join(path[:i], "."))
+			}
+			current = node[index]
+		default:
+			return 0, fmt.Errorf("cannot follow key '%s' into a non-object value at '%s'", key, strings.Join(path[:i], "."))
+		}
+	}
+
+	switch v := current.(type) {
+	case float64:
+		return v, nil
+	case string:
+		price, e := strconv.ParseFloat(v, 64)
+		if e != nil {
+			return 0, fmt.Errorf("value '%s' at '%s' is not a number: %s", v, strings.Join(path, "."), e)
+		}
+		return price, nil
+	default:
+		return 0, fmt.Errorf("value at '%s' is not a number or a numeric string: %v", strings.Join(path, "."), current)
+	}
+}
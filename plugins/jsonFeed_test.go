@@ -0,0 +1,86 @@
+package plugins
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJSONFeed(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		fmt.Fprint(w, `{"data": {"rates": [{"price": 0.25}, {"price": "410.5"}]}, "name": "test"}`)
+	}))
+	defer server.Close()
+	SetPriceFeedHeaders(map[string]map[string]string{
+		"pricing": {"Authorization": "Bearer token"},
+	})
+	defer SetPriceFeedHeaders(map[string]map[string]string{})
+
+	testCases := []struct {
+		options   string
+		wantPrice float64
+		wantError bool
+	}{
+		{
+			options:   "path=data.rates.0.price&headers=pricing",
+			wantPrice: 0.25,
+		}, {
+			options:   "path=$.data.rates[1].price&headers=pricing",
+			wantPrice: 410.5,
+		}, {
+			options:   "path=data.rates.0.price&scale=2&invert=true&headers=pricing",
+			wantPrice: 2.0,
+		}, {
+			options:   "path=data.rates.2.price&headers=pricing",
+			wantError: true,
+		}, {
+			options:   "path=name&headers=pricing",
+			wantError: true,
+		}, {
+			options:   "path=data.rates.0.price",
+			wantError: true,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.options, func(t *testing.T) {
+			f, e := newJSONFeed(server.URL + "/rates#" + kase.options)
+			if !assert.NoError(t, e) {
+				return
+			}
+
+			price, e := f.GetPrice()
+			if kase.wantError {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.InDelta(t, kase.wantPrice, price, 0.0000001)
+		})
+	}
+}
+
+func TestNewJSONFeedErrors(t *testing.T) {
+	testCases := []string{
+		"https://example.com/rates",
+		"https://example.com/rates#scale=2",
+		"https://example.com/rates#path=a&unknown=1",
+		"https://example.com/rates#path=a&headers=missing",
+	}
+
+	for _, url := range testCases {
+		t.Run(url, func(t *testing.T) {
+			_, e := newJSONFeed(url)
+			assert.Error(t, e)
+		})
+	}
+}
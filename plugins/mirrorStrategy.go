@@ -49,6 +49,8 @@ type mirrorConfig struct {
 	OffsetTrades                              bool                     `valid:"-" toml:"OFFSET_TRADES"`
 	BackingDbOverrideAccountID                string                   `valid:"-" toml:"BACKING_DB_OVERRIDE__ACCOUNT_ID"`
 	BackingFillTrackerLastTradeCursorOverride string                   `valid:"-" toml:"BACKING_FILL_TRACKER_LAST_TRADE_CURSOR_OVERRIDE"`
+	BackingNotionalFeedType                   string                   `valid:"-" toml:"BACKING_NOTIONAL_FEED_TYPE"`
+	BackingNotionalFeedURL                    string                   `valid:"-" toml:"BACKING_NOTIONAL_FEED_URL"`
 	ExchangeAPIKeys                           toml.ExchangeAPIKeysToml `valid:"-" toml:"EXCHANGE_API_KEYS"`
 	ExchangeParams                            toml.ExchangeParamsToml  `valid:"-" toml:"EXCHANGE_PARAMS"`
 	ExchangeHeaders                           toml.ExchangeHeadersToml `valid:"-" toml:"EXCHANGE_HEADERS"`
@@ -138,6 +140,17 @@ func convertDeprecatedMirrorConfigValues(config *mirrorConfig) {
 }
 
 // makeMirrorStrategy is a factory method
+// makeBackingNotionalFeed makes the feed used to write the notional value of the trades on the backing exchange, returns nil when not configured
+func makeBackingNotionalFeed(config *mirrorConfig) (api.PriceFeed, error) {
+	if config.BackingNotionalFeedType == "" && config.BackingNotionalFeedURL == "" {
+		return nil, nil
+	}
+	if config.BackingNotionalFeedType == "" || config.BackingNotionalFeedURL == "" {
+		return nil, fmt.Errorf("need to set both BACKING_NOTIONAL_FEED_TYPE and BACKING_NOTIONAL_FEED_URL or neither of them")
+	}
+	return MakePriceFeed(config.BackingNotionalFeedType, config.BackingNotionalFeedURL)
+}
+
 func makeMirrorStrategy(
 	sdex *SDEX,
 	ieif *IEIF,
@@ -243,13 +256,18 @@ func makeMirrorStrategy(
 			backingLastCursor = config.BackingFillTrackerLastTradeCursorOverride
 			log.Printf("set backingLastCursor from where to start tracking fills for backing exchange in mirror strategy (used override value): %v\n", backingLastCursor)
 		}
-		backingFillTracker = MakeFillTracker(backingPair, multithreading.MakeThreadTracker(), exchange, 0, 0, backingLastCursor)
+		backingFillTracker = MakeFillTracker(backingPair, multithreading.MakeThreadTracker(), exchange, nil, 0, 0, backingLastCursor)
 		backingFillTracker.RegisterHandler(MakeFillLogger())
 		backingAssetDisplayFn := model.MakePassthroughAssetDisplayFn()
 		if config.Exchange == "sdex" {
 			return nil, fmt.Errorf("we cannot mirror trades from SDEX for now (programmer: need to create sdexAssetMap to inject into the backingAssetDisplayFn)")
 		}
-		fillDBWriter := MakeFillDBWriter(db, backingAssetDisplayFn, config.Exchange, config.BackingDbOverrideAccountID)
+		var backingNotionalFeed api.PriceFeed
+		backingNotionalFeed, e = makeBackingNotionalFeed(config)
+		if e != nil {
+			return nil, fmt.Errorf("could not make the notional feed for the backing exchange in mirrorStrategy: %s", e)
+		}
+		fillDBWriter := MakeFillDBWriter(db, backingAssetDisplayFn, config.Exchange, config.BackingDbOverrideAccountID, backingNotionalFeed)
 		backingFillTracker.RegisterHandler(fillDBWriter)
 	}
 
@@ -47,6 +47,7 @@ func (f *orderConstraintsFilter) Apply(
 		var e error
 		var opPtr *txnbuild.ManageSellOffer
 		var passiveSellOffer *txnbuild.CreatePassiveSellOffer
+		var buyOffer *txnbuild.ManageBuyOffer
 
 		switch o := op.(type) {
 		case *txnbuild.ManageSellOffer:
@@ -61,6 +62,12 @@ func (f *orderConstraintsFilter) Apply(
 				return nil, fmt.Errorf("could not transform offer (pointer case): %s", e)
 			}
 			passiveSellOffer = o
+		case *txnbuild.ManageBuyOffer:
+			keep, e = f.shouldKeepBuyOffer(o)
+			if e != nil {
+				return nil, fmt.Errorf("could not transform buy offer (pointer case): %s", e)
+			}
+			buyOffer = o
 		default:
 			keep = true
 		}
@@ -70,12 +77,25 @@ func (f *orderConstraintsFilter) Apply(
 				filteredOps = append(filteredOps, opPtr)
 			} else if passiveSellOffer != nil {
 				filteredOps = append(filteredOps, passiveSellOffer)
+			} else if buyOffer != nil {
+				filteredOps = append(filteredOps, buyOffer)
 			}
 			numKeep++
 		} else {
 			numDropped++
 			// figure out how to convert the offer to a dropped state
-			if opPtr.OfferID == 0 {
+			if buyOffer != nil {
+				if buyOffer.OfferID == 0 {
+					// new offers can be dropped, so don't add to filteredOps
+				} else if buyOffer.Amount != "0" {
+					// modify offers should be converted to delete offers
+					opCopy := *buyOffer
+					opCopy.Amount = "0"
+					filteredOps = append(filteredOps, &opCopy)
+				} else {
+					return nil, fmt.Errorf("unable to drop manageBuyOffer operation (probably a delete op that should not have reached here): offerID=%d, amountRaw=%s", buyOffer.OfferID, buyOffer.Amount)
+				}
+			} else if opPtr.OfferID == 0 {
 				// new offers can be dropped, so don't add to filteredOps
 			} else if opPtr.Amount != "0" {
 				// modify offers should be converted to delete offers
@@ -193,3 +213,47 @@ func (f *orderConstraintsFilter) shouldKeepPassiveOffer(op *txnbuild.CreatePassi
 	log.Printf("orderConstraintsFilter:  buying, baseAmount=%.8f, quoteAmount=%.8f, keep = true\n", baseAmount, quoteAmount)
 	return true, nil
 }
+
+// shouldKeepBuyOffer is the ManageBuyOffer version of shouldKeepOffer, the amount of a buy offer is in units of the buying asset
+// and the price is the price of 1 unit of the buying asset in units of the selling asset
+func (f *orderConstraintsFilter) shouldKeepBuyOffer(op *txnbuild.ManageBuyOffer) (bool, error) {
+	// delete operations should never be dropped
+	amountFloat, e := strconv.ParseFloat(op.Amount, 64)
+	if e != nil {
+		return false, fmt.Errorf("could not convert amount (%s) to float: %s", op.Amount, e)
+	}
+	if op.Amount == "0" || amountFloat == 0.0 {
+		log.Printf("orderConstraintsFilter: keeping delete operation with amount = %s\n", op.Amount)
+		return true, nil
+	}
+
+	isSell, e := utils.IsSelling(f.baseAsset, f.quoteAsset, op.Selling, op.Buying)
+	if e != nil {
+		return false, fmt.Errorf("error when running the isSelling check for buy offer '%+v': %s", *op, e)
+	}
+
+	buyPrice, e := strconv.ParseFloat(op.Price, 64)
+	if e != nil {
+		return false, fmt.Errorf("could not convert price (%s) to float: %s", op.Price, e)
+	}
+
+	action := "buying"
+	baseAmount := amountFloat
+	quoteAmount := baseAmount * buyPrice
+	if isSell {
+		action = "selling"
+		quoteAmount = amountFloat
+		baseAmount = quoteAmount * buyPrice
+	}
+
+	if baseAmount < f.oc.MinBaseVolume.AsFloat() {
+		log.Printf("orderConstraintsFilter: %s (buy offer), keep = (baseAmount) %.8f < %s (MinBaseVolume): keep = false\n", action, baseAmount, f.oc.MinBaseVolume.AsString())
+		return false, nil
+	}
+	if f.oc.MinQuoteVolume != nil && quoteAmount < f.oc.MinQuoteVolume.AsFloat() {
+		log.Printf("orderConstraintsFilter: %s (buy offer), keep = (quoteAmount) %.8f < %s (MinQuoteVolume): keep = false\n", action, quoteAmount, f.oc.MinQuoteVolume.AsString())
+		return false, nil
+	}
+	log.Printf("orderConstraintsFilter: %s (buy offer), baseAmount=%.8f, quoteAmount=%.8f, keep = true\n", action, baseAmount, quoteAmount)
+	return true, nil
+}
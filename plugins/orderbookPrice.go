@@ -0,0 +1,160 @@
+package plugins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/stellar/kelp/model"
+)
+
+// orderbookPriceDepth is the number of levels fetched on each side of the orderbook for the orderbook price modes
+const orderbookPriceDepth int32 = 50
+
+// orderbookPriceMode is a way of deriving a price from the orderbook instead of the ticker, set via the modifier of a feed URL:
+//
+//	microprice -- top of book prices weighted by the volume on the opposite side
+//	depthmid:<pct>% -- average of the volume-weighted bid and ask prices within pct of the mid price
+//	depthmid:<units> -- average of the volume-weighted bid and ask prices of the first units of base asset on each side
+//	impactask:<units> -- volume-weighted price of buying units of base asset from the asks
+//	impactbid:<units> -- volume-weighted price of selling units of base asset into the bids
+type orderbookPriceMode struct {
+	name      string
+	value     float64
+	isPercent bool
+}
+
+// parseOrderbookPriceMode parses the modifier, returning nil if the modifier is not an orderbook price mode
+func parseOrderbookPriceMode(modifier string) (*orderbookPriceMode, error) {
+	parts := strings.SplitN(modifier, ":", 2)
+	name := parts[0]
+	switch name {
+	case "microprice":
+		if len(parts) != 1 {
+			return nil, fmt.Errorf("orderbook price mode 'microprice' does not take a value: %s", modifier)
+		}
+		return &orderbookPriceMode{name: name}, nil
+	case "depthmid", "impactask", "impactbid":
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("orderbook price mode '%s' needs a value, e.g. '%s:1000': %s", name, name, modifier)
+		}
+	default:
+		return nil, nil
+	}
+
+	valueString := parts[1]
+	isPercent := strings.HasSuffix(valueString, "%")
+	if isPercent && name != "depthmid" {
+		return nil, fmt.Errorf("orderbook price mode '%s' only accepts a number of units, not a percentage: %s", name, modifier)
+	}
+	value, e := strconv.ParseFloat(strings.TrimSuffix(valueString, "%"), 64)
+	if e != nil {
+		return nil, fmt.Errorf("unable to parse value of orderbook price mode '%s': %s", modifier, e)
+	}
+	if value <= 0.0 {
+		return nil, fmt.Errorf("value of orderbook price mode needs to be > 0.0: %s", modifier)
+	}
+	if isPercent {
+		value = value / 100.0
+	}
+
+	return &orderbookPriceMode{
+		name:      name,
+		value:     value,
+		isPercent: isPercent,
+	}, nil
+}
+
+// String is the stringer function
+func (m *orderbookPriceMode) String() string {
+	if m.name == "microprice" {
+		return m.name
+	}
+	if m.isPercent {
+		return fmt.Sprintf("%s:%v%%", m.name, m.value*100.0)
+	}
+	return fmt.Sprintf("%s:%v", m.name, m.value)
+}
+
+// price derives the price from the orderbook
+func (m *orderbookPriceMode) price(ob *model.OrderBook) (float64, error) {
+	bids := ob.Bids()
+	asks := ob.Asks()
+	if len(bids) == 0 || len(asks) == 0 {
+		return 0, fmt.Errorf("unable to compute '%s' price because the orderbook has %d bids and %d asks", m, len(bids), len(asks))
+	}
+
+	switch m.name {
+	case "microprice":
+		bidPrice, bidVolume := bids[0].Price.AsFloat(), bids[0].Volume.AsFloat()
+		askPrice, askVolume := asks[0].Price.AsFloat(), asks[0].Volume.AsFloat()
+		return (bidPrice*askVolume + askPrice*bidVolume) / (bidVolume + askVolume), nil
+	case "depthmid":
+		var bidPrice, askPrice float64
+		var e error
+		if m.isPercent {
+			mid := (bids[0].Price.AsFloat() + asks[0].Price.AsFloat()) / 2
+			bidPrice, e = vwapWithinPrice(bids, mid*(1-m.value), false)
+			if e != nil {
+				return 0, fmt.Errorf("unable to compute '%s' price of the bids: %s", m, e)
+			}
+			askPrice, e = vwapWithinPrice(asks, mid*(1+m.value), true)
+			if e != nil {
+				return 0, fmt.Errorf("unable to compute '%s' price of the asks: %s", m, e)
+			}
+		} else {
+			bidPrice, e = vwapForVolume(bids, m.value)
+			if e != nil {
+				return 0, fmt.Errorf("unable to compute '%s' price of the bids: %s", m, e)
+			}
+			askPrice, e = vwapForVolume(asks, m.value)
+			if e != nil {
+				return 0, fmt.Errorf("unable to compute '%s' price of the asks: %s", m, e)
+			}
+		}
+		return (bidPrice + askPrice) / 2, nil
+	case "impactask":
+		return vwapForVolume(asks, m.value)
+	case "impactbid":
+		return vwapForVolume(bids, m.value)
+	default:
+		return 0, fmt.Errorf("unknown orderbook price mode '%s' (programmer error)", m.name)
+	}
+}
+
+// vwapForVolume is the volume-weighted price of the first volume units of base asset of the orders, which are sorted best first
+func vwapForVolume(orders []model.Order, volume float64) (float64, error) {
+	remaining := volume
+	notional := 0.0
+	for _, o := range orders {
+		v := o.Volume.AsFloat()
+		if v > remaining {
+			v = remaining
+		}
+		notional += v * o.Price.AsFloat()
+		remaining -= v
+		if remaining <= 0 {
+			return notional / volume, nil
+		}
+	}
+	return 0, fmt.Errorf("not enough depth, only %.7f of %.7f units of base asset available in %d levels", volume-remaining, volume, len(orders))
+}
+
+// vwapWithinPrice is the volume-weighted price of the orders up to the limit price, which are sorted best first
+func vwapWithinPrice(orders []model.Order, limitPrice float64, isAsk bool) (float64, error) {
+	notional := 0.0
+	volume := 0.0
+	for _, o := range orders {
+		p := o.Price.AsFloat()
+		if (isAsk && p > limitPrice) || (!isAsk && p < limitPrice) {
+			break
+		}
+		notional += o.Volume.AsFloat() * p
+		volume += o.Volume.AsFloat()
+	}
+
+	if volume == 0.0 {
+		return 0, fmt.Errorf("no orders within the limit price %.10f", limitPrice)
+	}
+	return notional / volume, nil
+}
@@ -0,0 +1,102 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stellar/kelp/model"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTestOrders(levels [][2]float64) []model.Order {
+	orders := []model.Order{}
+	for _, level := range levels {
+		orders = append(orders, model.Order{
+			Price:  model.NumberFromFloat(level[0], 7),
+			Volume: model.NumberFromFloat(level[1], 7),
+		})
+	}
+	return orders
+}
+
+func TestOrderbookPriceMode(t *testing.T) {
+	// a thin book where a single tiny bid sits far above the real bids
+	ob := model.MakeOrderBook(
+		&model.TradingPair{Base: model.XLM, Quote: model.USDT},
+		makeTestOrders([][2]float64{{1.02, 100}, {1.03, 300}, {1.10, 1000}}),
+		makeTestOrders([][2]float64{{1.01, 1}, {0.98, 99}, {0.97, 1000}}),
+	)
+
+	testCases := []struct {
+		modifier  string
+		wantPrice float64
+		wantError bool
+	}{
+		{
+			modifier:  "microprice",
+			wantPrice: (1.01*100 + 1.02*1) / 101,
+		}, {
+			modifier:  "depthmid:100",
+			wantPrice: ((1.01*1+0.98*99)/100 + 1.02) / 2,
+		}, {
+			modifier:  "depthmid:2%",
+			wantPrice: ((1.01*1)/1 + (1.02*100+1.03*300)/400) / 2,
+		}, {
+			modifier:  "impactask:200",
+			wantPrice: (1.02*100 + 1.03*100) / 200,
+		}, {
+			modifier:  "impactbid:100",
+			wantPrice: (1.01*1 + 0.98*99) / 100,
+		}, {
+			modifier:  "impactbid:5000",
+			wantError: true,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.modifier, func(t *testing.T) {
+			m, e := parseOrderbookPriceMode(kase.modifier)
+			if !assert.NoError(t, e) || !assert.NotNil(t, m) {
+				return
+			}
+
+			price, e := m.price(ob)
+			if kase.wantError {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.InDelta(t, kase.wantPrice, price, 0.0000001)
+		})
+	}
+}
+
+func TestParseOrderbookPriceMode(t *testing.T) {
+	testCases := []struct {
+		modifier  string
+		wantNil   bool
+		wantError bool
+	}{
+		{modifier: "mid", wantNil: true},
+		{modifier: "last", wantNil: true},
+		{modifier: "microprice:5", wantError: true},
+		{modifier: "depthmid", wantError: true},
+		{modifier: "impactask:1%", wantError: true},
+		{modifier: "impactbid:-5", wantError: true},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.modifier, func(t *testing.T) {
+			m, e := parseOrderbookPriceMode(kase.modifier)
+			if kase.wantError {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, kase.wantNil, m == nil)
+		})
+	}
+}
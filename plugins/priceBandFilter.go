@@ -0,0 +1,324 @@
+package plugins
+
+import (
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"io/ioutil"
+	"log"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/api"
+	"github.com/stellar/kelp/support/utils"
+)
+
+// movingAverageType is the kind of moving average used by the price band filter
+type movingAverageType string
+
+// these are the types of moving averages
+const (
+	movingAverageSMA movingAverageType = "sma"
+	movingAverageEMA movingAverageType = "ema"
+)
+
+func parseMovingAverageType(s string) (movingAverageType, error) {
+	if s == string(movingAverageSMA) {
+		return movingAverageSMA, nil
+	} else if s == string(movingAverageEMA) {
+		return movingAverageEMA, nil
+	}
+	return "", fmt.Errorf("invalid moving average type '%s', needs to be either '%s' or '%s'", s, movingAverageSMA, movingAverageEMA)
+}
+
+// movingAverage is a simple or exponential moving average over the last numSamples samples. The exponential moving average is
+// seeded with the first sample and uses a smoothing factor of 2/(numSamples+1)
+type movingAverage struct {
+	maType     movingAverageType
+	numSamples int
+
+	// uninitialized runtime vars
+	samples        []float64   // last numSamples samples with the oldest first, only used by the sma
+	sampleTimes    []time.Time // time of each of the samples, only used by the sma
+	ema            float64
+	count          int // number of samples added, capped at numSamples
+	lastSampleTime time.Time
+}
+
+// add includes a sample taken at the given time in the moving average
+func (m *movingAverage) add(sample float64, at time.Time) {
+	m.lastSampleTime = at
+	if m.maType == movingAverageSMA {
+		m.samples = append(m.samples, sample)
+		m.sampleTimes = append(m.sampleTimes, at)
+		if len(m.samples) > m.numSamples {
+			m.samples = m.samples[len(m.samples)-m.numSamples:]
+			m.sampleTimes = m.sampleTimes[len(m.sampleTimes)-m.numSamples:]
+		}
+	} else if m.count == 0 {
+		m.ema = sample
+	} else {
+		alpha := 2.0 / float64(m.numSamples+1)
+		m.ema = alpha*sample + (1-alpha)*m.ema
+	}
+
+	if m.count < m.numSamples {
+		m.count++
+	}
+}
+
+// value returns the moving average, which is only defined once at least one sample has been added
+func (m *movingAverage) value() float64 {
+	if m.maType == movingAverageEMA {
+		return m.ema
+	}
+
+	sum := 0.0
+	for _, s := range m.samples {
+		sum += s
+	}
+	return sum / float64(len(m.samples))
+}
+
+// priceBandState is the state of the moving average that is persisted across restarts
+type priceBandState struct {
+	Samples        []float64 `json:"samples"`
+	SampleTimes    []int64   `json:"sample_times"` // unix time in seconds of each of the samples
+	EMA            float64   `json:"ema"`
+	Count          int       `json:"count"`
+	LastSampleTime int64     `json:"last_sample_time"` // unix time in seconds of the last sample
+}
+
+// PriceBandFilterConfig contains the configuration params for this filter
+type PriceBandFilterConfig struct {
+	MovingAverage movingAverageType
+	NumSamples    int
+	MaxDeviation  float64 // max distance from the moving average as a fraction of the moving average (ex: 0.02 for 2%)
+	feed          api.PriceFeed
+}
+
+// Validate ensures validity
+func (c *PriceBandFilterConfig) Validate() error {
+	if c.NumSamples <= 0 {
+		return fmt.Errorf("number of samples needs to be > 0, was %d", c.NumSamples)
+	}
+	if c.MaxDeviation <= 0.0 || c.MaxDeviation >= 1.0 {
+		return fmt.Errorf("max deviation needs to be between 0.0 and 1.0 (exclusive), was %.4f", c.MaxDeviation)
+	}
+	return nil
+}
+
+// String is the stringer method
+func (c *PriceBandFilterConfig) String() string {
+	return fmt.Sprintf("PriceBandFilterConfig[MovingAverage=%s, NumSamples=%d, MaxDeviation=%.4f]", c.MovingAverage, c.NumSamples, c.MaxDeviation)
+}
+
+type priceBandFilter struct {
+	name       string
+	baseAsset  hProtocol.Asset
+	quoteAsset hProtocol.Asset
+	config     *PriceBandFilterConfig
+	average    *movingAverage
+	statePath  string // empty when the state is not persisted
+	now        func() time.Time
+}
+
+// makeFilterPriceBand makes a submit filter that keeps only the orders priced within a band around a moving average of the price feed,
+// so a single sample from the feed cannot move the prices we allow. A sample is taken every time the filter is applied, so the moving
+// average spans numSamples update cycles and no samples are taken while the filter is not applied (ex: inside a "when" filter whose
+// conditions do not hold). When stateDir is not empty the moving average is persisted to a file in that directory named after the
+// configInput so it survives restarts, and samples in that file that are older than stateMaxAge are discarded when it is loaded
+func makeFilterPriceBand(
+	configInput string,
+	baseAsset hProtocol.Asset,
+	quoteAsset hProtocol.Asset,
+	config *PriceBandFilterConfig,
+	stateDir string,
+	stateMaxAge time.Duration,
+	now func() time.Time,
+) (SubmitFilter, error) {
+	average := &movingAverage{
+		maType:      config.MovingAverage,
+		numSamples:  config.NumSamples,
+		samples:     []float64{},
+		sampleTimes: []time.Time{},
+	}
+
+	statePath := ""
+	if stateDir != "" {
+		e := os.MkdirAll(stateDir, 0755)
+		if e != nil {
+			return nil, fmt.Errorf("could not create the state directory '%s' for the price band filter: %s", stateDir, e)
+		}
+
+		statePath = priceBandStatePath(stateDir, configInput)
+		e = loadPriceBandState(statePath, average, now().Add(-stateMaxAge))
+		if e != nil {
+			return nil, fmt.Errorf("could not load the state of the price band filter from file '%s': %s", statePath, e)
+		}
+	}
+
+	return &priceBandFilter{
+		name:       "priceBandFilter",
+		baseAsset:  baseAsset,
+		quoteAsset: quoteAsset,
+		config:     config,
+		average:    average,
+		statePath:  statePath,
+		now:        now,
+	}, nil
+}
+
+var _ SubmitFilter = &priceBandFilter{}
+
+// priceBandStatePath returns the file used to persist the state of the filter made from configInput
+func priceBandStatePath(stateDir string, configInput string) string {
+	h := fnv.New64a()
+	h.Write([]byte(configInput))
+	return filepath.Join(stateDir, fmt.Sprintf("priceBand_%x.json", h.Sum64()))
+}
+
+// loadPriceBandState reads the persisted state into the moving average, discarding samples taken before the cutoff. A missing file, or
+// a file without sample times, leaves the moving average empty
+func loadPriceBandState(statePath string, average *movingAverage, cutoff time.Time) error {
+	data, e := ioutil.ReadFile(statePath)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return nil
+		}
+		return fmt.Errorf("could not read file: %s", e)
+	}
+
+	var state priceBandState
+	e = json.Unmarshal(data, &state)
+	if e != nil {
+		return fmt.Errorf("could not unmarshal state: %s", e)
+	}
+
+	if state.LastSampleTime == 0 || len(state.SampleTimes) != len(state.Samples) {
+		log.Printf("priceBandFilter: discarding state in file '%s' because it does not have the times of its samples\n", statePath)
+		return nil
+	}
+	lastSampleTime := time.Unix(state.LastSampleTime, 0)
+	if lastSampleTime.Before(cutoff) {
+		log.Printf("priceBandFilter: discarding state in file '%s' because its last sample at %s was taken before the cutoff at %s\n", statePath, lastSampleTime.UTC(), cutoff.UTC())
+		return nil
+	}
+
+	// samples are ordered with the oldest first
+	samples := []float64{}
+	sampleTimes := []time.Time{}
+	for i, sample := range state.Samples {
+		sampleTime := time.Unix(state.SampleTimes[i], 0)
+		if sampleTime.Before(cutoff) {
+			continue
+		}
+		samples = append(samples, sample)
+		sampleTimes = append(sampleTimes, sampleTime)
+	}
+	if len(samples) > average.numSamples {
+		samples = samples[len(samples)-average.numSamples:]
+		sampleTimes = sampleTimes[len(sampleTimes)-average.numSamples:]
+	}
+	if state.Count > average.numSamples {
+		state.Count = average.numSamples
+	}
+	if average.maType == movingAverageSMA {
+		state.Count = len(samples)
+	}
+
+	average.samples = samples
+	average.sampleTimes = sampleTimes
+	average.ema = state.EMA
+	average.count = state.Count
+	average.lastSampleTime = lastSampleTime
+	log.Printf("priceBandFilter: loaded %d samples of the moving average from file '%s'\n", average.count, statePath)
+	return nil
+}
+
+// savePriceBandState writes the state of the moving average to the file
+func savePriceBandState(statePath string, average *movingAverage) error {
+	sampleTimes := []int64{}
+	for _, t := range average.sampleTimes {
+		sampleTimes = append(sampleTimes, t.Unix())
+	}
+
+	data, e := json.Marshal(priceBandState{
+		Samples:        average.samples,
+		SampleTimes:    sampleTimes,
+		EMA:            average.ema,
+		Count:          average.count,
+		LastSampleTime: average.lastSampleTime.Unix(),
+	})
+	if e != nil {
+		return fmt.Errorf("could not marshal state: %s", e)
+	}
+
+	e = ioutil.WriteFile(statePath, data, 0644)
+	if e != nil {
+		return fmt.Errorf("could not write file: %s", e)
+	}
+	return nil
+}
+
+func (f *priceBandFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer) ([]txnbuild.Operation, error) {
+	feedPrice, e := f.config.feed.GetPrice()
+	if e != nil {
+		return nil, fmt.Errorf("could not get price from priceFeed: %s", e)
+	}
+	if feedPrice <= 0.0 {
+		return nil, fmt.Errorf("invalid price from priceFeed: %.10f", feedPrice)
+	}
+
+	f.average.add(feedPrice, f.now())
+	if f.statePath != "" {
+		e = savePriceBandState(f.statePath, f.average)
+		if e != nil {
+			// the moving average is still valid in memory so we continue without persisting it
+			log.Printf("priceBandFilter: could not save state to file '%s', continuing: %s\n", f.statePath, e)
+		}
+	}
+
+	average := f.average.value()
+	lowerPrice := average * (1 - f.config.MaxDeviation)
+	upperPrice := average * (1 + f.config.MaxDeviation)
+	log.Printf("priceBandFilter: feedPrice=%.10f, %s=%.10f (%d samples), band=[%.10f, %.10f]\n", feedPrice, f.config.MovingAverage, average, f.average.count, lowerPrice, upperPrice)
+
+	fn := func(op *txnbuild.ManageSellOffer) (*txnbuild.ManageSellOffer, error) {
+		return f.priceBandFilterFn(lowerPrice, upperPrice, op)
+	}
+	ops, e = filterOps(f.name, f.baseAsset, f.quoteAsset, sellingOffers, buyingOffers, ops, fn)
+	if e != nil {
+		return nil, fmt.Errorf("could not apply filter: %s", e)
+	}
+	return ops, nil
+}
+
+func (f *priceBandFilter) priceBandFilterFn(lowerPrice float64, upperPrice float64, op *txnbuild.ManageSellOffer) (*txnbuild.ManageSellOffer, error) {
+	isSell, e := utils.IsSelling(f.baseAsset, f.quoteAsset, op.Selling, op.Buying)
+	if e != nil {
+		return nil, fmt.Errorf("error when running the isSelling check for offer '%+v': %s", *op, e)
+	}
+
+	sellPrice, e := strconv.ParseFloat(op.Price, 64)
+	if e != nil {
+		return nil, fmt.Errorf("could not convert price (%s) to float: %s", op.Price, e)
+	}
+
+	// reorient price to be in the context of the bot's base and quote asset, in quote units
+	price := sellPrice
+	if !isSell {
+		// invert price for buy side
+		price = 1 / sellPrice
+	}
+
+	if price < lowerPrice || price > upperPrice {
+		log.Printf("priceBandFilter: dropping op because price=%.10f is outside the band [%.10f, %.10f] (isSell=%v)\n", price, lowerPrice, upperPrice, isSell)
+		return nil, nil
+	}
+	return op, nil
+}
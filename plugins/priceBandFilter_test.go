@@ -0,0 +1,149 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/support/utils"
+)
+
+func TestMovingAverage(t *testing.T) {
+	testCases := []struct {
+		maType     movingAverageType
+		numSamples int
+		samples    []float64
+		wantValues []float64
+	}{
+		{
+			maType:     movingAverageSMA,
+			numSamples: 3,
+			samples:    []float64{1.0, 2.0, 3.0, 4.0, 8.0},
+			wantValues: []float64{1.0, 1.5, 2.0, 3.0, 5.0},
+		}, {
+			// smoothing factor is 2/(3+1) = 0.5
+			maType:     movingAverageEMA,
+			numSamples: 3,
+			samples:    []float64{1.0, 2.0, 3.0, 4.0, 8.0},
+			wantValues: []float64{1.0, 1.5, 2.25, 3.125, 5.5625},
+		}, {
+			maType:     movingAverageSMA,
+			numSamples: 1,
+			samples:    []float64{1.0, 2.0, 3.0},
+			wantValues: []float64{1.0, 2.0, 3.0},
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(string(k.maType), func(t *testing.T) {
+			m := &movingAverage{maType: k.maType, numSamples: k.numSamples, samples: []float64{}, sampleTimes: []time.Time{}}
+			now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+			for i, s := range k.samples {
+				m.add(s, now.Add(time.Duration(i)*time.Minute))
+				assert.InDelta(t, k.wantValues[i], m.value(), 0.0000001, "sample %d", i)
+			}
+			assert.Equal(t, k.numSamples, m.count)
+		})
+	}
+}
+
+func TestPriceBandFilter(t *testing.T) {
+	dir, e := ioutil.TempDir("", "priceBandFilter")
+	if !assert.NoError(t, e) {
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	base := utils.Asset2Asset2(testBaseAsset)
+	quote := utils.Asset2Asset2(testQuoteAsset)
+	configInput := "priceFeed/band/sma/4/0.2/fixed/1.0"
+	ops := []txnbuild.Operation{
+		makeSellOpAmtPrice(10.0, 1.05),
+		makeSellOpAmtPrice(10.0, 1.6),
+		makeBuyOpAmtPrice(10.0, 0.95),
+		makeBuyOpAmtPrice(10.0, 0.5),
+	}
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	makeFilter := func(feed *testScriptedFeed) SubmitFilter {
+		config := &PriceBandFilterConfig{MovingAverage: movingAverageSMA, NumSamples: 4, MaxDeviation: 0.2, feed: feed}
+		f, e := makeFilterPriceBand(configInput, base, quote, config, dir, 10*time.Minute, func() time.Time { return now })
+		if !assert.NoError(t, e) {
+			t.FailNow()
+		}
+		return f
+	}
+
+	// the last sample spikes to 2.0 which moves the moving average to 1.25, so the band is [1.0, 1.5] instead of [1.6, 2.4].
+	// samples are taken one minute apart
+	feed := &testScriptedFeed{prices: []float64{1.0, 1.0, 1.0, 2.0}, errors: []error{nil, nil, nil, nil}}
+	f := makeFilter(feed)
+	wantOps := [][]txnbuild.Operation{
+		{ops[0], ops[2]},
+		{ops[0], ops[2]},
+		{ops[0], ops[2]},
+		{ops[0]},
+	}
+	for i, want := range wantOps {
+		now = start.Add(time.Duration(i) * time.Minute)
+		actual, e := f.Apply(ops, []hProtocol.Offer{}, []hProtocol.Offer{})
+		if !assert.NoError(t, e, "step %d", i) {
+			return
+		}
+		assert.Equal(t, want, actual, "step %d", i)
+	}
+
+	// each restart makes a new filter with the same config which loads the persisted samples that are not older than the max age
+	restarts := []struct {
+		name    string
+		elapsed time.Duration
+		price   float64
+		wantOps []txnbuild.Operation
+	}{
+		{
+			// continues from the samples (1.0, 1.0, 2.0, 1.0) instead of starting over
+			name:    "all samples within max age",
+			elapsed: 4 * time.Minute,
+			price:   1.0,
+			wantOps: []txnbuild.Operation{ops[0]},
+		}, {
+			// the samples at 1 and 2 minutes are discarded so the samples are (2.0, 1.0, 1.3) and the band is [1.1467, 1.72]
+			name:    "some samples older than max age",
+			elapsed: 12*time.Minute + 30*time.Second,
+			price:   1.3,
+			wantOps: []txnbuild.Operation{ops[1]},
+		}, {
+			// the last sample was taken more than the max age ago so the moving average starts over
+			name:    "all samples older than max age",
+			elapsed: 30 * time.Minute,
+			price:   1.0,
+			wantOps: []txnbuild.Operation{ops[0], ops[2]},
+		},
+	}
+	for _, k := range restarts {
+		now = start.Add(k.elapsed)
+		f = makeFilter(&testScriptedFeed{prices: []float64{k.price}, errors: []error{nil}})
+		actual, e := f.Apply(ops, []hProtocol.Offer{}, []hProtocol.Offer{})
+		if !assert.NoError(t, e, k.name) {
+			return
+		}
+		assert.Equal(t, k.wantOps, actual, k.name)
+	}
+
+	// state persisted without the times of its samples is discarded because its age is unknown
+	e = ioutil.WriteFile(priceBandStatePath(dir, configInput), []byte(`{"samples":[2.0,2.0,2.0],"ema":0,"count":3}`), 0644)
+	if !assert.NoError(t, e) {
+		return
+	}
+	f = makeFilter(&testScriptedFeed{prices: []float64{1.0}, errors: []error{nil}})
+	actual, e := f.Apply(ops, []hProtocol.Offer{}, []hProtocol.Offer{})
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, []txnbuild.Operation{ops[0], ops[2]}, actual)
+}
@@ -2,6 +2,7 @@ package plugins
 
 import (
 	"fmt"
+	"strconv"
 	"strings"
 
 	"github.com/stellar/go/clients/horizonclient"
@@ -35,6 +36,28 @@ func SetPrivateSdexHack(api *horizonclient.Client, ieif *IEIF, network string) e
 
 // MakePriceFeed makes a PriceFeed
 func MakePriceFeed(feedType string, url string) (api.PriceFeed, error) {
+	return priceFeedFactory{}.makePriceFeed(feedType, url)
+}
+
+// priceFeedFactory makes price feeds, feeds that are made of other feeds make their sub-feeds with the factory they were made with
+// so that the sub-feeds of a traced feed are traced as well
+type priceFeedFactory struct {
+	trace bool
+}
+
+// makePriceFeed makes a PriceFeed, wrapped in a tracedFeed when the factory traces feeds
+func (f priceFeedFactory) makePriceFeed(feedType string, url string) (api.PriceFeed, error) {
+	feed, e := makePriceFeed(feedType, url, f)
+	if e != nil {
+		return nil, e
+	}
+	if !f.trace {
+		return feed, nil
+	}
+	return makeTracedFeed(feedType, url, feed), nil
+}
+
+func makePriceFeed(feedType string, url string, factory priceFeedFactory) (api.PriceFeed, error) {
 	switch feedType {
 	case "crypto":
 		return newCMCFeed(url), nil
@@ -42,6 +65,8 @@ func MakePriceFeed(feedType string, url string) (api.PriceFeed, error) {
 		return newFiatFeed(url), nil
 	case "fixed":
 		return newFixedFeed(url)
+	case "json":
+		return newJSONFeed(url)
 	case "exchange":
 		// [0] = exchangeType, [1] = base, [2] = quote, [3] = modifier (optional)
 		urlParts := strings.Split(url, "/")
@@ -72,23 +97,102 @@ func MakePriceFeed(feedType string, url string) (api.PriceFeed, error) {
 			Quote: quoteAsset,
 		}
 		tickerAPI := api.TickerAPI(exchange)
-		return newExchangeFeed(url, &tickerAPI, &tradingPair, exchangeModifier)
+		return newExchangeFeed(url, &tickerAPI, exchange, &tradingPair, exchangeModifier)
 	case "sdex":
 		sdex, e := makeSDEXFeed(url)
 		if e != nil {
 			return nil, fmt.Errorf("error occurred while making the SDEX price feed: %s", e)
 		}
 		return sdex, nil
+	case "basket":
+		basket, e := makeBasketFeed(url, factory)
+		if e != nil {
+			return nil, fmt.Errorf("error while making basket feed for weights file '%s': %s", url, e)
+		}
+		return basket, nil
+	case "trades":
+		tradesFeed, e := makeTradesFeed(url)
+		if e != nil {
+			return nil, fmt.Errorf("error occurred while making the trades price feed: %s", e)
+		}
+		return tradesFeed, nil
 	case "function":
-		fnFeed, e := makeFunctionPriceFeed(url)
+		fnFeed, e := makeFunctionPriceFeed(url, factory)
 		if e != nil {
 			return nil, fmt.Errorf("error while making function feed for URL '%s': %s", url, e)
 		}
 		return fnFeed, nil
+	case "consensus":
+		consensus, e := makeConsensusFeed(url, factory)
+		if e != nil {
+			return nil, fmt.Errorf("error while making consensus feed for URL '%s': %s", url, e)
+		}
+		return consensus, nil
+	case "cache":
+		cached, e := makeCachedFeed(url, factory)
+		if e != nil {
+			return nil, fmt.Errorf("error while making cache feed for URL '%s': %s", url, e)
+		}
+		return cached, nil
 	}
 	return nil, fmt.Errorf("unable to make price feed for feedType=%s and url=%s", feedType, url)
 }
 
+// compositeFeedSeparator separates the options and feeds in the URL of feeds that are composed of other feeds
+const compositeFeedSeparator = "|"
+
+// parseCompositeFeedURL parses the URL of a feed composed of other feeds, formatted like so:
+// key=value[,key=value]|feed_type/feed_url[|feed_type/feed_url]
+// the options section can be empty and only the allowed option keys are accepted, the feeds are made with the factory
+func parseCompositeFeedURL(url string, allowedOptions []string, factory priceFeedFactory) (map[string]float64, []api.PriceFeed, error) {
+	parts := strings.Split(url, compositeFeedSeparator)
+	if len(parts) < 2 {
+		return nil, nil, fmt.Errorf("URL needs an options section and at least one feed separated by '%s': %s", compositeFeedSeparator, url)
+	}
+
+	options := map[string]float64{}
+	if parts[0] != "" {
+		for _, option := range strings.Split(parts[0], ",") {
+			kv := strings.SplitN(option, "=", 2)
+			if len(kv) != 2 {
+				return nil, nil, fmt.Errorf("invalid option '%s', needs to be formatted as key=value", option)
+			}
+			isAllowed := false
+			for _, allowed := range allowedOptions {
+				if kv[0] == allowed {
+					isAllowed = true
+					break
+				}
+			}
+			if !isAllowed {
+				return nil, nil, fmt.Errorf("unknown option '%s', allowed options are %v", kv[0], allowedOptions)
+			}
+
+			v, e := strconv.ParseFloat(kv[1], 64)
+			if e != nil {
+				return nil, nil, fmt.Errorf("unable to parse value of option '%s': %s", kv[0], e)
+			}
+			options[kv[0]] = v
+		}
+	}
+
+	feeds := []api.PriceFeed{}
+	for _, feedSpec := range parts[1:] {
+		feedSpecParts := strings.SplitN(feedSpec, "/", 2)
+		if len(feedSpecParts) != 2 {
+			return nil, nil, fmt.Errorf("unable to correctly split into a price feed spec: %s", feedSpec)
+		}
+
+		feed, e := factory.makePriceFeed(feedSpecParts[0], feedSpecParts[1])
+		if e != nil {
+			return nil, nil, fmt.Errorf("error creating a price feed (typ='%s', url='%s'): %s", feedSpecParts[0], feedSpecParts[1], e)
+		}
+		feeds = append(feeds, feed)
+	}
+
+	return options, feeds, nil
+}
+
 // MakeFeedPair is the factory method that we expose
 func MakeFeedPair(dataTypeA, dataFeedAUrl, dataTypeB, dataFeedBUrl string) (*api.FeedPair, error) {
 	feedA, e := MakePriceFeed(dataTypeA, dataFeedAUrl)
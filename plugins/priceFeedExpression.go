@@ -0,0 +1,409 @@
+package plugins
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+	"unicode"
+
+	"github.com/stellar/kelp/api"
+)
+
+// expressionFeed is the price feed produced by parsing a function feed expression, named sub-feeds are fetched at most once per GetPrice
+type expressionFeed struct {
+	root       api.PriceFeed
+	namedFeeds map[string]*namedFeed
+	feeds      []api.PriceFeed // the feeds referenced in the expression in the order they appear
+	mutex      *sync.Mutex
+}
+
+var _ api.PriceFeed = &expressionFeed{}
+var _ compositePriceFeed = &expressionFeed{}
+
+// subFeeds impl
+func (f *expressionFeed) subFeeds() []api.PriceFeed {
+	return f.feeds
+}
+
+// GetPrice impl
+func (f *expressionFeed) GetPrice() (float64, error) {
+	f.mutex.Lock()
+	defer f.mutex.Unlock()
+
+	for _, nf := range f.namedFeeds {
+		nf.reset()
+	}
+	return f.root.GetPrice()
+}
+
+// namedFeed is a sub-feed assigned to a name in an expression, it remembers its price until reset so it can be referenced many times
+type namedFeed struct {
+	name    string
+	feed    api.PriceFeed
+	fetched bool
+	price   float64
+	e       error
+}
+
+var _ api.PriceFeed = &namedFeed{}
+
+func (f *namedFeed) reset() {
+	f.fetched = false
+	f.price = 0.0
+	f.e = nil
+}
+
+// GetPrice impl
+func (f *namedFeed) GetPrice() (float64, error) {
+	if !f.fetched {
+		f.price, f.e = f.feed.GetPrice()
+		if f.e != nil {
+			f.e = fmt.Errorf("error fetching price from named feed '%s': %s", f.name, f.e)
+		}
+		f.fetched = true
+	}
+	return f.price, f.e
+}
+
+// expressionParser is a recursive-descent parser for the function feed grammar:
+//
+//	program    := (name '=' expr ';')* expr
+//	expr       := term (('+' | '-') term)*
+//	term       := unary (('*' | '/') unary)*
+//	unary      := '-' unary | primary
+//	primary    := number | '(' expr ')' | fnName '(' expr (',' expr)* ')' | feedType '/' feedURL | name
+//
+// a feedURL extends until whitespace, ',' or ';' or an unbalanced ')' so operators that follow a feed spec need to be separated by a space
+type expressionParser struct {
+	input      string
+	pos        int
+	factory    priceFeedFactory
+	namedFeeds map[string]*namedFeed
+	feeds      []api.PriceFeed
+}
+
+func parseFeedExpression(expression string, factory priceFeedFactory) (api.PriceFeed, error) {
+	p := &expressionParser{
+		input:      expression,
+		pos:        0,
+		factory:    factory,
+		namedFeeds: map[string]*namedFeed{},
+		feeds:      []api.PriceFeed{},
+	}
+
+	root, e := p.parseProgram()
+	if e != nil {
+		return nil, e
+	}
+
+	return &expressionFeed{
+		root:       root,
+		namedFeeds: p.namedFeeds,
+		feeds:      p.feeds,
+		mutex:      &sync.Mutex{},
+	}, nil
+}
+
+func (p *expressionParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("error at position %d of expression '%s': %s", p.pos, p.input, fmt.Sprintf(format, args...))
+}
+
+func (p *expressionParser) skipSpaces() {
+	for p.pos < len(p.input) && unicode.IsSpace(rune(p.input[p.pos])) {
+		p.pos++
+	}
+}
+
+// peek returns the next non-space character or 0 at the end of the input
+func (p *expressionParser) peek() byte {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	return p.input[p.pos]
+}
+
+func (p *expressionParser) expect(c byte) error {
+	if p.peek() != c {
+		return p.errorf("expected '%c'", c)
+	}
+	p.pos++
+	return nil
+}
+
+func isIdentChar(c byte, first bool) bool {
+	if c == '_' || (c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') {
+		return true
+	}
+	return !first && c >= '0' && c <= '9'
+}
+
+// readIdent reads an identifier starting at the current position, returns "" if there is none
+func (p *expressionParser) readIdent() string {
+	p.skipSpaces()
+	start := p.pos
+	for p.pos < len(p.input) && isIdentChar(p.input[p.pos], p.pos == start) {
+		p.pos++
+	}
+	return p.input[start:p.pos]
+}
+
+func (p *expressionParser) parseProgram() (api.PriceFeed, error) {
+	for {
+		// an assignment is an identifier followed by a single '=', anything else is the final expression
+		start := p.pos
+		name := p.readIdent()
+		if name != "" && p.peek() == '=' {
+			p.pos++
+			if _, exists := p.namedFeeds[name]; exists {
+				return nil, p.errorf("named feed '%s' is assigned more than once", name)
+			}
+			if _, isFn := fnFactoryMap[name]; isFn {
+				return nil, p.errorf("cannot use the function name '%s' as the name of a feed", name)
+			}
+
+			feed, e := p.parseExpr()
+			if e != nil {
+				return nil, e
+			}
+			if e = p.expect(';'); e != nil {
+				return nil, e
+			}
+			p.namedFeeds[name] = &namedFeed{name: name, feed: feed}
+			continue
+		}
+		p.pos = start
+
+		feed, e := p.parseExpr()
+		if e != nil {
+			return nil, e
+		}
+		if p.peek() != 0 {
+			return nil, p.errorf("unexpected trailing input '%s'", p.input[p.pos:])
+		}
+		return feed, nil
+	}
+}
+
+func (p *expressionParser) parseExpr() (api.PriceFeed, error) {
+	left, e := p.parseTerm()
+	if e != nil {
+		return nil, e
+	}
+
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return left, nil
+		}
+		p.pos++
+
+		right, e := p.parseTerm()
+		if e != nil {
+			return nil, e
+		}
+		left = makeBinaryOpFeed(op, left, right)
+	}
+}
+
+func (p *expressionParser) parseTerm() (api.PriceFeed, error) {
+	left, e := p.parseUnary()
+	if e != nil {
+		return nil, e
+	}
+
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return left, nil
+		}
+		p.pos++
+
+		right, e := p.parseUnary()
+		if e != nil {
+			return nil, e
+		}
+		left = makeBinaryOpFeed(op, left, right)
+	}
+}
+
+func (p *expressionParser) parseUnary() (api.PriceFeed, error) {
+	if p.peek() == '-' {
+		p.pos++
+		inner, e := p.parseUnary()
+		if e != nil {
+			return nil, e
+		}
+		return makeFunctionFeed(func() (float64, error) {
+			v, e := inner.GetPrice()
+			if e != nil {
+				return 0.0, e
+			}
+			return -v, nil
+		}), nil
+	}
+	return p.parsePrimary()
+}
+
+func (p *expressionParser) parsePrimary() (api.PriceFeed, error) {
+	c := p.peek()
+	switch {
+	case c == 0:
+		return nil, p.errorf("unexpected end of expression")
+	case c == '(':
+		p.pos++
+		inner, e := p.parseExpr()
+		if e != nil {
+			return nil, e
+		}
+		if e = p.expect(')'); e != nil {
+			return nil, e
+		}
+		return inner, nil
+	case (c >= '0' && c <= '9') || c == '.':
+		return p.parseNumber()
+	case isIdentChar(c, true):
+		return p.parseIdentPrimary()
+	default:
+		return nil, p.errorf("unexpected character '%c'", c)
+	}
+}
+
+func (p *expressionParser) parseNumber() (api.PriceFeed, error) {
+	start := p.pos
+	for p.pos < len(p.input) && ((p.input[p.pos] >= '0' && p.input[p.pos] <= '9') || p.input[p.pos] == '.') {
+		p.pos++
+	}
+
+	v, e := strconv.ParseFloat(p.input[start:p.pos], 64)
+	if e != nil {
+		return nil, p.errorf("unable to parse number '%s': %s", p.input[start:p.pos], e)
+	}
+	return makeFunctionFeed(func() (float64, error) {
+		return v, nil
+	}), nil
+}
+
+func (p *expressionParser) parseIdentPrimary() (api.PriceFeed, error) {
+	identStart := p.pos
+	name := p.readIdent()
+
+	// the next character must be checked without skipping spaces because a feed spec or function call is not allowed to have any
+	next := byte(0)
+	if p.pos < len(p.input) {
+		next = p.input[p.pos]
+	}
+
+	switch next {
+	case '(':
+		return p.parseFunctionCall(name)
+	case '/':
+		p.pos++
+		return p.parseFeedSpec(name)
+	}
+
+	nf, ok := p.namedFeeds[name]
+	if !ok {
+		p.pos = identStart
+		return nil, p.errorf("unknown named feed '%s'", name)
+	}
+	return nf, nil
+}
+
+func (p *expressionParser) parseFunctionCall(name string) (api.PriceFeed, error) {
+	f, ok := fnFactoryMap[name]
+	if !ok {
+		return nil, p.errorf("the expression does not have the registered function '%s'", name)
+	}
+	// consume '('
+	p.pos++
+
+	args := []api.PriceFeed{}
+	if p.peek() != ')' {
+		for {
+			arg, e := p.parseExpr()
+			if e != nil {
+				return nil, e
+			}
+			args = append(args, arg)
+
+			if p.peek() != ',' {
+				break
+			}
+			p.pos++
+		}
+	}
+	if e := p.expect(')'); e != nil {
+		return nil, e
+	}
+
+	pf, e := f(args)
+	if e != nil {
+		return nil, fmt.Errorf("error when invoking price feed function '%s': %s", name, e)
+	}
+	return pf, nil
+}
+
+func (p *expressionParser) parseFeedSpec(feedType string) (api.PriceFeed, error) {
+	start := p.pos
+	depth := 0
+	for p.pos < len(p.input) {
+		c := p.input[p.pos]
+		if unicode.IsSpace(rune(c)) || c == ',' || c == ';' {
+			if depth == 0 {
+				break
+			}
+		} else if c == '(' {
+			depth++
+		} else if c == ')' {
+			if depth == 0 {
+				break
+			}
+			depth--
+		}
+		p.pos++
+	}
+
+	feedURL := strings.TrimSpace(p.input[start:p.pos])
+	if feedURL == "" {
+		return nil, p.errorf("missing URL for feed of type '%s'", feedType)
+	}
+
+	feed, e := p.factory.makePriceFeed(feedType, feedURL)
+	if e != nil {
+		return nil, fmt.Errorf("error creating a price feed (typ='%s', url='%s'): %s", feedType, feedURL, e)
+	}
+	p.feeds = append(p.feeds, feed)
+	return feed, nil
+}
+
+// makeBinaryOpFeed combines two feeds with an arithmetic operator
+func makeBinaryOpFeed(op byte, left api.PriceFeed, right api.PriceFeed) api.PriceFeed {
+	return makeFunctionFeed(func() (float64, error) {
+		l, e := left.GetPrice()
+		if e != nil {
+			return 0.0, e
+		}
+		r, e := right.GetPrice()
+		if e != nil {
+			return 0.0, e
+		}
+
+		switch op {
+		case '+':
+			return l + r, nil
+		case '-':
+			return l - r, nil
+		case '*':
+			return l * r, nil
+		case '/':
+			if r == 0.0 {
+				return 0.0, fmt.Errorf("division by zero (%.10f / %.10f)", l, r)
+			}
+			return l / r, nil
+		default:
+			return 0.0, fmt.Errorf("unknown operator '%c' (programmer error)", op)
+		}
+	})
+}
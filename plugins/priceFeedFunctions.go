@@ -10,9 +10,30 @@ type fnFactory func(feeds []api.PriceFeed) (api.PriceFeed, error)
 
 var fnFactoryMap = map[string]fnFactory{
 	"max":    max,
+	"min":    min,
+	"median": median,
+	"avg":    avg,
+	"wavg":   wavg,
 	"invert": invert,
 }
 
+// fetchInnerPrices fetches the prices of all the feeds passed in to the function with the given name, all prices need to be > 0.0
+func fetchInnerPrices(fnName string, feeds []api.PriceFeed) ([]float64, error) {
+	prices := []float64{}
+	for i, f := range feeds {
+		innerPrice, e := f.GetPrice()
+		if e != nil {
+			return nil, fmt.Errorf("error fetching price from feed (index=%d) in '%s' function feed: %s", i, fnName, e)
+		}
+
+		if innerPrice <= 0.0 {
+			return nil, fmt.Errorf("inner price of feed at index %d was <= 0.0 (%.10f)", i, innerPrice)
+		}
+		prices = append(prices, innerPrice)
+	}
+	return prices, nil
+}
+
 func max(feeds []api.PriceFeed) (api.PriceFeed, error) {
 	if len(feeds) < 2 {
 		return nil, fmt.Errorf("need to provide at least 2 price feeds to the 'max' price feed function but found only %d price feeds", len(feeds))
@@ -38,6 +59,98 @@ func max(feeds []api.PriceFeed) (api.PriceFeed, error) {
 	}), nil
 }
 
+func min(feeds []api.PriceFeed) (api.PriceFeed, error) {
+	if len(feeds) < 2 {
+		return nil, fmt.Errorf("need to provide at least 2 price feeds to the 'min' price feed function but found only %d price feeds", len(feeds))
+	}
+
+	return makeFunctionFeed(func() (float64, error) {
+		prices, e := fetchInnerPrices("min", feeds)
+		if e != nil {
+			return 0.0, e
+		}
+
+		min := prices[0]
+		for _, p := range prices[1:] {
+			if p < min {
+				min = p
+			}
+		}
+		return min, nil
+	}), nil
+}
+
+func median(feeds []api.PriceFeed) (api.PriceFeed, error) {
+	if len(feeds) < 2 {
+		return nil, fmt.Errorf("need to provide at least 2 price feeds to the 'median' price feed function but found only %d price feeds", len(feeds))
+	}
+
+	return makeFunctionFeed(func() (float64, error) {
+		prices, e := fetchInnerPrices("median", feeds)
+		if e != nil {
+			return 0.0, e
+		}
+
+		return medianOf(prices), nil
+	}), nil
+}
+
+func avg(feeds []api.PriceFeed) (api.PriceFeed, error) {
+	if len(feeds) < 2 {
+		return nil, fmt.Errorf("need to provide at least 2 price feeds to the 'avg' price feed function but found only %d price feeds", len(feeds))
+	}
+
+	return makeFunctionFeed(func() (float64, error) {
+		prices, e := fetchInnerPrices("avg", feeds)
+		if e != nil {
+			return 0.0, e
+		}
+
+		sum := 0.0
+		for _, p := range prices {
+			sum += p
+		}
+		return sum / float64(len(prices)), nil
+	}), nil
+}
+
+// wavg takes pairs of (weight, feed) arguments, the weights are usually constants but can be any price feed
+func wavg(feeds []api.PriceFeed) (api.PriceFeed, error) {
+	if len(feeds) < 2 || len(feeds)%2 != 0 {
+		return nil, fmt.Errorf("need to provide pairs of (weight, price feed) arguments to the 'wavg' price feed function but found %d arguments", len(feeds))
+	}
+
+	return makeFunctionFeed(func() (float64, error) {
+		weightedSum := 0.0
+		totalWeight := 0.0
+		for i := 0; i < len(feeds); i += 2 {
+			weight, e := feeds[i].GetPrice()
+			if e != nil {
+				return 0.0, fmt.Errorf("error fetching weight (index=%d) in 'wavg' function feed: %s", i, e)
+			}
+			if weight < 0.0 {
+				return 0.0, fmt.Errorf("weight at index %d was < 0.0 (%.10f)", i, weight)
+			}
+
+			innerPrice, e := feeds[i+1].GetPrice()
+			if e != nil {
+				return 0.0, fmt.Errorf("error fetching price from feed (index=%d) in 'wavg' function feed: %s", i+1, e)
+			}
+			if innerPrice <= 0.0 {
+				return 0.0, fmt.Errorf("inner price of feed at index %d was <= 0.0 (%.10f)", i+1, innerPrice)
+			}
+
+			weightedSum += weight * innerPrice
+			totalWeight += weight
+		}
+
+		if totalWeight == 0.0 {
+			return 0.0, fmt.Errorf("sum of weights in 'wavg' function feed was 0.0")
+		}
+		return weightedSum / totalWeight, nil
+	}), nil
+}
+
 func invert(feeds []api.PriceFeed) (api.PriceFeed, error) {
 	if len(feeds) != 1 {
 		return nil, fmt.Errorf("need to provide exactly 1 price feed to the 'invert' function but found %d price feeds", len(feeds))
@@ -0,0 +1,133 @@
+package plugins
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/stellar/kelp/api"
+)
+
+// PriceFeedTrace is the breakdown of a price feed and the sub-feeds it is made of, along with the result of the last time each
+// feed was queried
+type PriceFeedTrace struct {
+	FeedType string
+	URL      string
+	feed     api.PriceFeed // the traced feed, without the tracing wrapper
+
+	// uses mutex for the fields below since sub-feeds can be queried concurrently
+	mutex   *sync.Mutex
+	queried bool
+	price   float64
+	latency time.Duration
+	e       error
+}
+
+// PriceFeedTraceResult is the result of the last time a traced feed was queried
+type PriceFeedTraceResult struct {
+	Queried bool
+	Price   float64
+	Latency time.Duration
+	Error   error
+}
+
+// Result returns the result of the last time the feed was queried since the trace was reset
+func (t *PriceFeedTrace) Result() PriceFeedTraceResult {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	return PriceFeedTraceResult{
+		Queried: t.queried,
+		Price:   t.price,
+		Latency: t.latency,
+		Error:   t.e,
+	}
+}
+
+// Reset clears the results of this feed and all its sub-feeds, feeds that are not queried afterwards (such as fallbacks that were
+// not needed or feeds served from a cache) can then be told apart
+func (t *PriceFeedTrace) Reset() {
+	t.mutex.Lock()
+	t.queried = false
+	t.price = 0.0
+	t.latency = 0
+	t.e = nil
+	t.mutex.Unlock()
+
+	for _, c := range t.Children() {
+		c.Reset()
+	}
+}
+
+// Children returns the traces of the sub-feeds the feed is currently made of, this reflects the sub-feeds of feeds that are
+// rebuilt over time such as the basket feed
+func (t *PriceFeedTrace) Children() []*PriceFeedTrace {
+	children := []*PriceFeedTrace{}
+	cf, ok := t.feed.(compositePriceFeed)
+	if !ok {
+		return children
+	}
+
+	for _, sub := range cf.subFeeds() {
+		if tf, ok := sub.(*tracedFeed); ok {
+			children = append(children, tf.trace)
+		}
+	}
+	return children
+}
+
+func (t *PriceFeedTrace) record(price float64, latency time.Duration, e error) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	t.queried = true
+	t.price = price
+	t.latency = latency
+	t.e = e
+}
+
+// tracedFeed records the result of every call to the wrapped feed in its trace
+type tracedFeed struct {
+	feed  api.PriceFeed
+	trace *PriceFeedTrace
+}
+
+// ensure that it implements PriceFeed
+var _ api.PriceFeed = &tracedFeed{}
+
+// GetPrice impl
+func (f *tracedFeed) GetPrice() (float64, error) {
+	start := time.Now()
+	price, e := f.feed.GetPrice()
+	f.trace.record(price, time.Since(start), e)
+	return price, e
+}
+
+// compositePriceFeed is implemented by price feeds that are made of other price feeds
+type compositePriceFeed interface {
+	// subFeeds returns the feeds this feed is currently made of
+	subFeeds() []api.PriceFeed
+}
+
+// makeTracedFeed wraps the feed so every call to it is recorded in a new trace
+func makeTracedFeed(feedType string, url string, feed api.PriceFeed) *tracedFeed {
+	return &tracedFeed{
+		feed: feed,
+		trace: &PriceFeedTrace{
+			FeedType: feedType,
+			URL:      url,
+			feed:     feed,
+			mutex:    &sync.Mutex{},
+		},
+	}
+}
+
+// MakeTracedPriceFeed makes a PriceFeed like MakePriceFeed and also returns the trace of the feed and every sub-feed it is made of,
+// which is updated every time the feeds are queried
+func MakeTracedPriceFeed(feedType string, url string) (api.PriceFeed, *PriceFeedTrace, error) {
+	feed, e := priceFeedFactory{trace: true}.makePriceFeed(feedType, url)
+	if e != nil {
+		return nil, nil, fmt.Errorf("unable to make traced price feed: %s", e)
+	}
+	return feed, feed.(*tracedFeed).trace, nil
+}
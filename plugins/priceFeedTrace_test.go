@@ -0,0 +1,91 @@
+package plugins
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestMakeTracedPriceFeed(t *testing.T) {
+	feed, trace, e := MakeTracedPriceFeed("function", "max(fixed/1.0,invert(fixed/4.0))")
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, "function", trace.FeedType)
+	children := trace.Children()
+	if !assert.Equal(t, 2, len(children)) {
+		return
+	}
+	assert.Equal(t, "1.0", children[0].URL)
+	assert.Equal(t, "4.0", children[1].URL)
+
+	assert.False(t, trace.Result().Queried)
+	price, e := feed.GetPrice()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, 1.0, price)
+	assert.Equal(t, 1.0, trace.Result().Price)
+	assert.Equal(t, 4.0, children[1].Result().Price)
+
+	trace.Reset()
+	assert.False(t, children[0].Result().Queried)
+
+	// feeds made outside of MakeTracedPriceFeed are not traced
+	untraced, e := MakePriceFeed("fixed", "1.0")
+	if !assert.NoError(t, e) {
+		return
+	}
+	_, isTraced := untraced.(*tracedFeed)
+	assert.False(t, isTraced)
+}
+
+func TestMakeTracedPriceFeedBasketReload(t *testing.T) {
+	dir, e := ioutil.TempDir("", "tracedBasketFeed")
+	if !assert.NoError(t, e) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	path := dir + "/basket.cfg"
+	modTime := time.Unix(1600000000, 0)
+
+	writeTestBasketFile(t, path, `
+[[COMPONENTS]]
+NAME="USD"
+FEED_TYPE="fixed"
+FEED_URL="1.0"
+WEIGHT=1.0
+`, modTime)
+	feed, trace, e := MakeTracedPriceFeed("basket", path)
+	if !assert.NoError(t, e) {
+		return
+	}
+	if !assert.Equal(t, 1, len(trace.Children())) {
+		return
+	}
+	assert.Equal(t, "1.0", trace.Children()[0].URL)
+
+	// the components of the reloaded weights file are traced and replace the previous components
+	writeTestBasketFile(t, path, `
+[[COMPONENTS]]
+NAME="EUR"
+FEED_TYPE="fixed"
+FEED_URL="1.2"
+WEIGHT=1.0
+`, modTime.Add(time.Minute))
+	trace.Reset()
+	price, e := feed.GetPrice()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.InDelta(t, 1.2, price, 0.0000001)
+	children := trace.Children()
+	if !assert.Equal(t, 1, len(children)) {
+		return
+	}
+	assert.Equal(t, "1.2", children[0].URL)
+	assert.True(t, children[0].Result().Queried)
+}
@@ -0,0 +1,216 @@
+package plugins
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/stellar/kelp/api"
+	"github.com/stellar/kelp/model"
+)
+
+// RandomizeConfig contains the bounds used to randomize the levels of a strategy so our offers are harder to identify.
+// Randomization is disabled when this config is not specified
+type RandomizeConfig struct {
+	AmountFraction    float64 `valid:"-" toml:"AMOUNT_FRACTION" json:"amount_fraction"`         // max fraction of the amount of a level that is moved to or from the next level
+	MaxExtraSpread    float64 `valid:"-" toml:"MAX_EXTRA_SPREAD" json:"max_extra_spread"`       // max spread added to each level, as a fraction of its price
+	MinRefreshSeconds int64   `valid:"-" toml:"MIN_REFRESH_SECONDS" json:"min_refresh_seconds"` // min time before the randomization of a level changes
+	MaxRefreshSeconds int64   `valid:"-" toml:"MAX_REFRESH_SECONDS" json:"max_refresh_seconds"` // max time before the randomization of a level changes
+}
+
+// String impl.
+func (c RandomizeConfig) String() string {
+	return fmt.Sprintf("RandomizeConfig[AmountFraction=%.4f, MaxExtraSpread=%.4f, MinRefreshSeconds=%d, MaxRefreshSeconds=%d]",
+		c.AmountFraction, c.MaxExtraSpread, c.MinRefreshSeconds, c.MaxRefreshSeconds)
+}
+
+// levelJitter is the randomization applied to a level until it is refreshed
+type levelJitter struct {
+	amountShift float64 // fraction of the smaller of the amounts of this level and the next level that is moved to the next level, can be negative
+	extraSpread float64
+	refreshAt   time.Time
+}
+
+// randomizedLevelProvider wraps a level provider and randomizes the amounts and prices of its levels within the configured bounds.
+// Amounts are only moved between neighbouring levels so the total amount across all levels stays the same, and prices are only moved
+// away from the mid price so spreads are never tighter than the inner level provider's. Each level keeps its randomization for a random
+// time so the levels are not all refreshed together
+type randomizedLevelProvider struct {
+	inner            api.LevelProvider
+	config           *RandomizeConfig
+	orderConstraints *model.OrderConstraints
+	isBuySide        bool
+	random           *rand.Rand
+	now              func() time.Time
+
+	// uninitialized runtime vars
+	jitters []levelJitter
+}
+
+// ensure it implements the LevelProvider interface
+var _ api.LevelProvider = &randomizedLevelProvider{}
+
+// makeRandomizedLevelProvider is a factory method
+func makeRandomizedLevelProvider(
+	inner api.LevelProvider,
+	config *RandomizeConfig,
+	orderConstraints *model.OrderConstraints,
+	isBuySide bool,
+) (api.LevelProvider, error) {
+	p, e := makeRandomizedLevelProviderWithRandom(inner, config, orderConstraints, isBuySide, rand.New(rand.NewSource(time.Now().UnixNano())), time.Now)
+	if e != nil {
+		return nil, e
+	}
+	return p, nil
+}
+
+func makeRandomizedLevelProviderWithRandom(
+	inner api.LevelProvider,
+	config *RandomizeConfig,
+	orderConstraints *model.OrderConstraints,
+	isBuySide bool,
+	random *rand.Rand,
+	now func() time.Time,
+) (*randomizedLevelProvider, error) {
+	if config.AmountFraction < 0.0 || config.AmountFraction > 1.0 {
+		return nil, fmt.Errorf("AMOUNT_FRACTION needs to be between 0.0 and 1.0 inclusive, was %.4f", config.AmountFraction)
+	}
+	if config.MaxExtraSpread < 0.0 || config.MaxExtraSpread >= 1.0 {
+		return nil, fmt.Errorf("MAX_EXTRA_SPREAD needs to be >= 0.0 and < 1.0, was %.4f", config.MaxExtraSpread)
+	}
+	// a level that is refreshed on every update would replace our offers on every update and burn fees
+	if config.MaxRefreshSeconds <= 0 {
+		return nil, fmt.Errorf("MAX_REFRESH_SECONDS needs to be > 0, was %d", config.MaxRefreshSeconds)
+	}
+	if config.MinRefreshSeconds < 0 || config.MinRefreshSeconds > config.MaxRefreshSeconds {
+		return nil, fmt.Errorf("MIN_REFRESH_SECONDS (%d) needs to be >= 0 and <= MAX_REFRESH_SECONDS (%d)", config.MinRefreshSeconds, config.MaxRefreshSeconds)
+	}
+
+	return &randomizedLevelProvider{
+		inner:            inner,
+		config:           config,
+		orderConstraints: orderConstraints,
+		isBuySide:        isBuySide,
+		random:           random,
+		now:              now,
+		jitters:          []levelJitter{},
+	}, nil
+}
+
+// maybeRandomizeLevelProvider wraps the level provider in a randomizedLevelProvider when there is a randomize config
+func maybeRandomizeLevelProvider(
+	levelProvider api.LevelProvider,
+	config *RandomizeConfig,
+	orderConstraints *model.OrderConstraints,
+	isBuySide bool,
+) (api.LevelProvider, error) {
+	if config == nil {
+		return levelProvider, nil
+	}
+	return makeRandomizedLevelProvider(levelProvider, config, orderConstraints, isBuySide)
+}
+
+// GetLevels impl.
+func (p *randomizedLevelProvider) GetLevels(maxAssetBase float64, maxAssetQuote float64) ([]api.Level, error) {
+	levels, e := p.inner.GetLevels(maxAssetBase, maxAssetQuote)
+	if e != nil {
+		return nil, fmt.Errorf("could not get levels from inner level provider: %s", e)
+	}
+
+	p.refreshJitters(len(levels))
+	return p.randomize(levels), nil
+}
+
+// GetFillHandlers impl
+func (p *randomizedLevelProvider) GetFillHandlers() ([]api.FillHandler, error) {
+	return p.inner.GetFillHandlers()
+}
+
+// refreshJitters draws a new randomization for the levels that are due for a refresh
+func (p *randomizedLevelProvider) refreshJitters(numLevels int) {
+	now := p.now()
+	for len(p.jitters) < numLevels {
+		// a zero refreshAt is always due
+		p.jitters = append(p.jitters, levelJitter{})
+	}
+	p.jitters = p.jitters[:numLevels]
+
+	for i := range p.jitters {
+		if now.Before(p.jitters[i].refreshAt) {
+			continue
+		}
+
+		refreshSeconds := float64(p.config.MinRefreshSeconds) + p.random.Float64()*float64(p.config.MaxRefreshSeconds-p.config.MinRefreshSeconds)
+		p.jitters[i] = levelJitter{
+			amountShift: (2*p.random.Float64() - 1) * p.config.AmountFraction,
+			extraSpread: p.random.Float64() * p.config.MaxExtraSpread,
+			refreshAt:   now.Add(time.Duration(refreshSeconds * float64(time.Second))),
+		}
+	}
+}
+
+// randomize applies the current randomization to the levels
+func (p *randomizedLevelProvider) randomize(levels []api.Level) []api.Level {
+	if len(levels) == 0 {
+		return levels
+	}
+
+	// levels are always in the context of selling so adding spread moves the price away from the mid price on both sides
+	prices := []float64{}
+	amounts := []float64{}
+	totalAmount := 0.0
+	for i, l := range levels {
+		price := *model.NumberFromFloat(l.Price.AsFloat()*(1+p.jitters[i].extraSpread), p.orderConstraints.PricePrecision)
+		prices = append(prices, price.AsFloat())
+		amounts = append(amounts, l.Amount.AsFloat())
+		totalAmount += l.Amount.AsFloat()
+	}
+
+	for i := 0; i+1 < len(levels); i++ {
+		shift := p.jitters[i].amountShift * math.Min(amounts[i], amounts[i+1])
+		// do not shift an amount below the min volume allowed by the exchange
+		if shift > 0 {
+			shift = math.Min(shift, math.Max(0, amounts[i]-p.minAmount(prices[i])))
+		} else {
+			shift = math.Max(shift, -math.Max(0, amounts[i+1]-p.minAmount(prices[i+1])))
+		}
+		amounts[i] -= shift
+		amounts[i+1] += shift
+	}
+
+	randomizedLevels := []api.Level{}
+	remainingAmount := totalAmount
+	for i := range levels {
+		amount := model.NumberFromFloat(amounts[i], p.orderConstraints.VolumePrecision)
+		if i == len(levels)-1 {
+			// the last level absorbs any rounding so the total amount does not change
+			amount = model.NumberFromFloat(remainingAmount, p.orderConstraints.VolumePrecision)
+		}
+		remainingAmount -= amount.AsFloat()
+
+		randomizedLevels = append(randomizedLevels, api.Level{
+			Price:  *model.NumberFromFloat(prices[i], p.orderConstraints.PricePrecision),
+			Amount: *amount,
+		})
+	}
+	return randomizedLevels
+}
+
+// minAmount is the smallest amount of a level at the given price that satisfies the order constraints. On the buy side the levels are
+// in units of the quote asset and their price is in units of the base asset per unit of the quote asset
+func (p *randomizedLevelProvider) minAmount(price float64) float64 {
+	minBase := p.orderConstraints.MinBaseVolume.AsFloat()
+	minQuote := 0.0
+	if p.orderConstraints.MinQuoteVolume != nil {
+		minQuote = p.orderConstraints.MinQuoteVolume.AsFloat()
+	}
+
+	if price <= 0.0 {
+		return math.Max(minBase, minQuote)
+	}
+	if p.isBuySide {
+		return math.Max(minBase/price, minQuote)
+	}
+	return math.Max(minBase, minQuote/price)
+}
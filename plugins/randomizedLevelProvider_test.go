@@ -0,0 +1,202 @@
+package plugins
+
+import (
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/kelp/api"
+	"github.com/stellar/kelp/model"
+)
+
+// fixedLevelProvider always returns the same levels
+type fixedLevelProvider struct {
+	levels []api.Level
+}
+
+func (p *fixedLevelProvider) GetLevels(maxAssetBase float64, maxAssetQuote float64) ([]api.Level, error) {
+	return p.levels, nil
+}
+
+func (p *fixedLevelProvider) GetFillHandlers() ([]api.FillHandler, error) {
+	return nil, nil
+}
+
+func TestRandomizedLevelProvider(t *testing.T) {
+	orderConstraints := model.MakeOrderConstraints(7, 7, 1.0)
+	innerLevels := []api.Level{
+		{Price: *model.NumberFromFloat(1.0010, 7), Amount: *model.NumberFromFloat(100.0, 7)},
+		{Price: *model.NumberFromFloat(1.0015, 7), Amount: *model.NumberFromFloat(100.0, 7)},
+		// a shift from this level is bounded by the min base volume
+		{Price: *model.NumberFromFloat(1.0020, 7), Amount: *model.NumberFromFloat(2.0, 7)},
+	}
+	config := &RandomizeConfig{
+		AmountFraction:    0.5,
+		MaxExtraSpread:    0.001,
+		MinRefreshSeconds: 60,
+		MaxRefreshSeconds: 120,
+	}
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	p, e := makeRandomizedLevelProviderWithRandom(
+		&fixedLevelProvider{levels: innerLevels},
+		config,
+		orderConstraints,
+		false,
+		rand.New(rand.NewSource(1)),
+		func() time.Time { return now },
+	)
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	var lastLevels []api.Level
+	for i := 0; i < 20; i++ {
+		levels, e := p.GetLevels(1000.0, 1000.0)
+		if !assert.NoError(t, e) {
+			return
+		}
+		if !assert.Equal(t, len(innerLevels), len(levels)) {
+			return
+		}
+
+		totalAmount := 0.0
+		for j, l := range levels {
+			totalAmount += l.Amount.AsFloat()
+			assert.True(t, l.Amount.AsFloat() >= 1.0, "amount of level %d was below the min base volume: %s", j, l.Amount.AsString())
+			assert.True(t, l.Price.AsFloat() >= innerLevels[j].Price.AsFloat(), "price of level %d was tighter than the inner level: %s", j, l.Price.AsString())
+			assert.True(t, l.Price.AsFloat() <= innerLevels[j].Price.AsFloat()*1.001+0.0000001, "price of level %d was wider than the max extra spread: %s", j, l.Price.AsString())
+		}
+		assert.InDelta(t, 202.0, totalAmount, 0.0000001)
+
+		// levels are not refreshed before the min refresh time
+		if lastLevels != nil && i%2 == 1 {
+			assert.Equal(t, lastLevels, levels)
+		}
+		lastLevels = levels
+
+		if i%2 == 0 {
+			now = now.Add(30 * time.Second)
+		} else {
+			now = now.Add(2 * time.Minute)
+		}
+	}
+}
+
+func TestRandomizedLevelProviderRefresh(t *testing.T) {
+	innerLevels := []api.Level{
+		{Price: *model.NumberFromFloat(1.0010, 7), Amount: *model.NumberFromFloat(100.0, 7)},
+		{Price: *model.NumberFromFloat(1.0015, 7), Amount: *model.NumberFromFloat(100.0, 7)},
+		{Price: *model.NumberFromFloat(1.0020, 7), Amount: *model.NumberFromFloat(100.0, 7)},
+	}
+	config := &RandomizeConfig{
+		AmountFraction:    0.5,
+		MaxExtraSpread:    0.001,
+		MinRefreshSeconds: 60,
+		MaxRefreshSeconds: 120,
+	}
+	start := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	p, e := makeRandomizedLevelProviderWithRandom(
+		&fixedLevelProvider{levels: innerLevels},
+		config,
+		model.MakeOrderConstraints(7, 7, 1.0),
+		false,
+		rand.New(rand.NewSource(1)),
+		func() time.Time { return now },
+	)
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	// each step advances the clock from the start by the elapsed duration before getting the levels
+	steps := []struct {
+		elapsed     time.Duration
+		wantRefresh bool
+	}{
+		{elapsed: 0, wantRefresh: true},
+		{elapsed: 1 * time.Second, wantRefresh: false},
+		{elapsed: 30 * time.Second, wantRefresh: false},
+		{elapsed: 59 * time.Second, wantRefresh: false},
+		// every level is due once the max refresh time has passed
+		{elapsed: 121 * time.Second, wantRefresh: true},
+		{elapsed: 122 * time.Second, wantRefresh: false},
+		{elapsed: 180 * time.Second, wantRefresh: false},
+		{elapsed: 242 * time.Second, wantRefresh: true},
+	}
+
+	var lastLevels []api.Level
+	var lastRefreshAts []time.Time
+	for i, s := range steps {
+		now = start.Add(s.elapsed)
+		levels, e := p.GetLevels(1000.0, 1000.0)
+		if !assert.NoError(t, e, "step %d", i) {
+			return
+		}
+
+		refreshAts := []time.Time{}
+		for j, jitter := range p.jitters {
+			refreshAts = append(refreshAts, jitter.refreshAt)
+			if s.wantRefresh {
+				// the next refresh of each level is drawn between the min and max refresh times
+				assert.False(t, jitter.refreshAt.Before(now.Add(60*time.Second)), "step %d, level %d refreshes too early: %s", i, j, jitter.refreshAt)
+				assert.False(t, jitter.refreshAt.After(now.Add(120*time.Second)), "step %d, level %d refreshes too late: %s", i, j, jitter.refreshAt)
+			}
+		}
+
+		if !s.wantRefresh {
+			assert.Equal(t, lastLevels, levels, "step %d", i)
+			assert.Equal(t, lastRefreshAts, refreshAts, "step %d", i)
+		}
+		lastLevels = levels
+		lastRefreshAts = refreshAts
+	}
+}
+
+func TestMakeRandomizedLevelProvider(t *testing.T) {
+	testCases := []struct {
+		name    string
+		config  *RandomizeConfig
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			config: &RandomizeConfig{AmountFraction: 0.2, MaxExtraSpread: 0.001, MinRefreshSeconds: 30, MaxRefreshSeconds: 300},
+		}, {
+			name:   "refresh any time within max refresh",
+			config: &RandomizeConfig{AmountFraction: 0.2, MaxRefreshSeconds: 60},
+		}, {
+			name:    "missing max refresh",
+			config:  &RandomizeConfig{AmountFraction: 0.2},
+			wantErr: true,
+		}, {
+			name:    "negative amount fraction",
+			config:  &RandomizeConfig{AmountFraction: -0.1},
+			wantErr: true,
+		}, {
+			name:    "amount fraction above 1",
+			config:  &RandomizeConfig{AmountFraction: 1.1},
+			wantErr: true,
+		}, {
+			name:    "extra spread of 1",
+			config:  &RandomizeConfig{MaxExtraSpread: 1.0},
+			wantErr: true,
+		}, {
+			name:    "min refresh above max refresh",
+			config:  &RandomizeConfig{MinRefreshSeconds: 300, MaxRefreshSeconds: 30},
+			wantErr: true,
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			_, e := makeRandomizedLevelProvider(&fixedLevelProvider{}, k.config, model.MakeOrderConstraints(7, 7, 1.0), false)
+			if k.wantErr {
+				assert.Error(t, e)
+			} else {
+				assert.NoError(t, e)
+			}
+		})
+	}
+}
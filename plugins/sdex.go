@@ -1,6 +1,7 @@
 package plugins
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"math"
@@ -38,9 +39,8 @@ type SDEX struct {
 	API                           *horizonclient.Client
 	SourceAccount                 string
 	TradingAccount                string
-	SourceSeed                    string
-	TradingSeed                   string
 	Network                       string
+	signer                        api.Signer
 	threadTracker                 *multithreading.ThreadTracker
 	operationalBuffer             float64
 	operationalBufferNonNativePct float64
@@ -49,10 +49,15 @@ type SDEX struct {
 	assetMap                      map[model.Asset]hProtocol.Asset // this is needed until we fully address putting SDEX behind the Exchange interface
 	opFeeStroopsFn                OpFeeStroops
 	tradingOnSdex                 bool
+	useManageBuyOffer             bool
 
 	// uninitialized
 	seqNum             uint64
 	reloadSeqNum       bool
+	channels           *channelPool     // nil when not using channel accounts
+	feeBumpMaxOpFee    uint64           // 0 when fee bumping is disabled
+	feeBudget          *feeBudget       // nil when there is no daily fee budget
+	onSubmit           func(numOps int) // nil when submitted transactions are not tracked
 	ieif               *IEIF
 	ocOverridesHandler *OrderConstraintsOverridesHandler
 }
@@ -75,8 +80,7 @@ func MakeSDEX(
 	api *horizonclient.Client,
 	ieif *IEIF,
 	exchangeShim api.ExchangeShim,
-	sourceSeed string,
-	tradingSeed string,
+	signer api.Signer,
 	sourceAccount string,
 	tradingAccount string,
 	network string,
@@ -87,15 +91,15 @@ func MakeSDEX(
 	pair *model.TradingPair,
 	assetMap map[model.Asset]hProtocol.Asset,
 	opFeeStroopsFn OpFeeStroops,
+	useManageBuyOffer bool,
 ) *SDEX {
 	sdex := &SDEX{
 		API:                           api,
 		ieif:                          ieif,
-		SourceSeed:                    sourceSeed,
-		TradingSeed:                   tradingSeed,
 		SourceAccount:                 sourceAccount,
 		TradingAccount:                tradingAccount,
 		Network:                       network,
+		signer:                        signer,
 		threadTracker:                 threadTracker,
 		operationalBuffer:             operationalBuffer,
 		operationalBufferNonNativePct: operationalBufferNonNativePct,
@@ -104,6 +108,7 @@ func MakeSDEX(
 		assetMap:                      assetMap,
 		opFeeStroopsFn:                opFeeStroopsFn,
 		tradingOnSdex:                 exchangeShim == nil,
+		useManageBuyOffer:             useManageBuyOffer,
 		ocOverridesHandler:            MakeEmptyOrderConstraintsOverridesHandler(),
 	}
 
@@ -117,7 +122,6 @@ func MakeSDEX(
 
 	if sdex.SourceAccount == "" {
 		sdex.SourceAccount = sdex.TradingAccount
-		sdex.SourceSeed = sdex.TradingSeed
 		log.Println("No Source Account Set")
 	}
 	sdex.reloadSeqNum = true
@@ -125,6 +129,53 @@ func MakeSDEX(
 	return sdex
 }
 
+// UseChannelAccounts configures this instance to submit transactions using the passed in channel account addresses as the transaction source
+// account, which allows multiple transactions to be submitted concurrently. The signer of this instance needs to be able to sign for them
+func (sdex *SDEX) UseChannelAccounts(channelAddresses []string) error {
+	channels, e := makeChannelPool(channelAddresses)
+	if e != nil {
+		return fmt.Errorf("unable to make channel pool: %s", e)
+	}
+	sdex.channels = channels
+	log.Printf("using %d channel accounts to submit transactions\n", len(channelAddresses))
+	return nil
+}
+
+// EnableFeeBump configures this instance to resubmit transactions that time out or fail with tx_insufficient_fee as a fee bump transaction
+// (paid for by the source account) with a fee of up to maxOpFeeStroops per operation
+func (sdex *SDEX) EnableFeeBump(maxOpFeeStroops uint64) {
+	sdex.feeBumpMaxOpFee = maxOpFeeStroops
+	log.Printf("fee bumping enabled with maxOpFeeStroops=%d\n", maxOpFeeStroops)
+}
+
+// SetDailyFeeBudget limits the fees spent per UTC day, once the budget is exhausted IsFeeBudgetExhausted returns true until the next UTC day
+func (sdex *SDEX) SetDailyFeeBudget(dailyFeeBudgetStroops uint64) {
+	sdex.feeBudget = makeFeeBudget(dailyFeeBudgetStroops, time.Now)
+	log.Printf("using a daily fee budget of %d stroops\n", dailyFeeBudgetStroops)
+}
+
+// SetSubmissionListener registers a function that is called with the number of ops of every transaction that is submitted to the network,
+// which includes transactions submitted from channel accounts and every fee bump transaction
+func (sdex *SDEX) SetSubmissionListener(onSubmit func(numOps int)) {
+	sdex.onSubmit = onSubmit
+}
+
+// IsFeeBudgetExhausted returns true when the fees spent today have reached the daily fee budget, always false if there is no budget
+func (sdex *SDEX) IsFeeBudgetExhausted() bool {
+	if sdex.feeBudget == nil {
+		return false
+	}
+	return sdex.feeBudget.isExhausted()
+}
+
+// FeeBudgetRemaining returns the fraction of the daily fee budget that has not been spent today, always 1.0 if there is no budget
+func (sdex *SDEX) FeeBudgetRemaining() float64 {
+	if sdex.feeBudget == nil {
+		return 1.0
+	}
+	return sdex.feeBudget.remainingFraction()
+}
+
 // IEIF exoses the ieif var
 func (sdex *SDEX) IEIF() *IEIF {
 	return sdex.ieif
@@ -221,6 +272,22 @@ func (sdex *SDEX) CreateSellOffer(base hProtocol.Asset, counter hProtocol.Asset,
 	return sdex.createModifySellOffer(nil, base, counter, price, amount, incrementalNativeAmountRaw)
 }
 
+// UsesManageBuyOffer returns true if bids should be placed as native ManageBuyOffer ops instead of inverted ManageSellOffer ops
+func (sdex *SDEX) UsesManageBuyOffer() bool {
+	return sdex.useManageBuyOffer && sdex.tradingOnSdex
+}
+
+// CreateManageBuyOffer creates a buy offer as a ManageBuyOffer op, price is in units of counter and amount is in units of base
+func (sdex *SDEX) CreateManageBuyOffer(base hProtocol.Asset, counter hProtocol.Asset, price float64, amount float64, incrementalNativeAmountRaw float64) (*txnbuild.ManageBuyOffer, error) {
+	return sdex.createModifyBuyOffer(nil, counter, base, price, amount, incrementalNativeAmountRaw)
+}
+
+// ModifyManageBuyOffer modifies the passed in buy offer with a ManageBuyOffer op, price is in units of counter and amount is in units of base
+func (sdex *SDEX) ModifyManageBuyOffer(offer hProtocol.Offer, price float64, amount float64, incrementalNativeAmountRaw float64) (*txnbuild.ManageBuyOffer, error) {
+	// offers are always represented as sell offers on horizon, so the buy offer sells the asset that the offer is selling
+	return sdex.createModifyBuyOffer(&offer, offer.Selling, offer.Buying, price, amount, incrementalNativeAmountRaw)
+}
+
 func (sdex *SDEX) minReserve(subentries int32) float64 {
 	return float64(2+subentries) * baseReserve
 }
@@ -373,6 +440,66 @@ func (sdex *SDEX) createModifySellOffer(offer *hProtocol.Offer, selling hProtoco
 	return &result, nil
 }
 
+// createModifyBuyOffer is the counterpart to createModifySellOffer that expresses the offer in terms of the buying asset.
+// price is the price of 1 unit of the buying asset in units of the selling asset and amount is in units of the buying asset
+func (sdex *SDEX) createModifyBuyOffer(offer *hProtocol.Offer, selling hProtocol.Asset, buying hProtocol.Asset, price float64, amount float64, incrementalNativeAmountRaw float64) (*txnbuild.ManageBuyOffer, error) {
+	if price <= 0 {
+		return nil, fmt.Errorf("error: cannot create or modify buy offer, invalid price: %.8f", price)
+	}
+	if amount <= 0 {
+		return nil, fmt.Errorf("error: cannot create or modify buy offer, invalid amount: %.8f", amount)
+	}
+
+	// check liability limits on the asset being sold
+	incrementalSell := price * amount
+	willOversell, e := sdex.ieif.willOversell(selling, incrementalSell)
+	if e != nil {
+		return nil, e
+	}
+	if willOversell {
+		return nil, nil
+	}
+
+	// check trust limits on asset being bought
+	willOverbuy, e := sdex.ieif.willOverbuy(buying, amount)
+	if e != nil {
+		return nil, e
+	}
+	if willOverbuy {
+		return nil, nil
+	}
+
+	// explicitly check that we will not oversell XLM because of fee and min reserves
+	if sdex.tradingOnSdex {
+		incrementalNativeAmountTotal := incrementalNativeAmountRaw
+		if selling.Type == utils.Native {
+			incrementalNativeAmountTotal += incrementalSell
+		}
+		willOversellNative, e := sdex.ieif.willOversellNative(incrementalNativeAmountTotal)
+		if e != nil {
+			return nil, e
+		}
+		if willOversellNative {
+			return nil, nil
+		}
+	}
+
+	result := txnbuild.ManageBuyOffer{
+		Selling: utils.Asset2Asset(selling),
+		Buying:  utils.Asset2Asset(buying),
+		Amount:  strconv.FormatFloat(amount, 'f', int(sdexOrderConstraints.VolumePrecision), 64),
+		Price:   strconv.FormatFloat(price, 'f', int(sdexOrderConstraints.PricePrecision), 64),
+	}
+	if offer != nil {
+		result.OfferID = offer.ID
+	}
+	if sdex.SourceAccount != sdex.TradingAccount {
+		result.SourceAccount = &txnbuild.SimpleAccount{AccountID: sdex.TradingAccount}
+	}
+
+	return &result, nil
+}
+
 // SubmitOpsSynch is the forced synchronous version of SubmitOps below
 func (sdex *SDEX) SubmitOpsSynch(ops []build.TransactionMutator, submitMode api.SubmitMode, asyncCallback func(hash string, e error)) error {
 	// sdex does not have a post-only type of flag for their trading API so do not propagate submitMode
@@ -395,14 +522,38 @@ func (sdex *SDEX) submitOps(opsOld []build.TransactionMutator, asyncCallback fun
 		return fmt.Errorf("SubmitOps error when computing op fee: %s", e)
 	}
 
-	sdex.incrementSeqNum()
+	txSourceAccount := sdex.SourceAccount
+	var seqNum uint64
+	var channel *channelAccount
+	if sdex.channels != nil {
+		// ops without a source account would otherwise run against the channel account
+		ops, e = setOpsSourceAccount(ops, sdex.SourceAccount)
+		if e != nil {
+			return fmt.Errorf("SubmitOps error when setting source account on ops: %s", e)
+		}
+
+		channel, e = sdex.channels.acquire()
+		if e != nil {
+			return fmt.Errorf("SubmitOps error when acquiring a channel account: %s", e)
+		}
+		seqNum, e = channel.nextSeqNum(sdex.API)
+		if e != nil {
+			sdex.channels.release(channel, false)
+			return fmt.Errorf("SubmitOps error when fetching sequence number for channel account: %s", e)
+		}
+		txSourceAccount = channel.address
+	} else {
+		sdex.incrementSeqNum()
+		seqNum = sdex.seqNum
+	}
+
 	tx, e := txnbuild.NewTransaction(
 		txnbuild.TransactionParams{
 			// sequence number is decremented here because Transaction.Build will increment sequence number
 			// I have not tested with not decrementing here and setting IncrementSequenceNum=false so leaving this way
 			SourceAccount: &txnbuild.SimpleAccount{
-				AccountID: sdex.SourceAccount,
-				Sequence:  int64(sdex.seqNum - 1),
+				AccountID: txSourceAccount,
+				Sequence:  int64(seqNum - 1),
 			},
 			BaseFee: int64(opFee),
 			// If IncrementSequenceNum is true, NewTransaction() will call `sourceAccount.IncrementSequenceNumber()`
@@ -415,14 +566,28 @@ func (sdex *SDEX) submitOps(opsOld []build.TransactionMutator, asyncCallback fun
 		},
 	)
 	if e != nil {
+		if channel != nil {
+			sdex.channels.release(channel, false)
+		}
 		return fmt.Errorf("unable to make new transaction: %s", e)
 	}
 
-	// convert to xdr string
-	txeB64, e := sdex.sign(tx)
+	tx, e = sdex.sign(tx, channel)
 	if e != nil {
+		if channel != nil {
+			sdex.channels.release(channel, false)
+		}
 		return e
 	}
+
+	// convert to xdr string
+	txeB64, e := tx.Base64()
+	if e != nil {
+		if channel != nil {
+			sdex.channels.release(channel, false)
+		}
+		return fmt.Errorf("unable to encode transaction: %s", e)
+	}
 	log.Printf("tx XDR: %s\n", txeB64)
 
 	// submit
@@ -430,17 +595,24 @@ func (sdex *SDEX) submitOps(opsOld []build.TransactionMutator, asyncCallback fun
 		if asyncMode {
 			log.Println("submitting tx XDR to network (async)")
 			e = sdex.threadTracker.TriggerGoroutine(func(inputs []interface{}) {
-				sdex.submit(txeB64, asyncCallback, true)
+				sdex.submit(tx, txeB64, opFee, channel, asyncCallback, true)
 			}, nil)
 			if e != nil {
+				if channel != nil {
+					sdex.channels.release(channel, false)
+				}
 				return fmt.Errorf("unable to trigger goroutine to submit tx XDR to network asynchronously: %s", e)
 			}
 		} else {
 			log.Println("submitting tx XDR to network (synch)")
-			sdex.submit(txeB64, asyncCallback, false)
+			sdex.submit(tx, txeB64, opFee, channel, asyncCallback, false)
 		}
 	} else {
 		log.Println("not submitting tx XDR to network in simulation mode, calling asyncCallback with empty hash value")
+		if channel != nil {
+			// the sequence number was never consumed on the network
+			sdex.channels.release(channel, false)
+		}
 		sdex.invokeAsyncCallback(asyncCallback, "", nil, asyncMode)
 	}
 	return nil
@@ -451,22 +623,90 @@ func (sdex *SDEX) CreateBuyOffer(base hProtocol.Asset, counter hProtocol.Asset,
 	return sdex.CreateSellOffer(counter, base, 1/price, amount*price, incrementalNativeAmountRaw)
 }
 
-func (sdex *SDEX) sign(tx *txnbuild.Transaction) (string, error) {
-	var e error
-	if sdex.SourceSeed != sdex.TradingSeed {
-		tx, e = utils.SignWithSeed(tx, sdex.Network, sdex.SourceSeed, sdex.TradingSeed)
-	} else {
-		tx, e = utils.SignWithSeed(tx, sdex.Network, sdex.SourceSeed)
+func (sdex *SDEX) sign(tx *txnbuild.Transaction, channel *channelAccount) (*txnbuild.Transaction, error) {
+	if sdex.signer == nil {
+		return nil, fmt.Errorf("error signing transaction: no signer was provided")
+	}
+
+	accounts := []string{sdex.SourceAccount}
+	if sdex.SourceAccount != sdex.TradingAccount {
+		accounts = append(accounts, sdex.TradingAccount)
+	}
+	if channel != nil {
+		// the channel account is the transaction source account so it needs to sign as well
+		accounts = append(accounts, channel.address)
 	}
+
+	tx, e := sdex.signer.Sign(tx, sdex.Network, accounts...)
 	if e != nil {
-		return "", fmt.Errorf("error signing transaction: %s", e)
+		return nil, fmt.Errorf("error signing transaction: %s", e)
 	}
+	return tx, nil
+}
+
+// feeBump wraps the signed inner transaction in a fee bump transaction paid for by the source account and submits it,
+// raising the fee on every attempt until it succeeds, fails for a reason other than the fee, or reaches maxOpFeeStroops.
+// It also returns the max fee of the last transaction that was submitted, which is maxFee when no fee bump was submitted
+func (sdex *SDEX) feeBump(tx *txnbuild.Transaction, opFee uint64, maxFee uint64, e error) (hProtocol.Transaction, uint64, error) {
+	for attempt := 1; attempt <= maxFeeBumpAttempts && isFeeRelatedFailure(e); attempt++ {
+		if sdex.IsFeeBudgetExhausted() {
+			log.Printf("not fee bumping transaction because the daily fee budget is exhausted\n")
+			return hProtocol.Transaction{}, maxFee, e
+		}
 
-	return tx.Base64()
+		bumpedFee, ok := nextBumpFee(opFee, sdex.feeBumpMaxOpFee)
+		if !ok {
+			log.Printf("not fee bumping transaction because the op fee (%d stroops) is already at the max op fee (%d stroops)\n", opFee, sdex.feeBumpMaxOpFee)
+			return hProtocol.Transaction{}, maxFee, e
+		}
+		log.Printf("fee bump attempt %d of %d: raising op fee from %d to %d stroops after error: %s\n", attempt, maxFeeBumpAttempts, opFee, bumpedFee, e)
+		opFee = bumpedFee
+
+		feeBumpTx, e2 := txnbuild.NewFeeBumpTransaction(txnbuild.FeeBumpTransactionParams{
+			Inner:      tx,
+			FeeAccount: sdex.SourceAccount,
+			BaseFee:    int64(opFee),
+		})
+		if e2 != nil {
+			return hProtocol.Transaction{}, maxFee, fmt.Errorf("unable to make fee bump transaction: %s", e2)
+		}
+
+		feeBumpTx, e2 = sdex.signer.SignFeeBump(feeBumpTx, sdex.Network, sdex.SourceAccount)
+		if e2 != nil {
+			return hProtocol.Transaction{}, maxFee, fmt.Errorf("error signing fee bump transaction: %s", e2)
+		}
+
+		txeB64, e2 := feeBumpTx.Base64()
+		if e2 != nil {
+			return hProtocol.Transaction{}, maxFee, fmt.Errorf("unable to encode fee bump transaction: %s", e2)
+		}
+		log.Printf("fee bump tx XDR: %s\n", txeB64)
+
+		var resp hProtocol.Transaction
+		resp, e = sdex.API.SubmitTransactionXDR(txeB64)
+		sdex.notifySubmission(len(tx.Operations()))
+		maxFee = feeBumpMaxFee(opFee, len(tx.Operations()))
+		if e == nil {
+			return resp, maxFee, nil
+		}
+	}
+	return hProtocol.Transaction{}, maxFee, e
 }
 
-func (sdex *SDEX) submit(txeB64 string, asyncCallback func(hash string, e error), asyncMode bool) {
+func (sdex *SDEX) submit(tx *txnbuild.Transaction, txeB64 string, opFee uint64, channel *channelAccount, asyncCallback func(hash string, e error), asyncMode bool) {
 	resp, e := sdex.API.SubmitTransactionXDR(txeB64)
+	sdex.notifySubmission(len(tx.Operations()))
+	maxFee := opFee * uint64(len(tx.Operations()))
+	if e != nil && sdex.feeBumpMaxOpFee > 0 && isFeeRelatedFailure(e) {
+		resp, maxFee, e = sdex.feeBump(tx, opFee, maxFee, e)
+	}
+	if sdex.feeBudget != nil {
+		sdex.recordFee(resp, e, maxFee)
+	}
+	if channel != nil {
+		// we cannot know whether a failed tx consumed the channel's sequence number so always reload it on failure
+		sdex.channels.release(channel, e == nil)
+	}
 	if e != nil {
 		if herr, ok := errors.Cause(e).(*horizonclient.Error); ok {
 			var rcs *hProtocol.TransactionResultCodes
@@ -476,7 +716,7 @@ func (sdex *SDEX) submit(txeB64 string, asyncCallback func(hash string, e error)
 				sdex.invokeAsyncCallback(asyncCallback, "", e2, asyncMode)
 				return
 			}
-			if rcs.TransactionCode == "tx_bad_seq" {
+			if rcs.TransactionCode == "tx_bad_seq" && channel == nil {
 				log.Println("(async) error: tx_bad_seq, setting flag to reload seq number")
 				sdex.reloadSeqNum = true
 			}
@@ -496,6 +736,29 @@ func (sdex *SDEX) submit(txeB64 string, asyncCallback func(hash string, e error)
 	sdex.invokeAsyncCallback(asyncCallback, resp.Hash, nil, asyncMode)
 }
 
+func (sdex *SDEX) notifySubmission(numOps int) {
+	if sdex.onSubmit != nil {
+		sdex.onSubmit(numOps)
+	}
+}
+
+// recordFee adds the fee charged for a submitted transaction to the daily fee budget, transactions that fail with tx_failed are
+// still charged a fee so we use the max fee of the transaction that was last submitted (the fee bump transaction if there was one) as an
+// estimate for those
+func (sdex *SDEX) recordFee(resp hProtocol.Transaction, e error, maxFeeStroops uint64) {
+	if e == nil {
+		sdex.feeBudget.add(uint64(resp.FeeCharged))
+		return
+	}
+
+	if herr, ok := errors.Cause(e).(*horizonclient.Error); ok {
+		rcs, e2 := herr.ResultCodes()
+		if e2 == nil && rcs.TransactionCode == "tx_failed" {
+			sdex.feeBudget.add(maxFeeStroops)
+		}
+	}
+}
+
 func (sdex *SDEX) invokeAsyncCallback(asyncCallback func(hash string, e error), hash string, err error, asyncMode bool) {
 	if asyncCallback == nil {
 		return
@@ -869,6 +1132,53 @@ func (sdex *SDEX) GetLatestTradeCursor() (interface{}, error) {
 	return records[0].PT, nil
 }
 
+// enforce SDEX implementing api.FillStreamable
+var _ api.FillStreamable = &SDEX{}
+
+// StreamFills impl, streams the trades of the trading account from horizon and calls onFill for every trade on our trading pair.
+// The horizon client does not tell us when the stream is open so we call onConnect once horizon has served a request for the trades of
+// the trading account, a stream that cannot be opened after that returns right away and is treated as disconnected by the caller
+func (sdex *SDEX) StreamFills(ctx context.Context, cursor string, onConnect func(), onFill func(cursor string)) error {
+	baseAsset, quoteAsset, e := sdex.Assets()
+	if e != nil {
+		return fmt.Errorf("error while converting pair to base and quote asset: %s", e)
+	}
+
+	_, e = sdex.API.Trades(horizonclient.TradeRequest{
+		ForAccount: sdex.TradingAccount,
+		Order:      horizonclient.OrderDesc,
+		Limit:      1,
+	})
+	if e != nil {
+		return fmt.Errorf("error while fetching trades for account %s before streaming: %s", sdex.TradingAccount, e)
+	}
+	onConnect()
+
+	tradeReq := horizonclient.TradeRequest{
+		ForAccount: sdex.TradingAccount,
+		Cursor:     cursor,
+	}
+	e = sdex.API.StreamTrades(ctx, tradeReq, func(t hProtocol.Trade) {
+		isPair := sameAsset(baseAsset, t.BaseAssetType, t.BaseAssetCode, t.BaseAssetIssuer) && sameAsset(quoteAsset, t.CounterAssetType, t.CounterAssetCode, t.CounterAssetIssuer)
+		isReversedPair := sameAsset(quoteAsset, t.BaseAssetType, t.BaseAssetCode, t.BaseAssetIssuer) && sameAsset(baseAsset, t.CounterAssetType, t.CounterAssetCode, t.CounterAssetIssuer)
+		if !isPair && !isReversedPair {
+			return
+		}
+		onFill(t.PT)
+	})
+	if e != nil {
+		return fmt.Errorf("error while streaming trades for account %s: %s", sdex.TradingAccount, e)
+	}
+	return nil
+}
+
+func sameAsset(asset hProtocol.Asset, assetType string, code string, issuer string) bool {
+	if asset.Type == utils.Native {
+		return assetType == utils.Native
+	}
+	return asset.Code == code && asset.Issuer == issuer
+}
+
 func (sdex *SDEX) checkAssetExists(asset hProtocol.Asset) error {
 	req := horizonclient.AssetRequest{
 		ForAssetCode:   asset.Code,
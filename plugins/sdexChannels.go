@@ -0,0 +1,137 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+)
+
+// channelAccount is an account that is only used as the source account of a transaction (and pays the fee) so that
+// multiple transactions can be in flight at the same time without colliding on the sequence number of a single account.
+// It signs with the bot's signer so its key is held wherever the keys of the other accounts are held
+type channelAccount struct {
+	address string
+
+	// uninitialized
+	seqNum       uint64
+	reloadSeqNum bool
+}
+
+// channelAcquireTimeout is how long we wait for a channel account to be released when all of them are in use
+const channelAcquireTimeout = 30 * time.Second
+
+// channelPool hands out channel accounts to transactions. A channel can only have one transaction in flight at a time so it is
+// removed from the pool until it is released
+type channelPool struct {
+	available      chan *channelAccount
+	size           int
+	acquireTimeout time.Duration
+}
+
+// makeChannelPool is a factory method for channelPool
+func makeChannelPool(addresses []string) (*channelPool, error) {
+	if len(addresses) == 0 {
+		return nil, fmt.Errorf("need at least one channel account address to make a channel pool")
+	}
+
+	available := make(chan *channelAccount, len(addresses))
+	seen := map[string]bool{}
+	for i, address := range addresses {
+		kp, e := keypair.Parse(address)
+		if e != nil {
+			return nil, fmt.Errorf("cannot parse channel account address at index %d: %s", i, e)
+		}
+		if _, ok := kp.(*keypair.FromAddress); !ok {
+			return nil, fmt.Errorf("channel account at index %d was not an address", i)
+		}
+		if seen[kp.Address()] {
+			return nil, fmt.Errorf("channel account %s was specified more than once", kp.Address())
+		}
+		seen[kp.Address()] = true
+
+		available <- &channelAccount{
+			address:      kp.Address(),
+			reloadSeqNum: true,
+		}
+	}
+
+	return &channelPool{
+		available:      available,
+		size:           len(addresses),
+		acquireTimeout: channelAcquireTimeout,
+	}, nil
+}
+
+// acquire waits up to the acquire timeout for a channel account to be free, the channel needs to be returned to the pool with release
+func (p *channelPool) acquire() (*channelAccount, error) {
+	select {
+	case c := <-p.available:
+		return c, nil
+	default:
+	}
+
+	log.Printf("all %d channel accounts are in use, waiting up to %s for a channel account to be released\n", p.size, p.acquireTimeout)
+	select {
+	case c := <-p.available:
+		return c, nil
+	case <-time.After(p.acquireTimeout):
+		return nil, fmt.Errorf("timed out after %s waiting for one of the %d channel accounts to be released", p.acquireTimeout, p.size)
+	}
+}
+
+// release returns the channel account to the pool, reloading the sequence number on its next use if the transaction did not succeed
+func (p *channelPool) release(c *channelAccount, succeeded bool) {
+	if !succeeded {
+		c.reloadSeqNum = true
+	}
+	p.available <- c
+}
+
+// nextSeqNum returns the next sequence number to be used for a transaction with this channel account as the source account
+func (c *channelAccount) nextSeqNum(api horizonclient.ClientInterface) (uint64, error) {
+	if c.reloadSeqNum {
+		log.Printf("reloading sequence number for channel account %s\n", c.address)
+		accountDetail, e := api.AccountDetail(horizonclient.AccountRequest{AccountID: c.address})
+		if e != nil {
+			return 0, fmt.Errorf("error loading account detail for channel account %s: %s", c.address, e)
+		}
+		seqNum, e := accountDetail.GetSequenceNumber()
+		if e != nil {
+			return 0, fmt.Errorf("error getting seq num for channel account %s: %s", c.address, e)
+		}
+		c.seqNum = uint64(seqNum)
+		c.reloadSeqNum = false
+	}
+	c.seqNum++
+	return c.seqNum, nil
+}
+
+// setOpsSourceAccount sets the source account on any ops that do not have one, this is needed when the transaction's source account
+// is a channel account since the ops would otherwise be executed against the channel account
+func setOpsSourceAccount(ops []txnbuild.Operation, account string) ([]txnbuild.Operation, error) {
+	updated := []txnbuild.Operation{}
+	for _, op := range ops {
+		switch o := op.(type) {
+		case *txnbuild.ManageSellOffer:
+			if o.SourceAccount == nil {
+				o.SourceAccount = &txnbuild.SimpleAccount{AccountID: account}
+			}
+		case *txnbuild.ManageBuyOffer:
+			if o.SourceAccount == nil {
+				o.SourceAccount = &txnbuild.SimpleAccount{AccountID: account}
+			}
+		case *txnbuild.CreatePassiveSellOffer:
+			if o.SourceAccount == nil {
+				o.SourceAccount = &txnbuild.SimpleAccount{AccountID: account}
+			}
+		default:
+			return nil, fmt.Errorf("unable to set the source account for op of type %T when submitting with a channel account", op)
+		}
+		updated = append(updated, op)
+	}
+	return updated, nil
+}
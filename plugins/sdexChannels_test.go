@@ -0,0 +1,190 @@
+package plugins
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/clients/horizonclient"
+	"github.com/stellar/go/keypair"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+)
+
+// testAccountDetailClient returns the next sequence number from its script on each call to AccountDetail, all other methods are unimplemented
+type testAccountDetailClient struct {
+	horizonclient.ClientInterface
+	seqNums []string
+	calls   int
+}
+
+func (c *testAccountDetailClient) AccountDetail(request horizonclient.AccountRequest) (hProtocol.Account, error) {
+	i := c.calls
+	c.calls++
+	if i >= len(c.seqNums) {
+		return hProtocol.Account{}, fmt.Errorf("horizon unavailable")
+	}
+	return hProtocol.Account{AccountID: request.AccountID, Sequence: c.seqNums[i]}, nil
+}
+
+func makeTestKeypair(t *testing.T) *keypair.Full {
+	kp, e := keypair.Random()
+	if e != nil {
+		t.Fatalf("could not make random keypair: %s", e)
+	}
+	return kp
+}
+
+func TestMakeChannelPool(t *testing.T) {
+	kp1 := makeTestKeypair(t)
+	kp2 := makeTestKeypair(t)
+	testCases := []struct {
+		name      string
+		addresses []string
+		wantErr   bool
+	}{
+		{
+			name:      "two channels",
+			addresses: []string{kp1.Address(), kp2.Address()},
+		}, {
+			name:      "no channels",
+			addresses: []string{},
+			wantErr:   true,
+		}, {
+			name:      "duplicate channel",
+			addresses: []string{kp1.Address(), kp1.Address()},
+			wantErr:   true,
+		}, {
+			name:      "seed instead of address",
+			addresses: []string{kp1.Seed()},
+			wantErr:   true,
+		}, {
+			name:      "invalid address",
+			addresses: []string{"hello"},
+			wantErr:   true,
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			p, e := makeChannelPool(k.addresses)
+			if k.wantErr {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, len(k.addresses), p.size)
+			assert.Equal(t, len(k.addresses), len(p.available))
+		})
+	}
+}
+
+func TestChannelPoolAcquireRelease(t *testing.T) {
+	kp := makeTestKeypair(t)
+	p, e := makeChannelPool([]string{kp.Address()})
+	if !assert.NoError(t, e) {
+		return
+	}
+	p.acquireTimeout = 10 * time.Millisecond
+
+	c, e := p.acquire()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, kp.Address(), c.address)
+
+	// the only channel is in flight so acquiring another one times out
+	_, e = p.acquire()
+	assert.Error(t, e)
+
+	// a successful transaction does not need the sequence number to be reloaded
+	c.reloadSeqNum = false
+	p.release(c, true)
+	c, e = p.acquire()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.False(t, c.reloadSeqNum)
+
+	// a failed transaction reloads the sequence number on the next use of the channel
+	p.release(c, false)
+	c, e = p.acquire()
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.True(t, c.reloadSeqNum)
+}
+
+func TestChannelAccountNextSeqNum(t *testing.T) {
+	kp := makeTestKeypair(t)
+	p, e := makeChannelPool([]string{kp.Address()})
+	if !assert.NoError(t, e) {
+		return
+	}
+	client := &testAccountDetailClient{seqNums: []string{"100", "200"}}
+
+	c, e := p.acquire()
+	if !assert.NoError(t, e) {
+		return
+	}
+	// the sequence number is loaded on first use and incremented locally after that
+	for _, want := range []uint64{101, 102} {
+		seqNum, e := c.nextSeqNum(client)
+		if !assert.NoError(t, e) {
+			return
+		}
+		assert.Equal(t, want, seqNum)
+	}
+	assert.Equal(t, 1, client.calls)
+
+	// the sequence number is reloaded after a failed transaction
+	p.release(c, false)
+	c, e = p.acquire()
+	if !assert.NoError(t, e) {
+		return
+	}
+	seqNum, e := c.nextSeqNum(client)
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, uint64(201), seqNum)
+	assert.Equal(t, 2, client.calls)
+
+	// a failure to reload keeps the channel marked for a reload so the next use tries again
+	p.release(c, false)
+	c, e = p.acquire()
+	if !assert.NoError(t, e) {
+		return
+	}
+	_, e = c.nextSeqNum(client)
+	assert.Error(t, e)
+	assert.True(t, c.reloadSeqNum)
+}
+
+func TestSetOpsSourceAccount(t *testing.T) {
+	tradingAccount := makeTestKeypair(t).Address()
+	otherAccount := &txnbuild.SimpleAccount{AccountID: makeTestKeypair(t).Address()}
+
+	mso := &txnbuild.ManageSellOffer{Selling: txnbuild.NativeAsset{}, Buying: testQuoteAsset, Amount: "1.0", Price: "1.0"}
+	mbo := &txnbuild.ManageBuyOffer{Selling: testQuoteAsset, Buying: txnbuild.NativeAsset{}, Amount: "1.0", Price: "1.0", SourceAccount: otherAccount}
+	pso := &txnbuild.CreatePassiveSellOffer{Selling: txnbuild.NativeAsset{}, Buying: testQuoteAsset, Amount: "1.0", Price: "1.0"}
+
+	updated, e := setOpsSourceAccount([]txnbuild.Operation{mso, mbo, pso}, tradingAccount)
+	if !assert.NoError(t, e) {
+		return
+	}
+	if !assert.Equal(t, 3, len(updated)) {
+		return
+	}
+	assert.Equal(t, &txnbuild.SimpleAccount{AccountID: tradingAccount}, updated[0].(*txnbuild.ManageSellOffer).SourceAccount)
+	// an explicit source account is not overwritten
+	assert.Equal(t, otherAccount, updated[1].(*txnbuild.ManageBuyOffer).SourceAccount)
+	assert.Equal(t, &txnbuild.SimpleAccount{AccountID: tradingAccount}, updated[2].(*txnbuild.CreatePassiveSellOffer).SourceAccount)
+
+	_, e = setOpsSourceAccount([]txnbuild.Operation{&txnbuild.Payment{}}, tradingAccount)
+	assert.Error(t, e)
+}
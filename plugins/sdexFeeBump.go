@@ -0,0 +1,132 @@
+package plugins
+
+import (
+	"log"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/stellar/go/clients/horizonclient"
+)
+
+// maxFeeBumpAttempts is the number of times a stuck transaction is resubmitted with a fee bump before giving up
+const maxFeeBumpAttempts = 3
+
+// feeBumpMultiplier is the factor by which the fee is raised on every attempt. stellar-core only replaces a transaction
+// that is already in its queue when the new fee is at least 10x the fee of the queued transaction
+const feeBumpMultiplier = 10
+
+// feeBudget tracks the fees spent on transactions during the current UTC day
+type feeBudget struct {
+	mutex              *sync.Mutex
+	dailyBudgetStroops uint64 // 0 means unlimited
+	now                func() time.Time
+	day                string
+	spentStroops       uint64
+	loggedExhaustedDay string
+}
+
+// makeFeeBudget is a factory method for feeBudget
+func makeFeeBudget(dailyBudgetStroops uint64, now func() time.Time) *feeBudget {
+	return &feeBudget{
+		mutex:              &sync.Mutex{},
+		dailyBudgetStroops: dailyBudgetStroops,
+		now:                now,
+	}
+}
+
+// rollover resets the spent amount when the UTC day changes, needs to be called while holding the mutex
+func (b *feeBudget) rollover() {
+	today := b.now().UTC().Format("2006-01-02")
+	if today != b.day {
+		if b.day != "" {
+			log.Printf("fee budget: new day %s, resetting spent fees (spent %d stroops on %s)\n", today, b.spentStroops, b.day)
+		}
+		b.day = today
+		b.spentStroops = 0
+	}
+}
+
+// add records fees spent on a transaction
+func (b *feeBudget) add(stroops uint64) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.rollover()
+	b.spentStroops += stroops
+}
+
+// isExhausted returns true when the fees spent today have reached the daily budget
+func (b *feeBudget) isExhausted() bool {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.rollover()
+	if b.dailyBudgetStroops == 0 || b.spentStroops < b.dailyBudgetStroops {
+		return false
+	}
+
+	if b.loggedExhaustedDay != b.day {
+		log.Printf("fee budget: spent %d stroops today which exhausts the daily budget of %d stroops\n", b.spentStroops, b.dailyBudgetStroops)
+		b.loggedExhaustedDay = b.day
+	}
+	return true
+}
+
+// remainingFraction returns the fraction of the daily budget that has not been spent today, which is 1.0 when the budget is unlimited
+func (b *feeBudget) remainingFraction() float64 {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	b.rollover()
+	if b.dailyBudgetStroops == 0 {
+		return 1.0
+	}
+	if b.spentStroops >= b.dailyBudgetStroops {
+		return 0.0
+	}
+	return float64(b.dailyBudgetStroops-b.spentStroops) / float64(b.dailyBudgetStroops)
+}
+
+// nextBumpFee returns the per-operation fee to use when resubmitting a transaction that was submitted with prevOpFee,
+// returning false when the fee cannot be raised any further
+func nextBumpFee(prevOpFee uint64, maxOpFeeStroops uint64) (uint64, bool) {
+	if prevOpFee >= maxOpFeeStroops {
+		return prevOpFee, false
+	}
+
+	if prevOpFee == 0 {
+		prevOpFee = baseFeeStroops
+	}
+	nextFee := prevOpFee * feeBumpMultiplier
+	if nextFee > maxOpFeeStroops {
+		nextFee = maxOpFeeStroops
+	}
+	return nextFee, true
+}
+
+// feeBumpMaxFee returns the max fee of a fee bump transaction that wraps an inner transaction with numInnerOps operations, the fee bump
+// transaction is charged for one more operation than its inner transaction
+func feeBumpMaxFee(opFee uint64, numInnerOps int) uint64 {
+	return opFee * uint64(numInnerOps+1)
+}
+
+// isFeeRelatedFailure returns true when the submission failed in a way that a higher fee could fix, i.e. the request to horizon timed out
+// while the transaction was waiting to get into a ledger, or the transaction was rejected because the fee was too low
+func isFeeRelatedFailure(e error) bool {
+	herr, ok := errors.Cause(e).(*horizonclient.Error)
+	if !ok {
+		return false
+	}
+
+	// a timeout does not have any result codes so check for it first
+	if herr.Problem.Status == 504 {
+		return true
+	}
+
+	rcs, e2 := herr.ResultCodes()
+	if e2 != nil {
+		return false
+	}
+	return rcs.TransactionCode == "tx_insufficient_fee"
+}
@@ -0,0 +1,97 @@
+package plugins
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNextBumpFee(t *testing.T) {
+	testCases := []struct {
+		prevOpFee       uint64
+		maxOpFeeStroops uint64
+		wantFee         uint64
+		wantOk          bool
+	}{
+		{
+			prevOpFee:       100,
+			maxOpFeeStroops: 5000,
+			wantFee:         1000,
+			wantOk:          true,
+		}, {
+			prevOpFee:       1000,
+			maxOpFeeStroops: 5000,
+			wantFee:         5000,
+			wantOk:          true,
+		}, {
+			prevOpFee:       5000,
+			maxOpFeeStroops: 5000,
+			wantFee:         5000,
+			wantOk:          false,
+		}, {
+			prevOpFee:       0,
+			maxOpFeeStroops: 5000,
+			wantFee:         1000,
+			wantOk:          true,
+		}, {
+			prevOpFee:       100,
+			maxOpFeeStroops: 0,
+			wantFee:         100,
+			wantOk:          false,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(fmt.Sprintf("%d_%d", kase.prevOpFee, kase.maxOpFeeStroops), func(t *testing.T) {
+			fee, ok := nextBumpFee(kase.prevOpFee, kase.maxOpFeeStroops)
+			assert.Equal(t, kase.wantFee, fee)
+			assert.Equal(t, kase.wantOk, ok)
+		})
+	}
+}
+
+func TestFeeBumpMaxFee(t *testing.T) {
+	testCases := []struct {
+		opFee       uint64
+		numInnerOps int
+		want        uint64
+	}{
+		{opFee: 1000, numInnerOps: 1, want: 2000},
+		{opFee: 1000, numInnerOps: 5, want: 6000},
+		{opFee: 100, numInnerOps: 100, want: 10100},
+	}
+
+	for _, kase := range testCases {
+		t.Run(fmt.Sprintf("%d_%d", kase.opFee, kase.numInnerOps), func(t *testing.T) {
+			assert.Equal(t, kase.want, feeBumpMaxFee(kase.opFee, kase.numInnerOps))
+		})
+	}
+}
+
+func TestFeeBudget(t *testing.T) {
+	now := time.Date(2020, 5, 1, 23, 0, 0, 0, time.UTC)
+	b := makeFeeBudget(1000, func() time.Time { return now })
+
+	assert.False(t, b.isExhausted())
+	assert.Equal(t, 1.0, b.remainingFraction())
+	b.add(600)
+	assert.False(t, b.isExhausted())
+	assert.InDelta(t, 0.4, b.remainingFraction(), 0.0000001)
+	b.add(400)
+	assert.True(t, b.isExhausted())
+	assert.Equal(t, 0.0, b.remainingFraction())
+
+	// budget resets on the next UTC day
+	now = now.Add(2 * time.Hour)
+	assert.False(t, b.isExhausted())
+	b.add(999)
+	assert.False(t, b.isExhausted())
+
+	// a budget of 0 is unlimited
+	unlimited := makeFeeBudget(0, func() time.Time { return now })
+	unlimited.add(1000000)
+	assert.False(t, unlimited.isExhausted())
+	assert.Equal(t, 1.0, unlimited.remainingFraction())
+}
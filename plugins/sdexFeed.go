@@ -14,17 +14,34 @@ import (
 
 // sdexFeed represents a pricefeed from the SDEX
 type sdexFeed struct {
-	sdex       *SDEX
-	assetBase  *hProtocol.Asset
-	assetQuote *hProtocol.Asset
+	sdex          *SDEX
+	assetBase     *hProtocol.Asset
+	assetQuote    *hProtocol.Asset
+	orderbookMode *orderbookPriceMode
 }
 
 // ensure that it implements PriceFeed
 var _ api.PriceFeed = &sdexFeed{}
 
-// makeSDEXFeed creates a price feed from buysell's url fields
+// makeSDEXFeed creates a price feed from buysell's url fields, formatted as base/quote with an optional orderbook price mode
+// as a third part, e.g. COUPON:GBMMZMK2DC4FFP4CAI6KCVNCQ7WLO5A7DQU7EC7WGHRDQBZB763X4OQI/XLM:/depthmid:1000
 func makeSDEXFeed(url string) (*sdexFeed, error) {
 	urlParts := strings.Split(url, "/")
+	if len(urlParts) < 2 || len(urlParts) > 3 {
+		return nil, fmt.Errorf("invalid format of sdex type URL, needs either 2 or 3 parts after splitting URL by '/', has %d: %s", len(urlParts), url)
+	}
+
+	var orderbookMode *orderbookPriceMode
+	if len(urlParts) == 3 && urlParts[2] != "mid" {
+		var e error
+		orderbookMode, e = parseOrderbookPriceMode(urlParts[2])
+		if e != nil {
+			return nil, fmt.Errorf("invalid modifier on sdex type URL: %s", e)
+		}
+		if orderbookMode == nil {
+			return nil, fmt.Errorf("unsupported modifier '%s' on sdex type URL", urlParts[2])
+		}
+	}
 
 	baseAsset, e := parseHorizonAsset(urlParts[0])
 	if e != nil {
@@ -62,8 +79,7 @@ func makeSDEXFeed(url string) (*sdexFeed, error) {
 		api,
 		ieif,
 		nil,
-		"",
-		"",
+		nil,
 		"",
 		"",
 		network,
@@ -74,12 +90,14 @@ func makeSDEXFeed(url string) (*sdexFeed, error) {
 		tradingPair,
 		sdexAssetMap,
 		SdexFixedFeeFn(0),
+		false,
 	)
 
 	return &sdexFeed{
-		sdex:       sdex,
-		assetBase:  baseAsset,
-		assetQuote: quoteAsset,
+		sdex:          sdex,
+		assetBase:     baseAsset,
+		assetQuote:    quoteAsset,
+		orderbookMode: orderbookMode,
 	}, nil
 }
 
@@ -96,8 +114,21 @@ func parseHorizonAsset(assetString string) (*hProtocol.Asset, error) {
 	return asset, e
 }
 
-// GetPrice returns the SDEX mid price for the trading pair
+// GetPrice returns the SDEX mid price for the trading pair, or the price from the orderbook price mode when one is set
 func (s *sdexFeed) GetPrice() (float64, error) {
+	if s.orderbookMode != nil {
+		orderBook, e := s.sdex.GetOrderBook(s.sdex.pair, orderbookPriceDepth)
+		if e != nil {
+			return 0, fmt.Errorf("unable to get sdex price: %s", e)
+		}
+
+		price, e := s.orderbookMode.price(orderBook)
+		if e != nil {
+			return 0, fmt.Errorf("unable to get sdex price: %s", e)
+		}
+		return price, nil
+	}
+
 	orderBook, e := s.sdex.GetOrderBook(s.sdex.pair, 1)
 	if e != nil {
 		return 0, fmt.Errorf("unable to get sdex price: %s", e)
@@ -0,0 +1,187 @@
+package plugins
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/api"
+	"github.com/stellar/kelp/model"
+	"github.com/stellar/kelp/support/utils"
+)
+
+// ownTopOfBook is the best price of our own offers on each side of the book, in units of the quote asset per unit of the base asset
+type ownTopOfBook struct {
+	hasBid  bool
+	bestBid float64
+	hasAsk  bool
+	bestAsk float64
+}
+
+type selfTradeFilter struct {
+	name           string
+	baseAsset      hProtocol.Asset
+	quoteAsset     hProtocol.Asset
+	reprice        bool
+	pricePrecision int8
+	loadOffers     func() ([]hProtocol.Offer, error)
+}
+
+// MakeFilterSelfTrade makes a submit filter that drops or reprices ops that would match open offers of our own account. The offers
+// are loaded from the exchange when the filter is applied, which fetches all offers of the account on SDEX and uses GetOpenOrders
+// on centralized exchanges. This catches offers placed by other bots that share the account on the same book, whether they quote
+// the pair or the inverted pair. Offers on other pairs are ignored since the ops of this bot cannot match them
+func MakeFilterSelfTrade(exchangeShim api.ExchangeShim, tradingPair *model.TradingPair, baseAsset hProtocol.Asset, quoteAsset hProtocol.Asset, reprice bool) (SubmitFilter, error) {
+	return &selfTradeFilter{
+		name:           "selfTradeFilter",
+		baseAsset:      baseAsset,
+		quoteAsset:     quoteAsset,
+		reprice:        reprice,
+		pricePrecision: exchangeShim.GetOrderConstraints(tradingPair).PricePrecision,
+		loadOffers:     exchangeShim.LoadOffersHack,
+	}, nil
+}
+
+var _ SubmitFilter = &selfTradeFilter{}
+
+func (f *selfTradeFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer) ([]txnbuild.Operation, error) {
+	offers, e := f.loadOffers()
+	if e != nil {
+		return nil, fmt.Errorf("could not load open offers of the account: %s", e)
+	}
+	// FilterOffers picks up offers on the inverted pair too since they are on the same book
+	ownSellingOffers, ownBuyingOffers := utils.FilterOffers(offers, f.baseAsset, f.quoteAsset)
+
+	// offers that are updated or deleted by the ops will no longer be on the book in their current form so they cannot be matched
+	tob, e := makeOwnTopOfBook(ownSellingOffers, ownBuyingOffers, ignoreOfferIDs(ops))
+	if e != nil {
+		return nil, fmt.Errorf("could not compute the top of the book of our own offers: %s", e)
+	}
+
+	innerFn := func(op *txnbuild.ManageSellOffer) (*txnbuild.ManageSellOffer, error) {
+		return selfTradeFilterFn(tob, op, f.baseAsset, f.quoteAsset, f.reprice, f.pricePrecision)
+	}
+	ops, e = filterOps(f.name, f.baseAsset, f.quoteAsset, sellingOffers, buyingOffers, ops, innerFn)
+	if e != nil {
+		return nil, fmt.Errorf("could not apply filter: %s", e)
+	}
+	return ops, nil
+}
+
+// String is the Stringer method
+func (f *selfTradeFilter) String() string {
+	return f.name
+}
+
+func makeOwnTopOfBook(sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer, ignoreOfferIds map[int64]bool) (*ownTopOfBook, error) {
+	tob := &ownTopOfBook{}
+	for _, o := range sellingOffers {
+		if ignoreOfferIds[o.ID] {
+			continue
+		}
+		price, e := strconv.ParseFloat(o.Price, 64)
+		if e != nil {
+			return nil, fmt.Errorf("could not parse price of offer %d (%s): %s", o.ID, o.Price, e)
+		}
+		if !tob.hasAsk || price < tob.bestAsk {
+			tob.hasAsk, tob.bestAsk = true, price
+		}
+	}
+	for _, o := range buyingOffers {
+		if ignoreOfferIds[o.ID] {
+			continue
+		}
+		// the price of an offer that buys the base asset is in units of base per unit of quote
+		price, e := strconv.ParseFloat(o.Price, 64)
+		if e != nil {
+			return nil, fmt.Errorf("could not parse price of offer %d (%s): %s", o.ID, o.Price, e)
+		}
+		if price <= 0.0 {
+			continue
+		}
+		if !tob.hasBid || 1/price > tob.bestBid {
+			tob.hasBid, tob.bestBid = true, 1/price
+		}
+	}
+	return tob, nil
+}
+
+func selfTradeFilterFn(
+	tob *ownTopOfBook,
+	op *txnbuild.ManageSellOffer,
+	baseAsset hProtocol.Asset,
+	quoteAsset hProtocol.Asset,
+	reprice bool,
+	pricePrecision int8,
+) (*txnbuild.ManageSellOffer, error) {
+	isSell, e := utils.IsSelling(baseAsset, quoteAsset, op.Selling, op.Buying)
+	if e != nil {
+		return nil, fmt.Errorf("error when running the isSelling check for op '%+v': %s", *op, e)
+	}
+
+	opPrice, e := strconv.ParseFloat(op.Price, 64)
+	if e != nil {
+		return nil, fmt.Errorf("could not convert price (%s) to float: %s", op.Price, e)
+	}
+
+	// the price of both sell and buy ops is in units of the asset being bought per unit of the asset being sold, so an op crosses our
+	// own offers on the other side when its price is at or below the inverse of the best price on that side
+	var maxCrossingPrice float64
+	if isSell {
+		if !tob.hasBid {
+			return op, nil
+		}
+		maxCrossingPrice = tob.bestBid
+	} else {
+		if !tob.hasAsk || tob.bestAsk <= 0.0 {
+			return op, nil
+		}
+		maxCrossingPrice = 1 / tob.bestAsk
+	}
+	if opPrice > maxCrossingPrice {
+		return op, nil
+	}
+
+	if !reprice {
+		log.Printf("selfTradeFilter: dropping op with price %s (isSell=%v) since it would match our own offers at %.7f\n", op.Price, isSell, maxCrossingPrice)
+		return nil, nil
+	}
+
+	// the new price is computed in units of the quote asset per unit of the base asset so it lands on a valid price of the pair
+	var newPrice string
+	if isSell {
+		newPrice = model.NumberFromFloat(priceAbove(tob.bestBid, pricePrecision), pricePrecision).AsString()
+	} else {
+		bidPrice := priceBelow(tob.bestAsk, pricePrecision)
+		if bidPrice <= 0.0 {
+			log.Printf("selfTradeFilter: dropping op with price %s (isSell=%v) since there is no valid price below our own ask at %.10f\n", op.Price, isSell, tob.bestAsk)
+			return nil, nil
+		}
+		// keep more digits once the price is inverted so the bid does not move back onto our own ask
+		newPrice = model.NumberFromFloat(1/bidPrice, model.InvertPrecision).AsString()
+	}
+	log.Printf("selfTradeFilter: repricing op from %s to %s (isSell=%v) since it would match our own offers at %.7f\n", op.Price, newPrice, isSell, maxCrossingPrice)
+	op.Price = newPrice
+	return op, nil
+}
+
+// priceAbove is the lowest price at the precision that is above the given price
+func priceAbove(price float64, precision int8) float64 {
+	p := model.NumberFromFloat(price, precision).AsFloat()
+	if p <= price {
+		p += math.Pow(10, -float64(precision))
+	}
+	return p
+}
+
+// priceBelow is the highest price at the precision that is below the given price
+func priceBelow(price float64, precision int8) float64 {
+	p := model.NumberFromFloat(price, precision).AsFloat()
+	if p >= price {
+		p -= math.Pow(10, -float64(precision))
+	}
+	return p
+}
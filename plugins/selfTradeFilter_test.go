@@ -0,0 +1,157 @@
+package plugins
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/support/utils"
+)
+
+func TestMakeOwnTopOfBook(t *testing.T) {
+	base := utils.Asset2Asset2(testBaseAsset)
+	quote := utils.Asset2Asset2(testQuoteAsset)
+	sellingOffers := []hProtocol.Offer{
+		{ID: 1, Selling: base, Buying: quote, Price: "1.2500000"},
+		{ID: 2, Selling: base, Buying: quote, Price: "1.2000000"},
+	}
+	// prices of offers buying the base asset are in units of base per unit of quote
+	buyingOffers := []hProtocol.Offer{
+		{ID: 3, Selling: quote, Buying: base, Price: "1.0000000"},
+		{ID: 4, Selling: quote, Buying: base, Price: "0.8000000"},
+	}
+
+	testCases := []struct {
+		name           string
+		ignoreOfferIds map[int64]bool
+		want           *ownTopOfBook
+	}{
+		{
+			name:           "all offers",
+			ignoreOfferIds: map[int64]bool{},
+			want:           &ownTopOfBook{hasBid: true, bestBid: 1.25, hasAsk: true, bestAsk: 1.2},
+		}, {
+			name:           "offers updated by ops are ignored",
+			ignoreOfferIds: map[int64]bool{2: true, 4: true},
+			want:           &ownTopOfBook{hasBid: true, bestBid: 1.0, hasAsk: true, bestAsk: 1.25},
+		}, {
+			name:           "no offers left",
+			ignoreOfferIds: map[int64]bool{1: true, 2: true, 3: true, 4: true},
+			want:           &ownTopOfBook{},
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			actual, e := makeOwnTopOfBook(sellingOffers, buyingOffers, k.ignoreOfferIds)
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, k.want, actual)
+		})
+	}
+}
+
+func TestSelfTradeFilterFn(t *testing.T) {
+	// our own best bid is at 1.0 and our own best ask is at 1.25
+	tob := &ownTopOfBook{hasBid: true, bestBid: 1.0, hasAsk: true, bestAsk: 1.25}
+
+	testCases := []struct {
+		name           string
+		tob            *ownTopOfBook
+		reprice        bool
+		pricePrecision int8
+		inputOp        *txnbuild.ManageSellOffer
+		wantOp         *txnbuild.ManageSellOffer
+	}{
+		{
+			name:    "sell above own bid",
+			tob:     tob,
+			inputOp: makeSellOpAmtPrice(10.0, 1.1),
+			wantOp:  makeSellOpAmtPrice(10.0, 1.1),
+		}, {
+			name:    "sell at own bid is dropped",
+			tob:     tob,
+			inputOp: makeSellOpAmtPrice(10.0, 1.0),
+			wantOp:  nil,
+		}, {
+			name:           "sell below own bid is repriced",
+			tob:            tob,
+			reprice:        true,
+			pricePrecision: 7,
+			inputOp:        makeSellOpAmtPrice(10.0, 0.9),
+			wantOp:         makeSellOpAmtPrice(10.0, 1.0000001),
+		}, {
+			name:           "sell is repriced at the precision of the pair",
+			tob:            tob,
+			reprice:        true,
+			pricePrecision: 4,
+			inputOp:        makeSellOpAmtPrice(10.0, 0.9),
+			wantOp: &txnbuild.ManageSellOffer{
+				Buying:  testQuoteAsset,
+				Selling: testBaseAsset,
+				Amount:  "10.0000000",
+				Price:   "1.0001",
+			},
+		}, {
+			name:    "sell without own bids",
+			tob:     &ownTopOfBook{hasAsk: true, bestAsk: 1.25},
+			inputOp: makeSellOpAmtPrice(10.0, 0.5),
+			wantOp:  makeSellOpAmtPrice(10.0, 0.5),
+		}, {
+			name:    "buy below own ask",
+			tob:     tob,
+			inputOp: makeBuyOpAmtPrice(10.0, 1.2),
+			wantOp:  makeBuyOpAmtPrice(10.0, 1.2),
+		}, {
+			name:    "buy above own ask is dropped",
+			tob:     tob,
+			inputOp: makeBuyOpAmtPrice(10.0, 1.3),
+			wantOp:  nil,
+		}, {
+			// the price of a buy op is in units of base per unit of quote so it is the inverse of the bid just below the own ask (1.25)
+			name:           "buy above own ask is repriced",
+			tob:            tob,
+			reprice:        true,
+			pricePrecision: 7,
+			inputOp:        makeBuyOpAmtPrice(10.0, 1.3),
+			wantOp: &txnbuild.ManageSellOffer{
+				Buying:  testBaseAsset,
+				Selling: testQuoteAsset,
+				Amount:  "13.0000000",
+				Price:   "0.800000064000005",
+			},
+		}, {
+			name:           "buy is repriced at the precision of the pair",
+			tob:            tob,
+			reprice:        true,
+			pricePrecision: 4,
+			inputOp:        makeBuyOpAmtPrice(10.0, 1.3),
+			wantOp: &txnbuild.ManageSellOffer{
+				Buying:  testBaseAsset,
+				Selling: testQuoteAsset,
+				Amount:  "13.0000000",
+				Price:   "0.800064005120410",
+			},
+		}, {
+			name:    "buy without own asks",
+			tob:     &ownTopOfBook{hasBid: true, bestBid: 1.0},
+			inputOp: makeBuyOpAmtPrice(10.0, 2.0),
+			wantOp:  makeBuyOpAmtPrice(10.0, 2.0),
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			base := utils.Asset2Asset2(testBaseAsset)
+			quote := utils.Asset2Asset2(testQuoteAsset)
+			actual, e := selfTradeFilterFn(k.tob, k.inputOp, base, quote, k.reprice, k.pricePrecision)
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, k.wantOp, actual)
+		})
+	}
+}
@@ -217,6 +217,14 @@ func convertToPassiveSellOffer(op *txnbuild.ManageSellOffer) *txnbuild.CreatePas
 	return passiveSellOffer
 }
 
+// convertToPassiveOp converts sell offers to passive sell offers, there is no passive variant of a buy offer so any ManageBuyOffer is returned as-is
+func convertToPassiveOp(op txnbuild.Operation) txnbuild.Operation {
+	if mso, ok := op.(*txnbuild.ManageSellOffer); ok {
+		return convertToPassiveSellOffer(mso)
+	}
+	return op
+}
+
 func (s *sellSideStrategy) createPrecedingOffers(
 	precedingLevels []api.Level,
 ) (
@@ -243,15 +251,15 @@ func (s *sellSideStrategy) createPrecedingOffers(
 		}
 
 		var offerPrice *model.Number
-		var op *txnbuild.ManageSellOffer
-		offerPrice, hitCapacityLimit, op, e = s.createSellLevel(i, *targetPrice, *targetAmount)
+		var op txnbuild.Operation
+		offerPrice, hitCapacityLimit, op, e = s.createSellLevel(i, precedingLevels[i], *targetPrice, *targetAmount)
 		if e != nil {
 			return 0, false, nil, nil, fmt.Errorf("unable to create new preceding offer: %s", e)
 		}
 
 		if op != nil {
 			// create passive sell
-			passiveSellOffer := convertToPassiveSellOffer(op)
+			passiveSellOffer := convertToPassiveOp(op)
 
 			ops = append(ops, passiveSellOffer)
 
@@ -321,13 +329,13 @@ func (s *sellSideStrategy) UpdateWithOps(offers []hProtocol.Offer) (opsOld []bui
 		}
 
 		var offerPrice *model.Number
-		var op *txnbuild.ManageSellOffer
+		var op txnbuild.Operation
 		if isModify {
 			log.Printf("**************sellSideStrategy.UpdateWithOps - isModify: %s", isModify)
-			offerPrice, hitCapacityLimit, op, e = s.modifySellLevel(offers, existingOffersIdx, i, *targetPrice, *targetAmount)
+			offerPrice, hitCapacityLimit, op, e = s.modifySellLevel(offers, existingOffersIdx, i, s.desiredLevels[i], *targetPrice, *targetAmount)
 		} else {
 			log.Printf("**************sellSideStrategy.UpdateWithOps - isModify: %s", isModify)
-			offerPrice, hitCapacityLimit, op, e = s.createSellLevel(i, *targetPrice, *targetAmount)
+			offerPrice, hitCapacityLimit, op, e = s.createSellLevel(i, s.desiredLevels[i], *targetPrice, *targetAmount)
 		}
 		if e != nil {
 			return nil, nil, fmt.Errorf("unable to update existing offers or create new offers: %s", e)
@@ -339,7 +347,7 @@ func (s *sellSideStrategy) UpdateWithOps(offers []hProtocol.Offer) (opsOld []bui
 				// prepend operations that reduce the size of an existing order because they decrease our liabilities
 				ops = append([]txnbuild.Operation{op}, ops...)
 			} else {
-				passiveSellOffer := convertToPassiveSellOffer(op)
+				passiveSellOffer := convertToPassiveOp(op)
 
 				ops = append(ops, passiveSellOffer)
 				//ops = append(ops, op)
@@ -402,8 +410,85 @@ func (s *sellSideStrategy) computeRemainderAmount(incrementalSellAmount float64,
 		availableSellingCapacity.Selling, availableBuyingCapacity.Buying, price)
 }
 
+// invertedLevelConstraints returns a copy of the order constraints that keeps as many digits of the price of inverted levels as a model.Number
+// can hold. Inverted prices are at most 10^PricePrecision and model.Number rounds through an int64, which leaves 18 digits in total
+func invertedLevelConstraints(oc *model.OrderConstraints) *model.OrderConstraints {
+	c := *oc
+	c.PricePrecision = 18 - oc.PricePrecision
+	if c.PricePrecision > model.InvertPrecision {
+		c.PricePrecision = model.InvertPrecision
+	}
+	return &c
+}
+
+// usesManageBuyOffer returns true if this is the buy side and bids are placed as native ManageBuyOffer ops
+func (s *sellSideStrategy) usesManageBuyOffer() bool {
+	return s.divideAmountByPrice && s.sdex.UsesManageBuyOffer()
+}
+
+// manageBuyOfferTargets returns the price and amount of the bid for the level in the bot's base/quote context, i.e. the price in units of
+// the quote asset per unit of the base asset and the amount in units of the base asset. The bid price is computed from the level before
+// its price is capped to the precision of the inverted side, so it is only rounded once. sellingAmount is the amount of the side (i.e. in
+// units of the quote asset) and is less than targetSellingAmount when the offer was reduced to fit our remaining capacity
+func (s *sellSideStrategy) manageBuyOfferTargets(level api.Level, sellingAmount float64, targetSellingAmount float64) (float64, float64) {
+	bidPrice := model.NumberFromFloat(1/level.Price.AsFloat(), s.orderConstraints.PricePrecision).AsFloat()
+	if sellingAmount >= targetSellingAmount {
+		return bidPrice, level.Amount.AsFloat()
+	}
+	// truncate so we do not exceed the remaining capacity of the quote asset
+	return bidPrice, model.NumberFromFloatRoundTruncate(sellingAmount/bidPrice, s.orderConstraints.VolumePrecision).AsFloat()
+}
+
+// createOffer places a new offer on the side, bids are placed as native ManageBuyOffer ops when enabled on the SDEX instance.
+// price and amount are always in terms of the side (i.e. inverted for the buy side), the ManageBuyOffer is computed from the level instead
+func (s *sellSideStrategy) createOffer(level api.Level, price float64, amount float64, targetAmount float64, incrementalNativeAmountRaw float64) (txnbuild.Operation, error) {
+	if s.usesManageBuyOffer() {
+		bidPrice, bidAmount := s.manageBuyOfferTargets(level, amount, targetAmount)
+		op, e := s.sdex.CreateManageBuyOffer(*s.assetQuote, *s.assetBase, bidPrice, bidAmount, incrementalNativeAmountRaw)
+		if op == nil {
+			// avoid returning a typed nil inside the interface
+			return nil, e
+		}
+		return op, e
+	}
+
+	op, e := s.sdex.CreateSellOffer(*s.assetBase, *s.assetQuote, price, amount, incrementalNativeAmountRaw)
+	if op == nil {
+		return nil, e
+	}
+	return op, e
+}
+
+// modifyOffer is the counterpart to createOffer for existing offers
+func (s *sellSideStrategy) modifyOffer(offer hProtocol.Offer, level api.Level, price float64, amount float64, targetAmount float64, incrementalNativeAmountRaw float64) (txnbuild.Operation, error) {
+	if s.usesManageBuyOffer() {
+		bidPrice, bidAmount := s.manageBuyOfferTargets(level, amount, targetAmount)
+		op, e := s.sdex.ModifyManageBuyOffer(offer, bidPrice, bidAmount, incrementalNativeAmountRaw)
+		if op == nil {
+			return nil, e
+		}
+		return op, e
+	}
+
+	op, e := s.sdex.ModifySellOffer(offer, price, amount, incrementalNativeAmountRaw)
+	if op == nil {
+		return nil, e
+	}
+	return op, e
+}
+
+// addLiabilities updates the cached liabilities for the op that was placed, ManageBuyOffer ops are accounted for in terms of their own
+// price and amount instead of the inverted amounts of the side
+func (s *sellSideStrategy) addLiabilities(op txnbuild.Operation, selling hProtocol.Asset, buying hProtocol.Asset, incrementalSell float64, incrementalBuy float64, incrementalNativeAmountRaw float64) error {
+	if mbo, ok := op.(*txnbuild.ManageBuyOffer); ok {
+		return s.ieif.AddManageBuyOfferLiabilities(selling, buying, mbo, incrementalNativeAmountRaw)
+	}
+	s.ieif.AddLiabilities(selling, buying, incrementalSell, incrementalBuy, incrementalNativeAmountRaw)
+	return nil
+}
+
 // createSellLevel returns offerPrice, hitCapacityLimit, op, error.
-func (s *sellSideStrategy) createSellLevel(index int, targetPrice model.Number, targetAmount model.Number) (*model.Number, bool, *txnbuild.ManageSellOffer, error) {
+func (s *sellSideStrategy) createSellLevel(index int, level api.Level, targetPrice model.Number, targetAmount model.Number) (*model.Number, bool, txnbuild.Operation, error) {
 	incrementalNativeAmountRaw := s.sdex.ComputeIncrementalNativeAmountRaw(true)
 	targetPrice = *model.NumberByCappingPrecision(&targetPrice, s.orderConstraints.PricePrecision)
 	targetAmount = *model.NumberByCappingPrecision(&targetAmount, s.orderConstraints.VolumePrecision)
@@ -412,7 +497,7 @@ func (s *sellSideStrategy) createSellLevel(index int, targetPrice model.Number,
 		targetPrice.AsFloat(),
 		targetAmount.AsFloat(),
 		incrementalNativeAmountRaw,
-		func(price float64, amount float64, incrementalNativeAmountRaw float64) (*txnbuild.ManageSellOffer, error) {
+		func(price float64, amount float64, incrementalNativeAmountRaw float64) (txnbuild.Operation, error) {
 			priceLogged := price
 			amountLogged := amount
 			if s.divideAmountByPrice {
@@ -420,7 +505,7 @@ func (s *sellSideStrategy) createSellLevel(index int, targetPrice model.Number,
 				amountLogged = amount * price
 			}
 			log.Printf("%s | create | new level=%d | priceQuote=%.8f | amtBase=%.8f\n", s.action, index+1, priceLogged, amountLogged)
-			return s.sdex.CreateSellOffer(*s.assetBase, *s.assetQuote, price, amount, incrementalNativeAmountRaw)
+			return s.createOffer(level, price, amount, targetAmount.AsFloat(), incrementalNativeAmountRaw)
 		},
 		*s.assetBase,
 		*s.assetQuote,
@@ -429,7 +514,7 @@ func (s *sellSideStrategy) createSellLevel(index int, targetPrice model.Number,
 }
 
 // modifySellLevel returns offerPrice, hitCapacityLimit, op, error.
-func (s *sellSideStrategy) modifySellLevel(offers []hProtocol.Offer, index int, newIndex int, targetPrice model.Number, targetAmount model.Number) (*model.Number, bool, *txnbuild.ManageSellOffer, error) {
+func (s *sellSideStrategy) modifySellLevel(offers []hProtocol.Offer, index int, newIndex int, level api.Level, targetPrice model.Number, targetAmount model.Number) (*model.Number, bool, txnbuild.Operation, error) {
 	highestPrice := targetPrice.AsFloat() + targetPrice.AsFloat()*s.priceTolerance
 	lowestPrice := targetPrice.AsFloat() - targetPrice.AsFloat()*s.priceTolerance
 	minAmount := targetAmount.AsFloat() - targetAmount.AsFloat()*s.amountTolerance
@@ -481,7 +566,7 @@ func (s *sellSideStrategy) modifySellLevel(offers []hProtocol.Offer, index int,
 		targetPrice.AsFloat(),
 		targetAmount.AsFloat(),
 		incrementalNativeAmountRaw,
-		func(price float64, amount float64, incrementalNativeAmountRaw float64) (*txnbuild.ManageSellOffer, error) {
+		func(price float64, amount float64, incrementalNativeAmountRaw float64) (txnbuild.Operation, error) {
 			priceLogged := price
 			amountLogged := amount
 			curPriceLogged := curPrice
@@ -503,7 +588,7 @@ func (s *sellSideStrategy) modifySellLevel(offers []hProtocol.Offer, index int,
 			}
 			log.Printf("%s | modify | old level=%d | new level = %d | triggers=%v | targetPriceQuote=%.8f | targetAmtBase=%.8f | curPriceQuote=%.8f | lowPriceQuote=%.8f | highPriceQuote=%.8f | curAmtBase=%.8f | minAmtBase=%.8f | maxAmtBase=%.8f\n",
 				s.action, index+1, newIndex+1, triggers, priceLogged, amountLogged, curPriceLogged, lowestPriceLogged, highestPriceLogged, curAmountLogged, minAmountLogged, maxAmountLogged)
-			return s.sdex.ModifySellOffer(offers[index], price, amount, incrementalNativeAmountRaw)
+			return s.modifyOffer(offers[index], level, price, amount, targetAmount.AsFloat(), incrementalNativeAmountRaw)
 		},
 		offers[index].Selling,
 		offers[index].Buying,
@@ -516,10 +601,10 @@ func (s *sellSideStrategy) placeOrderWithRetry(
 	targetPrice float64,
 	targetAmount float64,
 	incrementalNativeAmountRaw float64,
-	placeOffer func(price float64, amount float64, incrementalNativeAmountRaw float64) (*txnbuild.ManageSellOffer, error),
+	placeOffer func(price float64, amount float64, incrementalNativeAmountRaw float64) (txnbuild.Operation, error),
 	assetBase hProtocol.Asset,
 	assetQuote hProtocol.Asset,
-) (bool, *txnbuild.ManageSellOffer, error) {
+) (bool, txnbuild.Operation, error) {
 	op, e := placeOffer(targetPrice, targetAmount, incrementalNativeAmountRaw)
 	if e != nil {
 		return false, nil, e
@@ -529,7 +614,10 @@ func (s *sellSideStrategy) placeOrderWithRetry(
 	// op is nil only when we hit capacity limits
 	if op != nil {
 		// update the cached liabilities if we create a valid operation to create an offer
-		s.ieif.AddLiabilities(assetBase, assetQuote, incrementalSellAmount, incrementalBuyAmount, incrementalNativeAmountRaw)
+		e = s.addLiabilities(op, assetBase, assetQuote, incrementalSellAmount, incrementalBuyAmount, incrementalNativeAmountRaw)
+		if e != nil {
+			return false, nil, e
+		}
 		return false, op, nil
 	}
 
@@ -549,7 +637,10 @@ func (s *sellSideStrategy) placeOrderWithRetry(
 
 	if op != nil {
 		// update the cached liabilities if we create a valid operation to create an offer
-		s.ieif.AddLiabilities(assetBase, assetQuote, newSellingAmount, newBuyingAmount, incrementalNativeAmountRaw)
+		e = s.addLiabilities(op, assetBase, assetQuote, newSellingAmount, newBuyingAmount, incrementalNativeAmountRaw)
+		if e != nil {
+			return true, nil, e
+		}
 		return true, op, nil
 	}
 	return true, nil, fmt.Errorf("error: (programmer?) unable to place offer with the new (reduced) selling and buying amounts, oldSellingAmount=%.8f, newSellingAmount=%.8f, oldBuyingAmount=%.8f, newBuyingAmount=%.8f",
@@ -110,3 +110,49 @@ func TestComputeOffersToPrune(t *testing.T) {
 		})
 	}
 }
+
+func TestManageBuyOfferTargets(t *testing.T) {
+	orderConstraints := model.MakeOrderConstraints(7, 7, 1.0)
+	levelConstraints := invertedLevelConstraints(orderConstraints)
+	if !assert.Equal(t, int8(11), levelConstraints.PricePrecision) {
+		return
+	}
+
+	// bid 100 units of base at 1.2345678 units of quote, which is a price of 0.8100001 when the level is capped to 7 decimals and would
+	// place the bid at 1/0.8100001 = 1.2345677
+	level := api.Level{
+		Price:  *model.NumberFromFloat(1/1.2345678, levelConstraints.PricePrecision),
+		Amount: *model.NumberFromFloat(100.0, levelConstraints.VolumePrecision),
+	}
+	testCases := []struct {
+		name                string
+		sellingAmount       float64
+		targetSellingAmount float64
+		wantPrice           float64
+		wantAmount          float64
+	}{
+		{
+			name:                "full level",
+			sellingAmount:       123.45678,
+			targetSellingAmount: 123.45678,
+			wantPrice:           1.2345678,
+			wantAmount:          100.0,
+		}, {
+			// 7.5 / 1.2345678 = 6.07500049815 which is truncated to fit the remaining capacity
+			name:                "reduced to remaining capacity",
+			sellingAmount:       7.5,
+			targetSellingAmount: 123.45678,
+			wantPrice:           1.2345678,
+			wantAmount:          6.0750004,
+		},
+	}
+
+	s := &sellSideStrategy{orderConstraints: orderConstraints}
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			price, amount := s.manageBuyOfferTargets(level, k.sellingAmount, k.targetSellingAmount)
+			assert.Equal(t, k.wantPrice, price)
+			assert.Equal(t, k.wantAmount, amount)
+		})
+	}
+}
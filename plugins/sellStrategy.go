@@ -12,17 +12,18 @@ import (
 
 // sellConfig contains the configuration params for this Strategy
 type sellConfig struct {
-	DataTypeA              string        `valid:"-" toml:"DATA_TYPE_A"`
-	DataFeedAURL           string        `valid:"-" toml:"DATA_FEED_A_URL"`
-	DataTypeB              string        `valid:"-" toml:"DATA_TYPE_B"`
-	DataFeedBURL           string        `valid:"-" toml:"DATA_FEED_B_URL"`
-	PriceTolerance         float64       `valid:"-" toml:"PRICE_TOLERANCE"`
-	AmountTolerance        float64       `valid:"-" toml:"AMOUNT_TOLERANCE"`
-	AmountOfABase          float64       `valid:"-" toml:"AMOUNT_OF_A_BASE"` // the size of order
-	RateOffsetPercent      float64       `valid:"-" toml:"RATE_OFFSET_PERCENT"`
-	RateOffset             float64       `valid:"-" toml:"RATE_OFFSET"`
-	RateOffsetPercentFirst bool          `valid:"-" toml:"RATE_OFFSET_PERCENT_FIRST"`
-	Levels                 []StaticLevel `valid:"-" toml:"LEVELS"`
+	DataTypeA              string           `valid:"-" toml:"DATA_TYPE_A"`
+	DataFeedAURL           string           `valid:"-" toml:"DATA_FEED_A_URL"`
+	DataTypeB              string           `valid:"-" toml:"DATA_TYPE_B"`
+	DataFeedBURL           string           `valid:"-" toml:"DATA_FEED_B_URL"`
+	PriceTolerance         float64          `valid:"-" toml:"PRICE_TOLERANCE"`
+	AmountTolerance        float64          `valid:"-" toml:"AMOUNT_TOLERANCE"`
+	AmountOfABase          float64          `valid:"-" toml:"AMOUNT_OF_A_BASE"` // the size of order
+	RateOffsetPercent      float64          `valid:"-" toml:"RATE_OFFSET_PERCENT"`
+	RateOffset             float64          `valid:"-" toml:"RATE_OFFSET"`
+	RateOffsetPercentFirst bool             `valid:"-" toml:"RATE_OFFSET_PERCENT_FIRST"`
+	Levels                 []StaticLevel    `valid:"-" toml:"LEVELS"`
+	Randomize              *RandomizeConfig `valid:"-" toml:"RANDOMIZE"` // can be nil, in which case levels are not randomized
 }
 
 // String impl.
@@ -38,6 +39,7 @@ func makeSellStrategy(
 	assetBase *hProtocol.Asset,
 	assetQuote *hProtocol.Asset,
 	config *sellConfig,
+	spreadWidener api.SpreadWidener,
 ) (api.Strategy, error) {
 	pf, e := MakeFeedPair(
 		config.DataTypeA,
@@ -55,13 +57,22 @@ func makeSellStrategy(
 		absolute:     config.RateOffset,
 		percentFirst: config.RateOffsetPercentFirst,
 	}
+	levelProvider, e := maybeRandomizeLevelProvider(
+		makeStaticSpreadLevelProvider(config.Levels, config.AmountOfABase, offset, pf, orderConstraints, spreadWidener),
+		config.Randomize,
+		orderConstraints,
+		false,
+	)
+	if e != nil {
+		return nil, fmt.Errorf("cannot make the sell strategy because we could not randomize the levels: %s", e)
+	}
 	sellSideStrategy := makeSellSideStrategy(
 		sdex,
 		orderConstraints,
 		ieif,
 		assetBase,
 		assetQuote,
-		makeStaticSpreadLevelProvider(config.Levels, config.AmountOfABase, offset, pf, orderConstraints),
+		levelProvider,
 		config.PriceTolerance,
 		config.AmountTolerance,
 		false,
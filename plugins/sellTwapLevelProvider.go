@@ -11,7 +11,6 @@ import (
 	"github.com/stellar/kelp/api"
 	"github.com/stellar/kelp/model"
 	"github.com/stellar/kelp/queries"
-	"github.com/stellar/kelp/support/postgresdb"
 )
 
 const secondsInHour = 60 * 60
@@ -248,8 +247,9 @@ func (r *roundInfo) String() string {
 
 // GetLevels impl.
 func (p *sellTwapLevelProvider) GetLevels(maxAssetBase float64, maxAssetQuote float64) ([]api.Level, error) {
-	now := time.Now().UTC()
-	log.Printf("GetLevels, unix timestamp for 'now' in UTC = %d (%s)\n", now.Unix(), now)
+	// the day boundaries follow the timezone of the volume filters, which is UTC unless configured otherwise
+	now := time.Now().In(p.dowFilter[0].location())
+	log.Printf("GetLevels, unix timestamp for 'now' = %d (%s)\n", now.Unix(), now)
 
 	volFilter := p.dowFilter[now.Weekday()]
 	log.Printf("volumeFilter = %s\n", volFilter.String())
@@ -385,14 +385,10 @@ func (p *sellTwapLevelProvider) makeActiveBucket(now time.Time, volFilter volume
 	if e != nil {
 		return nil, nil, fmt.Errorf("could not fetch base asset cap in base units: %s", e)
 	}
-	queryResult, e := volFilter.dailyVolumeByDateQuery.QueryRow(now.Format(postgresdb.DateFormatString))
+	dailyVolumeValues, _, e := volFilter.queryVolume(now)
 	if e != nil {
 		return nil, nil, fmt.Errorf("could not fetch daily values for today: %s", e)
 	}
-	dailyVolumeValues, ok := queryResult.(*queries.DailyVolume)
-	if !ok {
-		return nil, nil, fmt.Errorf("could not cast query result from dailyValuesByDateQuery as a *queries.DailyVolume, was type '%T'", queryResult)
-	}
 
 	// bucket on bot load
 	if p.activeBucket == nil {
@@ -158,5 +158,15 @@ func makeDowFilter(filterFactory *FilterFactory, dowDailyCap DayOfWeekFilterConf
 		dowVolumeFilters[i] = *vf
 	}
 
+	// the twap strategy sells a daily capacity so every filter needs a daily window, with the same day boundaries so we can pick the day
+	for i, vf := range dowVolumeFilters {
+		if vf.config.window.unit != volumeWindowDaily {
+			return dowVolumeFilters, fmt.Errorf("the %d-th filter needs a daily window but had the window %s", i, vf.config.window)
+		}
+		if vf.location() != dowVolumeFilters[0].location() {
+			return dowVolumeFilters, fmt.Errorf("all filters need to use the same timezone but the %d-th filter used %s and the 0-th filter used %s", i, vf.location(), dowVolumeFilters[0].location())
+		}
+	}
+
 	return dowVolumeFilters, nil
 }
@@ -40,19 +40,28 @@ type staticSpreadLevelProvider struct {
 	offset           rateOffset
 	pf               *api.FeedPair
 	orderConstraints *model.OrderConstraints
+	widener          api.SpreadWidener // can be nil
 }
 
 // ensure it implements the LevelProvider interface
 var _ api.LevelProvider = &staticSpreadLevelProvider{}
 
 // makeStaticSpreadLevelProvider is a factory method
-func makeStaticSpreadLevelProvider(staticLevels []StaticLevel, amountOfBase float64, offset rateOffset, pf *api.FeedPair, orderConstraints *model.OrderConstraints) api.LevelProvider {
+func makeStaticSpreadLevelProvider(
+	staticLevels []StaticLevel,
+	amountOfBase float64,
+	offset rateOffset,
+	pf *api.FeedPair,
+	orderConstraints *model.OrderConstraints,
+	widener api.SpreadWidener,
+) api.LevelProvider {
 	return &staticSpreadLevelProvider{
 		staticLevels:     staticLevels,
 		amountOfBase:     amountOfBase,
 		offset:           offset,
 		pf:               pf,
 		orderConstraints: orderConstraints,
+		widener:          widener,
 	}
 }
 
@@ -67,9 +76,17 @@ func (p *staticSpreadLevelProvider) GetLevels(maxAssetBase float64, maxAssetQuot
 		log.Printf("mid price (adjusted): %.7f\n", midPrice)
 	}
 
+	widening := 0.0
+	if p.widener != nil {
+		widening = p.widener.Widening()
+	}
+	if widening > 0.0 {
+		log.Printf("widening the spread of all levels by %.4f\n", widening)
+	}
+
 	levels := []api.Level{}
 	for _, sl := range p.staticLevels {
-		absoluteSpread := midPrice * sl.SPREAD
+		absoluteSpread := midPrice * (sl.SPREAD + widening)
 		levels = append(levels, api.Level{
 			// we always add here because it is only used in the context of selling so we always charge a higher price to include a spread
 			Price:  *model.NumberFromFloat(midPrice+absoluteSpread, p.orderConstraints.PricePrecision),
@@ -0,0 +1,68 @@
+package plugins
+
+import (
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/kelp/api"
+	"github.com/stellar/kelp/model"
+)
+
+type fixedSpreadWidener float64
+
+func (w fixedSpreadWidener) Widening() float64 {
+	return float64(w)
+}
+
+func TestStaticSpreadLevelProviderWidening(t *testing.T) {
+	testCases := []struct {
+		widener    api.SpreadWidener
+		wantPrices []float64
+	}{
+		{
+			widener:    nil,
+			wantPrices: []float64{2.02, 2.04},
+		}, {
+			widener:    fixedSpreadWidener(0.0),
+			wantPrices: []float64{2.02, 2.04},
+		}, {
+			widener:    fixedSpreadWidener(0.05),
+			wantPrices: []float64{2.12, 2.14},
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(fmt.Sprintf("%v", k.widener), func(t *testing.T) {
+			feedA, e := newFixedFeed("2.0")
+			if !assert.NoError(t, e) {
+				return
+			}
+			feedB, e := newFixedFeed("1.0")
+			if !assert.NoError(t, e) {
+				return
+			}
+			p := makeStaticSpreadLevelProvider(
+				[]StaticLevel{StaticLevel{SPREAD: 0.01, AMOUNT: 1.0}, StaticLevel{SPREAD: 0.02, AMOUNT: 1.0}},
+				10.0,
+				rateOffset{},
+				&api.FeedPair{FeedA: feedA, FeedB: feedB},
+				model.MakeOrderConstraints(7, 7, 0.1),
+				k.widener,
+			)
+
+			levels, e := p.GetLevels(1000.0, 1000.0)
+			if !assert.NoError(t, e) {
+				return
+			}
+			if !assert.Equal(t, len(k.wantPrices), len(levels)) {
+				return
+			}
+			for i, l := range levels {
+				assert.InDelta(t, k.wantPrices[i], l.Price.AsFloat(), 0.0000001)
+				assert.InDelta(t, 10.0, l.Amount.AsFloat(), 0.0000001)
+			}
+		})
+	}
+}
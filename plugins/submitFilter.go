@@ -7,6 +7,7 @@ import (
 
 	hProtocol "github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/model"
 	"github.com/stellar/kelp/support/utils"
 )
 
@@ -52,6 +53,8 @@ func ignoreOfferIDs(ops []txnbuild.Operation) map[int64]bool {
 		switch o := op.(type) {
 		case *txnbuild.ManageSellOffer:
 			ignoreOfferIDs[o.OfferID] = true
+		case *txnbuild.ManageBuyOffer:
+			ignoreOfferIDs[o.OfferID] = true
 		default:
 			continue
 		}
@@ -118,6 +121,24 @@ func filterOps(
 	ops []txnbuild.Operation,
 	fn filterFn,
 ) ([]txnbuild.Operation, error) {
+	// filters work on ManageSellOffer ops so we run any ManageBuyOffer ops through the filters as their equivalent sell offer
+	ops, originalMBOs, e := convertMBOs2MSOs(ops)
+	if e != nil {
+		return nil, fmt.Errorf("could not convert ManageBuyOffer ops: %s", e)
+	}
+
+	// remember the ManageBuyOffer that each op returned by the inner filter fn was made from so we can convert it back below
+	derivedMBOs := map[*txnbuild.ManageSellOffer]*txnbuild.ManageBuyOffer{}
+	innerFn := fn
+	fn = func(op *txnbuild.ManageSellOffer) (*txnbuild.ManageSellOffer, error) {
+		mbo, isMBO := originalMBOs[msoKey(op)]
+		newOp, e := innerFn(op)
+		if e == nil && isMBO && newOp != nil {
+			derivedMBOs[newOp] = mbo
+		}
+		return newOp, e
+	}
+
 	ignoreOfferIds := ignoreOfferIDs(ops)
 	offerMap := makeOfferMap(append(sellingOffers, buyingOffers...))
 	opCounter := filterCounter{}
@@ -193,7 +214,7 @@ func filterOps(
 	}
 
 	// convert all remaining buy and sell offers to delete offers
-	filteredOps, e := handleRemainingOffers(
+	filteredOps, e = handleRemainingOffers(
 		&sellCounter,
 		sellingOffers,
 		ignoreOfferIds,
@@ -218,9 +239,120 @@ func filterOps(
 	log.Printf("filter \"%s\" result B: dropped %d, transformed %d, kept %d from original %d sell offers\n", filterName, sellCounter.dropped, sellCounter.transformed, sellCounter.kept, len(sellingOffers))
 	log.Printf("filter \"%s\" result C: dropped %d, transformed %d, kept %d from original %d buy offers\n", filterName, buyCounter.dropped, buyCounter.transformed, buyCounter.kept, len(buyingOffers))
 	log.Printf("filter \"%s\" result D: len(filteredOps) = %d\n", filterName, len(filteredOps))
+	filteredOps, e = restoreMBOs(filteredOps, originalMBOs, derivedMBOs)
+	if e != nil {
+		return nil, fmt.Errorf("could not restore ManageBuyOffer ops: %s", e)
+	}
 	return filteredOps, nil
 }
 
+// msoKey identifies a ManageSellOffer by value so we can match ops across the copies made when filtering
+func msoKey(mso *txnbuild.ManageSellOffer) string {
+	return fmt.Sprintf("%d|%s:%s|%s:%s|%s|%s", mso.OfferID, mso.Selling.GetCode(), mso.Selling.GetIssuer(), mso.Buying.GetCode(), mso.Buying.GetIssuer(), mso.Price, mso.Amount)
+}
+
+// convertMBOs2MSOs converts any ManageBuyOffer ops to their equivalent ManageSellOffer, returning the converted ops and the original
+// ManageBuyOffer ops keyed by msoKey of the equivalent ManageSellOffer
+func convertMBOs2MSOs(ops []txnbuild.Operation) ([]txnbuild.Operation, map[string]*txnbuild.ManageBuyOffer, error) {
+	converted := []txnbuild.Operation{}
+	originalMBOs := map[string]*txnbuild.ManageBuyOffer{}
+	for _, op := range ops {
+		mbo, ok := op.(*txnbuild.ManageBuyOffer)
+		if !ok {
+			converted = append(converted, op)
+			continue
+		}
+
+		mso, e := convertMBO2MSO(mbo)
+		if e != nil {
+			return nil, nil, e
+		}
+		originalMBOs[msoKey(mso)] = mbo
+		converted = append(converted, mso)
+	}
+	return converted, originalMBOs, nil
+}
+
+// restoreMBOs converts the ops that were made from a ManageBuyOffer back to a ManageBuyOffer. Ops that were passed through the filter unchanged
+// are replaced with the original ManageBuyOffer and ops that were transformed by the filter are converted using the price of the original
+// ManageBuyOffer when the filter did not change the price, so the transformed op is not sent as the lossy inverted ManageSellOffer
+func restoreMBOs(
+	ops []txnbuild.Operation,
+	originalMBOs map[string]*txnbuild.ManageBuyOffer,
+	derivedMBOs map[*txnbuild.ManageSellOffer]*txnbuild.ManageBuyOffer,
+) ([]txnbuild.Operation, error) {
+	if len(originalMBOs) == 0 {
+		return ops, nil
+	}
+
+	restored := []txnbuild.Operation{}
+	for _, op := range ops {
+		mso, ok := op.(*txnbuild.ManageSellOffer)
+		if !ok {
+			restored = append(restored, op)
+			continue
+		}
+
+		if mbo, exists := originalMBOs[msoKey(mso)]; exists {
+			restored = append(restored, mbo)
+		} else if mbo, exists := derivedMBOs[mso]; exists {
+			newMBO, e := convertTransformedMSO2MBO(mso, mbo)
+			if e != nil {
+				return nil, e
+			}
+			restored = append(restored, newMBO)
+		} else {
+			restored = append(restored, op)
+		}
+	}
+	return restored, nil
+}
+
+// convertTransformedMSO2MBO converts a ManageSellOffer that a filter made from the original ManageBuyOffer back to a ManageBuyOffer.
+// The price of the original ManageBuyOffer is kept when the filter only changed the amount
+func convertTransformedMSO2MBO(mso *txnbuild.ManageSellOffer, original *txnbuild.ManageBuyOffer) (*txnbuild.ManageBuyOffer, error) {
+	originalAsMSO, e := convertMBO2MSO(original)
+	if e != nil {
+		return nil, e
+	}
+
+	mboPrice := original.Price
+	if mso.Price != originalAsMSO.Price {
+		msoPrice, e := strconv.ParseFloat(mso.Price, 64)
+		if e != nil {
+			return nil, fmt.Errorf("could not parse price of ManageSellOffer as float64: %s", e)
+		}
+		if msoPrice <= 0 {
+			return nil, fmt.Errorf("invalid price on ManageSellOffer: %s", mso.Price)
+		}
+		mboPrice = strconv.FormatFloat(1/msoPrice, 'f', int(sdexOrderConstraints.PricePrecision), 64)
+	}
+
+	mboAmount := "0"
+	msoAmount, e := strconv.ParseFloat(mso.Amount, 64)
+	if e != nil {
+		return nil, fmt.Errorf("could not parse amount of ManageSellOffer as float64: %s", e)
+	}
+	if msoAmount != 0 {
+		price, e := strconv.ParseFloat(mboPrice, 64)
+		if e != nil {
+			return nil, fmt.Errorf("could not parse price of ManageBuyOffer as float64: %s", e)
+		}
+		// the amount of a sell offer is in units of the selling asset and the amount of a buy offer is in units of the buying asset,
+		// truncate so the converted offer does not exceed the amount allowed by the filter
+		mboAmount = model.NumberFromFloatRoundTruncate(msoAmount/price, sdexOrderConstraints.VolumePrecision).AsString()
+	}
+
+	return &txnbuild.ManageBuyOffer{
+		Selling:       mso.Selling,
+		Buying:        mso.Buying,
+		Amount:        mboAmount,
+		Price:         mboPrice,
+		OfferID:       mso.OfferID,
+		SourceAccount: mso.SourceAccount,
+	}, nil
+}
+
 func selectBuySellList(
 	baseAsset hProtocol.Asset,
 	quoteAsset hProtocol.Asset,
@@ -375,6 +507,34 @@ func handleRemainingOffers(
 	return filteredOps, nil
 }
 
+// convertMBO2MSO converts a ManageBuyOffer to the equivalent ManageSellOffer, i.e. with an inverted price and the amount in units of the selling asset
+func convertMBO2MSO(mbo *txnbuild.ManageBuyOffer) (*txnbuild.ManageSellOffer, error) {
+	price, e := strconv.ParseFloat(mbo.Price, 64)
+	if e != nil {
+		return nil, fmt.Errorf("could not parse price of ManageBuyOffer as float64: %s", e)
+	}
+	if price <= 0 {
+		return nil, fmt.Errorf("invalid price on ManageBuyOffer: %s", mbo.Price)
+	}
+	amount, e := strconv.ParseFloat(mbo.Amount, 64)
+	if e != nil {
+		return nil, fmt.Errorf("could not parse amount of ManageBuyOffer as float64: %s", e)
+	}
+
+	msoAmount := "0"
+	if amount != 0 {
+		msoAmount = strconv.FormatFloat(amount*price, 'f', int(sdexOrderConstraints.VolumePrecision), 64)
+	}
+	return &txnbuild.ManageSellOffer{
+		Selling:       mbo.Selling,
+		Buying:        mbo.Buying,
+		Amount:        msoAmount,
+		Price:         strconv.FormatFloat(1/price, 'f', int(sdexOrderConstraints.PricePrecision), 64),
+		OfferID:       mbo.OfferID,
+		SourceAccount: mbo.SourceAccount,
+	}, nil
+}
+
 func convertOffer2MSO(offer hProtocol.Offer) *txnbuild.ManageSellOffer {
 	return &txnbuild.ManageSellOffer{
 		Selling:       utils.Asset2Asset(offer.Selling),
@@ -0,0 +1,160 @@
+package plugins
+
+import (
+	"fmt"
+	"strconv"
+	"testing"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/support/utils"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestConvertMBO2MSO(t *testing.T) {
+	testCases := []struct {
+		amount     string
+		price      string
+		offerID    int64
+		wantAmount string
+		wantPrice  string
+	}{
+		{
+			amount:     "10.0000000",
+			price:      "0.5000000",
+			offerID:    0,
+			wantAmount: "5.0000000",
+			wantPrice:  "2.0000000",
+		}, {
+			amount:     "100",
+			price:      "4",
+			offerID:    12,
+			wantAmount: "400.0000000",
+			wantPrice:  "0.2500000",
+		}, {
+			amount:     "0",
+			price:      "0.2000000",
+			offerID:    12,
+			wantAmount: "0",
+			wantPrice:  "5.0000000",
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(fmt.Sprintf("%s_%s_%d", kase.amount, kase.price, kase.offerID), func(t *testing.T) {
+			mbo := &txnbuild.ManageBuyOffer{
+				Selling: txnbuild.NativeAsset{},
+				Buying:  txnbuild.CreditAsset{Code: "USD", Issuer: "GBMMZMK2DC4FFP4CAI6KCVNCQ7WLO5A7DQU7EC7WGHRDQBZB763X4OQI"},
+				Amount:  kase.amount,
+				Price:   kase.price,
+				OfferID: kase.offerID,
+			}
+
+			mso, e := convertMBO2MSO(mbo)
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, mbo.Selling, mso.Selling)
+			assert.Equal(t, mbo.Buying, mso.Buying)
+			assert.Equal(t, kase.wantAmount, mso.Amount)
+			assert.Equal(t, kase.wantPrice, mso.Price)
+			assert.Equal(t, kase.offerID, mso.OfferID)
+		})
+	}
+}
+
+func TestRestoreMBOs(t *testing.T) {
+	mbo := &txnbuild.ManageBuyOffer{
+		Selling: txnbuild.NativeAsset{},
+		Buying:  txnbuild.CreditAsset{Code: "USD", Issuer: "GBMMZMK2DC4FFP4CAI6KCVNCQ7WLO5A7DQU7EC7WGHRDQBZB763X4OQI"},
+		Amount:  "10.0000000",
+		Price:   "0.5000000",
+		OfferID: 5,
+	}
+	ops, originalMBOs, e := convertMBOs2MSOs([]txnbuild.Operation{mbo})
+	if !assert.NoError(t, e) {
+		return
+	}
+	if !assert.Equal(t, 1, len(ops)) {
+		return
+	}
+	msoView := ops[0].(*txnbuild.ManageSellOffer)
+
+	// an unchanged copy of the sell offer should be restored to the original buy offer
+	unchanged := *msoView
+	restored, e := restoreMBOs([]txnbuild.Operation{&unchanged}, originalMBOs, map[*txnbuild.ManageSellOffer]*txnbuild.ManageBuyOffer{})
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, []txnbuild.Operation{mbo}, restored)
+
+	// a sell offer with a transformed amount should be converted back to a buy offer at the original price
+	transformed := *msoView
+	transformed.Amount = "1.0000000"
+	restored, e = restoreMBOs([]txnbuild.Operation{&transformed}, originalMBOs, map[*txnbuild.ManageSellOffer]*txnbuild.ManageBuyOffer{&transformed: mbo})
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, []txnbuild.Operation{&txnbuild.ManageBuyOffer{
+		Selling: mbo.Selling,
+		Buying:  mbo.Buying,
+		Amount:  "2.0000000",
+		Price:   "0.5000000",
+		OfferID: 5,
+	}}, restored)
+
+	// a repriced sell offer should be converted back to a buy offer at the new price
+	repriced := *msoView
+	repriced.Amount = "1.0000000"
+	repriced.Price = "4.0000000"
+	restored, e = restoreMBOs([]txnbuild.Operation{&repriced}, originalMBOs, map[*txnbuild.ManageSellOffer]*txnbuild.ManageBuyOffer{&repriced: mbo})
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, []txnbuild.Operation{&txnbuild.ManageBuyOffer{
+		Selling: mbo.Selling,
+		Buying:  mbo.Buying,
+		Amount:  "4.0000000",
+		Price:   "0.2500000",
+		OfferID: 5,
+	}}, restored)
+
+	// sell offers that were not made from a buy offer are left as-is
+	other := &txnbuild.ManageSellOffer{Selling: mbo.Buying, Buying: mbo.Selling, Amount: "1.0000000", Price: "3.0000000"}
+	restored, e = restoreMBOs([]txnbuild.Operation{other}, originalMBOs, map[*txnbuild.ManageSellOffer]*txnbuild.ManageBuyOffer{})
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, []txnbuild.Operation{other}, restored)
+}
+
+func TestFilterOpsKeepsManageBuyOffers(t *testing.T) {
+	quote := txnbuild.CreditAsset{Code: "USD", Issuer: "GBMMZMK2DC4FFP4CAI6KCVNCQ7WLO5A7DQU7EC7WGHRDQBZB763X4OQI"}
+	// buys 10 units of the base asset at 0.5 units of the quote asset each
+	mbo := &txnbuild.ManageBuyOffer{Selling: quote, Buying: txnbuild.NativeAsset{}, Amount: "10.0000000", Price: "0.5000000"}
+
+	// halves the amount of every op
+	halveFn := func(op *txnbuild.ManageSellOffer) (*txnbuild.ManageSellOffer, error) {
+		amount, e := strconv.ParseFloat(op.Amount, 64)
+		if e != nil {
+			return nil, e
+		}
+		opCopy := *op
+		opCopy.Amount = fmt.Sprintf("%.7f", amount/2)
+		return &opCopy, nil
+	}
+
+	filtered, e := filterOps(
+		"halve",
+		utils.Asset2Asset2(txnbuild.NativeAsset{}),
+		utils.Asset2Asset2(quote),
+		[]hProtocol.Offer{},
+		[]hProtocol.Offer{},
+		[]txnbuild.Operation{mbo},
+		halveFn,
+	)
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, []txnbuild.Operation{&txnbuild.ManageBuyOffer{Selling: quote, Buying: txnbuild.NativeAsset{}, Amount: "5.0000000", Price: "0.5000000"}}, filtered)
+}
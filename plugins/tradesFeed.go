@@ -0,0 +1,465 @@
+package plugins
+
+import (
+	"database/sql"
+	"fmt"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/kelp/api"
+	"github.com/stellar/kelp/kelpdb"
+	"github.com/stellar/kelp/model"
+	"github.com/stellar/kelp/support/postgresdb"
+)
+
+// emaLookbackMultiple is how many time constants of history are used by time-based EMAs, older trades have a negligible weight
+const emaLookbackMultiple = 3
+
+// maxHorizonRecords is the maximum number of records horizon returns in a single page
+const maxHorizonRecords = 200
+
+// tradesFeedDB is the kelpdb database used by trades feeds that read from the trades table
+var tradesFeedDB *sql.DB
+
+// SetTradesFeedDB sets the kelpdb database used by trades feeds with the "kelpdb" source
+func SetTradesFeedDB(db *sql.DB) {
+	tradesFeedDB = db
+}
+
+// tradePoint is a trade, or an aggregate of trades, used to compute a price
+type tradePoint struct {
+	ts     time.Time
+	price  float64
+	volume float64
+}
+
+// tradesSource fetches the trades in the window, oldest first
+type tradesSource interface {
+	fetchTrades(window tradesWindow) ([]tradePoint, error)
+}
+
+// tradesWindow is either the last count trades or the trades in the last duration
+type tradesWindow struct {
+	count    int
+	duration time.Duration
+}
+
+// String is the stringer function
+func (w tradesWindow) String() string {
+	if w.count > 0 {
+		return fmt.Sprintf("last %d trades", w.count)
+	}
+	return fmt.Sprintf("last %s", w.duration)
+}
+
+// tradesPriceMode is how the price is computed from the trades, set via the last part of the feed URL:
+//
+//	vwap:<N>m -- volume-weighted average price of the trades in the last N minutes
+//	vwap:<N>t -- volume-weighted average price of the last N trades
+//	ema:<N>m -- exponential moving average of trade prices with a time constant of N minutes
+//	ema:<N>t -- exponential moving average of the last N trade prices with a smoothing factor of 2/(N+1)
+type tradesPriceMode struct {
+	name      string
+	n         float64
+	isMinutes bool
+}
+
+func parseTradesPriceMode(mode string) (*tradesPriceMode, error) {
+	parts := strings.SplitN(mode, ":", 2)
+	if len(parts) != 2 || (parts[0] != "vwap" && parts[0] != "ema") {
+		return nil, fmt.Errorf("trades price mode needs to be formatted as vwap:<N>m, vwap:<N>t, ema:<N>m or ema:<N>t: %s", mode)
+	}
+
+	isMinutes := strings.HasSuffix(parts[1], "m")
+	if !isMinutes && !strings.HasSuffix(parts[1], "t") {
+		return nil, fmt.Errorf("trades price mode window needs to end in 'm' (minutes) or 't' (trades): %s", mode)
+	}
+	n, e := strconv.ParseFloat(parts[1][:len(parts[1])-1], 64)
+	if e != nil {
+		return nil, fmt.Errorf("unable to parse window of trades price mode '%s': %s", mode, e)
+	}
+	if n <= 0 || (!isMinutes && n != math.Trunc(n)) {
+		return nil, fmt.Errorf("window of trades price mode needs to be > 0 and a whole number of trades: %s", mode)
+	}
+
+	return &tradesPriceMode{
+		name:      parts[0],
+		n:         n,
+		isMinutes: isMinutes,
+	}, nil
+}
+
+// window is the trades that need to be fetched to compute the price
+func (m *tradesPriceMode) window() tradesWindow {
+	if !m.isMinutes {
+		return tradesWindow{count: int(m.n)}
+	}
+
+	minutes := m.n
+	if m.name == "ema" {
+		minutes = m.n * emaLookbackMultiple
+	}
+	return tradesWindow{duration: time.Duration(minutes * float64(time.Minute))}
+}
+
+// price computes the price from the trades, which are sorted oldest first
+func (m *tradesPriceMode) price(points []tradePoint) (float64, error) {
+	if len(points) == 0 {
+		return 0, fmt.Errorf("no trades in the window (%s)", m.window())
+	}
+
+	if m.name == "vwap" {
+		notional := 0.0
+		volume := 0.0
+		for _, p := range points {
+			notional += p.price * p.volume
+			volume += p.volume
+		}
+		if volume == 0.0 {
+			return 0, fmt.Errorf("total volume of the trades in the window (%s) was 0.0", m.window())
+		}
+		return notional / volume, nil
+	}
+
+	ema := points[0].price
+	for i := 1; i < len(points); i++ {
+		var alpha float64
+		if m.isMinutes {
+			elapsed := points[i].ts.Sub(points[i-1].ts).Minutes()
+			alpha = 1 - math.Exp(-elapsed/m.n)
+		} else {
+			alpha = 2 / (m.n + 1)
+		}
+		ema += alpha * (points[i].price - ema)
+	}
+	return ema, nil
+}
+
+// tradesFeed derives a price from recent trades instead of quotes
+type tradesFeed struct {
+	name   string
+	source tradesSource
+	mode   *tradesPriceMode
+}
+
+// ensure that it implements PriceFeed
+var _ api.PriceFeed = &tradesFeed{}
+
+// makeTradesFeed makes a trades feed from a URL formatted in one of the following ways:
+//
+//	<exchange>/<base>/<quote>/<mode> -- public trades from an exchange, e.g. ccxt-kraken/XLM/USD/vwap:30m
+//	sdex/<CODE:ISSUER>/<CODE:ISSUER>/<mode> -- horizon trade aggregations or trades, e.g. sdex/COUPON:GA.../XLM:/ema:20t
+//	kelpdb/<market_id>/<mode> -- our own trades from the trades table in kelpdb, e.g. kelpdb/ab12cd34ef/vwap:100t
+func makeTradesFeed(url string) (*tradesFeed, error) {
+	urlParts := strings.Split(url, "/")
+	if len(urlParts) < 3 {
+		return nil, fmt.Errorf("invalid format of trades type URL, needs at least 3 parts after splitting URL by '/', has %d: %s", len(urlParts), url)
+	}
+
+	mode, e := parseTradesPriceMode(urlParts[len(urlParts)-1])
+	if e != nil {
+		return nil, fmt.Errorf("invalid trades type URL: %s", e)
+	}
+
+	var source tradesSource
+	switch urlParts[0] {
+	case "kelpdb":
+		if len(urlParts) != 3 {
+			return nil, fmt.Errorf("invalid format of trades type URL with kelpdb source, needs 3 parts after splitting URL by '/', has %d: %s", len(urlParts), url)
+		}
+		if tradesFeedDB == nil {
+			return nil, fmt.Errorf("trades type URL with kelpdb source needs the bot to be configured with a POSTGRES_DB")
+		}
+		source = &kelpdbTradesSource{db: tradesFeedDB, marketID: urlParts[1]}
+	case "sdex":
+		if len(urlParts) != 4 {
+			return nil, fmt.Errorf("invalid format of trades type URL with sdex source, needs 4 parts after splitting URL by '/', has %d: %s", len(urlParts), url)
+		}
+		source, e = makeSdexTradesSource(urlParts[1], urlParts[2])
+		if e != nil {
+			return nil, fmt.Errorf("cannot make trades feed: %s", e)
+		}
+	default:
+		if len(urlParts) != 4 {
+			return nil, fmt.Errorf("invalid format of trades type URL with exchange source, needs 4 parts after splitting URL by '/', has %d: %s", len(urlParts), url)
+		}
+		source, e = makeExchangeTradesSource(urlParts[0], urlParts[1], urlParts[2])
+		if e != nil {
+			return nil, fmt.Errorf("cannot make trades feed: %s", e)
+		}
+	}
+
+	return &tradesFeed{
+		name:   url,
+		source: source,
+		mode:   mode,
+	}, nil
+}
+
+// GetPrice impl
+func (f *tradesFeed) GetPrice() (float64, error) {
+	points, e := f.source.fetchTrades(f.mode.window())
+	if e != nil {
+		return 0, fmt.Errorf("error while fetching trades for trades feed (%s): %s", f.name, e)
+	}
+
+	price, e := f.mode.price(points)
+	if e != nil {
+		return 0, fmt.Errorf("error while computing price for trades feed (%s): %s", f.name, e)
+	}
+
+	log.Printf("price from trades feed (%s) using %d trades: price=%.10f", f.name, len(points), price)
+	return price, nil
+}
+
+// filterTradePoints keeps the trades in the window, the points need to be sorted oldest first
+func filterTradePoints(points []tradePoint, window tradesWindow, now time.Time) []tradePoint {
+	if window.count > 0 {
+		if len(points) > window.count {
+			return points[len(points)-window.count:]
+		}
+		return points
+	}
+
+	since := now.Add(-window.duration)
+	for i, p := range points {
+		if !p.ts.Before(since) {
+			return points[i:]
+		}
+	}
+	return []tradePoint{}
+}
+
+// exchangeTradesSource reads the public trades of an exchange, limited to the most recent trades the exchange returns
+type exchangeTradesSource struct {
+	tradeAPI api.TradeAPI
+	pair     *model.TradingPair
+}
+
+func makeExchangeTradesSource(exchangeType string, base string, quote string) (*exchangeTradesSource, error) {
+	exchange, e := MakeExchange(exchangeType, true)
+	if e != nil {
+		return nil, fmt.Errorf("error when making the '%s' exchange: %s", exchangeType, e)
+	}
+	baseAsset, e := exchange.GetAssetConverter().FromString(base)
+	if e != nil {
+		return nil, fmt.Errorf("error when converting the base asset: %s", e)
+	}
+	quoteAsset, e := exchange.GetAssetConverter().FromString(quote)
+	if e != nil {
+		return nil, fmt.Errorf("error when converting the quote asset: %s", e)
+	}
+
+	return &exchangeTradesSource{
+		tradeAPI: exchange,
+		pair: &model.TradingPair{
+			Base:  baseAsset,
+			Quote: quoteAsset,
+		},
+	}, nil
+}
+
+func (s *exchangeTradesSource) fetchTrades(window tradesWindow) ([]tradePoint, error) {
+	result, e := s.tradeAPI.GetTrades(s.pair, nil)
+	if e != nil {
+		return nil, fmt.Errorf("error while getting trades from exchange: %s", e)
+	}
+
+	// GetTrades returns the trades sorted oldest first
+	points := []tradePoint{}
+	for _, t := range result.Trades {
+		points = append(points, tradePoint{
+			ts:     time.Unix(0, t.Order.Timestamp.AsInt64()*int64(time.Millisecond)),
+			price:  t.Order.Price.AsFloat(),
+			volume: t.Order.Volume.AsFloat(),
+		})
+	}
+	return filterTradePoints(points, window, time.Now()), nil
+}
+
+// sdexTradesSource reads trade aggregations from horizon for time windows and trades for count windows
+type sdexTradesSource struct {
+	api        *horizonclient.Client
+	baseAsset  *hProtocol.Asset
+	quoteAsset *hProtocol.Asset
+}
+
+func makeSdexTradesSource(base string, quote string) (*sdexTradesSource, error) {
+	baseAsset, e := parseHorizonAsset(base)
+	if e != nil {
+		return nil, fmt.Errorf("unable to convert base asset url to sdex asset: %s", e)
+	}
+	quoteAsset, e := parseHorizonAsset(quote)
+	if e != nil {
+		return nil, fmt.Errorf("unable to convert quote asset url to sdex asset: %s", e)
+	}
+
+	// do not fall back to a default client because it would silently read the trades of a different network
+	if privateSdexHackVar == nil {
+		return nil, fmt.Errorf("cannot make an sdex trades source because the horizon client of the bot has not been set")
+	}
+
+	return &sdexTradesSource{
+		api:        privateSdexHackVar.API,
+		baseAsset:  baseAsset,
+		quoteAsset: quoteAsset,
+	}, nil
+}
+
+func (s *sdexTradesSource) fetchTrades(window tradesWindow) ([]tradePoint, error) {
+	if window.count > 0 {
+		return s.fetchLatestTrades(window.count)
+	}
+	return s.fetchTradeAggregations(window.duration)
+}
+
+func (s *sdexTradesSource) fetchLatestTrades(count int) ([]tradePoint, error) {
+	if count > maxHorizonRecords {
+		return nil, fmt.Errorf("cannot fetch more than %d trades from horizon, requested %d", maxHorizonRecords, count)
+	}
+
+	tradesPage, e := s.api.Trades(horizonclient.TradeRequest{
+		BaseAssetType:      horizonclient.AssetType(s.baseAsset.Type),
+		BaseAssetCode:      s.baseAsset.Code,
+		BaseAssetIssuer:    s.baseAsset.Issuer,
+		CounterAssetType:   horizonclient.AssetType(s.quoteAsset.Type),
+		CounterAssetCode:   s.quoteAsset.Code,
+		CounterAssetIssuer: s.quoteAsset.Issuer,
+		Order:              horizonclient.OrderDesc,
+		Limit:              uint(count),
+	})
+	if e != nil {
+		return nil, fmt.Errorf("error while fetching trades from horizon: %s", e)
+	}
+
+	records := tradesPage.Embedded.Records
+	points := []tradePoint{}
+	// records are newest first, walk them backwards so the points are oldest first
+	for i := len(records) - 1; i >= 0; i-- {
+		t := records[i]
+		volume, e := strconv.ParseFloat(t.BaseAmount, 64)
+		if e != nil {
+			return nil, fmt.Errorf("unable to parse base amount of trade '%s': %s", t.ID, e)
+		}
+		if t.Price == nil || t.Price.D == 0 {
+			return nil, fmt.Errorf("trade '%s' does not have a valid price", t.ID)
+		}
+
+		points = append(points, tradePoint{
+			ts:     t.LedgerCloseTime,
+			price:  float64(t.Price.N) / float64(t.Price.D),
+			volume: volume,
+		})
+	}
+	return points, nil
+}
+
+// tradeAggregationResolution returns the smallest resolution supported by horizon that fits the window in a single page of trade aggregations.
+// The window is aligned to the resolution so it can span one more bucket than duration/resolution
+func tradeAggregationResolution(duration time.Duration) (time.Duration, error) {
+	resolutions := []time.Duration{horizonclient.OneMinute, horizonclient.FiveMinutes, horizonclient.FifteenMinutes, horizonclient.OneHour}
+	for _, r := range resolutions {
+		if duration/r+1 <= maxHorizonRecords {
+			return r, nil
+		}
+	}
+	largest := resolutions[len(resolutions)-1]
+	return 0, fmt.Errorf("cannot fetch trade aggregations for a window of %s because it needs more than %d aggregations at the largest resolution of %s", duration, maxHorizonRecords, largest)
+}
+
+func (s *sdexTradesSource) fetchTradeAggregations(duration time.Duration) ([]tradePoint, error) {
+	resolution, e := tradeAggregationResolution(duration)
+	if e != nil {
+		return nil, e
+	}
+
+	// horizon needs the start and end times to be aligned to the resolution
+	endTime := time.Now().Truncate(resolution).Add(resolution)
+	startTime := endTime.Add(-duration).Truncate(resolution)
+	aggregationsPage, e := s.api.TradeAggregations(horizonclient.TradeAggregationRequest{
+		StartTime:          startTime,
+		EndTime:            endTime,
+		Resolution:         resolution,
+		BaseAssetType:      horizonclient.AssetType(s.baseAsset.Type),
+		BaseAssetCode:      s.baseAsset.Code,
+		BaseAssetIssuer:    s.baseAsset.Issuer,
+		CounterAssetType:   horizonclient.AssetType(s.quoteAsset.Type),
+		CounterAssetCode:   s.quoteAsset.Code,
+		CounterAssetIssuer: s.quoteAsset.Issuer,
+		Order:              horizonclient.OrderAsc,
+		Limit:              uint(maxHorizonRecords),
+	})
+	if e != nil {
+		return nil, fmt.Errorf("error while fetching trade aggregations from horizon: %s", e)
+	}
+
+	points := []tradePoint{}
+	for _, a := range aggregationsPage.Embedded.Records {
+		baseVolume, e := strconv.ParseFloat(a.BaseVolume, 64)
+		if e != nil {
+			return nil, fmt.Errorf("unable to parse base volume of trade aggregation: %s", e)
+		}
+		counterVolume, e := strconv.ParseFloat(a.CounterVolume, 64)
+		if e != nil {
+			return nil, fmt.Errorf("unable to parse counter volume of trade aggregation: %s", e)
+		}
+		if baseVolume == 0.0 {
+			continue
+		}
+
+		// each aggregation is treated as a single trade at its volume-weighted price
+		points = append(points, tradePoint{
+			ts:     time.Unix(0, a.Timestamp*int64(time.Millisecond)),
+			price:  counterVolume / baseVolume,
+			volume: baseVolume,
+		})
+	}
+	return points, nil
+}
+
+// kelpdbTradesSource reads our own trades from the trades table in kelpdb
+type kelpdbTradesSource struct {
+	db       *sql.DB
+	marketID string
+}
+
+func (s *kelpdbTradesSource) fetchTrades(window tradesWindow) ([]tradePoint, error) {
+	var rows *sql.Rows
+	var e error
+	if window.count > 0 {
+		rows, e = s.db.Query(kelpdb.SqlQueryLatestTrades, s.marketID, window.count)
+	} else {
+		since := time.Now().UTC().Add(-window.duration).Format(postgresdb.TimestampFormatString)
+		rows, e = s.db.Query(kelpdb.SqlQueryTradesSince, s.marketID, since)
+	}
+	if e != nil {
+		return nil, fmt.Errorf("could not query trades table for market '%s': %s", s.marketID, e)
+	}
+	defer rows.Close()
+
+	points := []tradePoint{}
+	for rows.Next() {
+		var p tradePoint
+		e = rows.Scan(&p.ts, &p.price, &p.volume)
+		if e != nil {
+			return nil, fmt.Errorf("could not read trade from trades table for market '%s': %s", s.marketID, e)
+		}
+		points = append(points, p)
+	}
+	if e = rows.Err(); e != nil {
+		return nil, fmt.Errorf("error while reading trades from trades table for market '%s': %s", s.marketID, e)
+	}
+
+	if window.count > 0 {
+		// the latest trades are queried newest first
+		for i, j := 0, len(points)-1; i < j; i, j = i+1, j-1 {
+			points[i], points[j] = points[j], points[i]
+		}
+	}
+	return points, nil
+}
@@ -0,0 +1,134 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// testTradesSource returns its trades filtered to the requested window
+type testTradesSource struct {
+	points []tradePoint
+	now    time.Time
+}
+
+func (s *testTradesSource) fetchTrades(window tradesWindow) ([]tradePoint, error) {
+	return filterTradePoints(s.points, window, s.now), nil
+}
+
+func TestTradesFeed(t *testing.T) {
+	now := time.Unix(1600000000, 0)
+	points := []tradePoint{
+		{ts: now.Add(-90 * time.Minute), price: 2.0, volume: 100},
+		{ts: now.Add(-20 * time.Minute), price: 1.0, volume: 10},
+		{ts: now.Add(-10 * time.Minute), price: 1.2, volume: 30},
+		// a tiny trade at an extreme price is barely reflected in the vwap
+		{ts: now.Add(-1 * time.Minute), price: 5.0, volume: 0.1},
+	}
+
+	testCases := []struct {
+		mode      string
+		wantPrice float64
+		wantError bool
+	}{
+		{
+			mode:      "vwap:30m",
+			wantPrice: (1.0*10 + 1.2*30 + 5.0*0.1) / 40.1,
+		}, {
+			mode:      "vwap:2t",
+			wantPrice: (1.2*30 + 5.0*0.1) / 30.1,
+		}, {
+			mode:      "ema:3t",
+			wantPrice: (1.0+1.2)/2/2 + 5.0/2,
+		}, {
+			mode:      "vwap:0.5m",
+			wantError: true,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.mode, func(t *testing.T) {
+			mode, e := parseTradesPriceMode(kase.mode)
+			if !assert.NoError(t, e) {
+				return
+			}
+			f := &tradesFeed{
+				name:   kase.mode,
+				source: &testTradesSource{points: points, now: now},
+				mode:   mode,
+			}
+
+			price, e := f.GetPrice()
+			if kase.wantError {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.InDelta(t, kase.wantPrice, price, 0.0000001)
+		})
+	}
+}
+
+func TestParseTradesPriceMode(t *testing.T) {
+	testCases := []struct {
+		mode       string
+		wantWindow tradesWindow
+		wantError  bool
+	}{
+		{mode: "vwap:30m", wantWindow: tradesWindow{duration: 30 * time.Minute}},
+		{mode: "vwap:100t", wantWindow: tradesWindow{count: 100}},
+		{mode: "ema:10m", wantWindow: tradesWindow{duration: 30 * time.Minute}},
+		{mode: "ema:20t", wantWindow: tradesWindow{count: 20}},
+		{mode: "twap:30m", wantError: true},
+		{mode: "vwap:30", wantError: true},
+		{mode: "vwap:1.5t", wantError: true},
+		{mode: "ema:0t", wantError: true},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.mode, func(t *testing.T) {
+			mode, e := parseTradesPriceMode(kase.mode)
+			if kase.wantError {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, kase.wantWindow, mode.window())
+		})
+	}
+}
+
+func TestTradeAggregationResolution(t *testing.T) {
+	testCases := []struct {
+		duration       time.Duration
+		wantResolution time.Duration
+		wantError      bool
+	}{
+		{duration: 30 * time.Minute, wantResolution: time.Minute},
+		{duration: 199 * time.Minute, wantResolution: time.Minute},
+		{duration: 200 * time.Minute, wantResolution: 5 * time.Minute},
+		{duration: 24 * time.Hour, wantResolution: 15 * time.Minute},
+		{duration: 199 * time.Hour, wantResolution: time.Hour},
+		{duration: 200 * time.Hour, wantError: true},
+		{duration: 30 * 24 * time.Hour, wantError: true},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.duration.String(), func(t *testing.T) {
+			resolution, e := tradeAggregationResolution(kase.duration)
+			if kase.wantError {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, kase.wantResolution, resolution)
+		})
+	}
+}
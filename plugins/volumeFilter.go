@@ -10,6 +10,7 @@ import (
 
 	hProtocol "github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/api"
 	"github.com/stellar/kelp/model"
 	"github.com/stellar/kelp/queries"
 	"github.com/stellar/kelp/support/postgresdb"
@@ -37,16 +38,22 @@ func parseVolumeFilterMode(mode string) (volumeFilterMode, error) {
 type VolumeFilterConfig struct {
 	BaseAssetCapInBaseUnits  *float64
 	BaseAssetCapInQuoteUnits *float64
-	action                   queries.DailyVolumeAction
-	mode                     volumeFilterMode
-	additionalMarketIDs      []string // can be nil
-	optionalAccountIDs       []string // can be nil
+	// BaseAssetCapInNotionalUnits caps the value of the base asset in the reference currency of the notionalFeed
+	BaseAssetCapInNotionalUnits *float64
+	action                      queries.DailyVolumeAction
+	mode                        volumeFilterMode
+	additionalMarketIDs         []string // can be nil
+	optionalAccountIDs          []string // can be nil
+	window                      volumeFilterWindow
+	notionalFeed                api.PriceFeed // price of the quote asset in the reference currency, only needed for notional caps
 }
 
 type limitParameters struct {
-	baseAssetCapInBaseUnits  *float64
-	baseAssetCapInQuoteUnits *float64
-	mode                     volumeFilterMode
+	baseAssetCapInBaseUnits     *float64
+	baseAssetCapInQuoteUnits    *float64
+	baseAssetCapInNotionalUnits *float64
+	notionalPrice               float64 // price of the quote asset in the reference currency, only used for notional caps
+	mode                        volumeFilterMode
 }
 
 type volumeFilter struct {
@@ -56,9 +63,11 @@ type volumeFilter struct {
 	quoteAsset             hProtocol.Asset
 	config                 *VolumeFilterConfig
 	dailyVolumeByDateQuery *queries.DailyVolumeByDate
+	// volumeByTimeRangeQuery is used instead of dailyVolumeByDateQuery for windows other than the calendar day in UTC
+	volumeByTimeRangeQuery *queries.VolumeByTimeRange
 }
 
-// makeFilterVolume makes a submit filter that limits orders placed based on the daily volume traded
+// makeFilterVolume makes a submit filter that limits orders placed based on the volume traded in the configured window
 func makeFilterVolume(
 	configValue string,
 	exchangeName string,
@@ -82,9 +91,18 @@ func makeFilterVolume(
 	marketID := MakeMarketID(exchangeName, baseAssetString, quoteAssetString)
 	// note that append(s, nil) is valid
 	marketIDs := utils.Dedupe(append([]string{marketID}, config.additionalMarketIDs...))
-	dailyVolumeByDateQuery, e := queries.MakeDailyVolumeByDateForMarketIdsAction(db, marketIDs, config.action, config.optionalAccountIDs)
-	if e != nil {
-		return nil, fmt.Errorf("could not make daily volume by date Query: %s", e)
+	var dailyVolumeByDateQuery *queries.DailyVolumeByDate
+	var volumeByTimeRangeQuery *queries.VolumeByTimeRange
+	if config.window.isDailyUTC() {
+		dailyVolumeByDateQuery, e = queries.MakeDailyVolumeByDateForMarketIdsAction(db, marketIDs, config.action, config.optionalAccountIDs)
+		if e != nil {
+			return nil, fmt.Errorf("could not make daily volume by date Query: %s", e)
+		}
+	} else {
+		volumeByTimeRangeQuery, e = queries.MakeVolumeByTimeRangeForMarketIdsAction(db, marketIDs, config.action, config.optionalAccountIDs)
+		if e != nil {
+			return nil, fmt.Errorf("could not make volume by time range Query: %s", e)
+		}
 	}
 
 	e = config.Validate()
@@ -92,6 +110,10 @@ func makeFilterVolume(
 		return nil, fmt.Errorf("invalid config: %s", e)
 	}
 
+	if config.BaseAssetCapInNotionalUnits != nil && config.notionalFeed == nil {
+		return nil, fmt.Errorf("invalid config: the notional cap needs a notional feed, set NOTIONAL_FEED_TYPE and NOTIONAL_FEED_URL in the trader config")
+	}
+
 	return &volumeFilter{
 		name:                   "volumeFilter",
 		configValue:            configValue,
@@ -99,6 +121,7 @@ func makeFilterVolume(
 		quoteAsset:             quoteAsset,
 		config:                 config,
 		dailyVolumeByDateQuery: dailyVolumeByDateQuery,
+		volumeByTimeRangeQuery: volumeByTimeRangeQuery,
 	}, nil
 }
 
@@ -110,7 +133,11 @@ func (c *VolumeFilterConfig) Validate() error {
 		return fmt.Errorf("invalid asset caps: only one asset cap can be non-nil, but both are non-nil")
 	}
 
-	if c.BaseAssetCapInBaseUnits == nil && c.BaseAssetCapInQuoteUnits == nil {
+	if c.BaseAssetCapInNotionalUnits != nil && (c.BaseAssetCapInBaseUnits != nil || c.BaseAssetCapInQuoteUnits != nil) {
+		return fmt.Errorf("invalid asset caps: only one asset cap can be non-nil, but the notional cap is non-nil along with another cap")
+	}
+
+	if c.BaseAssetCapInBaseUnits == nil && c.BaseAssetCapInQuoteUnits == nil && c.BaseAssetCapInNotionalUnits == nil {
 		return fmt.Errorf("invalid asset caps: only one asset cap can be non-nil, but both are nil")
 	}
 
@@ -122,48 +149,72 @@ func (c *VolumeFilterConfig) Validate() error {
 		return fmt.Errorf("could not parse action: %s", e)
 	}
 
+	if c.window.unit == volumeWindowRolling && c.window.rollingDuration <= 0 {
+		return fmt.Errorf("invalid window: rolling windows need a duration > 0")
+	}
+
 	return nil
 }
 
 // String is the stringer method
 func (c *VolumeFilterConfig) String() string {
-	return fmt.Sprintf("VolumeFilterConfig[BaseAssetCapInBaseUnits=%s, BaseAssetCapInQuoteUnits=%s, mode=%s, action=%s, additionalMarketIDs=%v, optionalAccountIDs=%v]",
-		utils.CheckedFloatPtr(c.BaseAssetCapInBaseUnits), utils.CheckedFloatPtr(c.BaseAssetCapInQuoteUnits), c.mode, c.action, c.additionalMarketIDs, c.optionalAccountIDs)
+	return fmt.Sprintf("VolumeFilterConfig[BaseAssetCapInBaseUnits=%s, BaseAssetCapInQuoteUnits=%s, BaseAssetCapInNotionalUnits=%s, mode=%s, action=%s, additionalMarketIDs=%v, optionalAccountIDs=%v, window=%s]",
+		utils.CheckedFloatPtr(c.BaseAssetCapInBaseUnits), utils.CheckedFloatPtr(c.BaseAssetCapInQuoteUnits), utils.CheckedFloatPtr(c.BaseAssetCapInNotionalUnits), c.mode, c.action, c.additionalMarketIDs, c.optionalAccountIDs, c.window)
 }
 
 func (f *volumeFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol.Offer, buyingOffers []hProtocol.Offer) ([]txnbuild.Operation, error) {
-	dateString := time.Now().UTC().Format(postgresdb.DateFormatString)
 	// TODO for flipped marketIDs
-	queryResult, e := f.dailyVolumeByDateQuery.QueryRow(dateString)
+	dailyValuesBaseSold, windowString, e := f.queryVolume(time.Now())
 	if e != nil {
-		return nil, fmt.Errorf("could not load dailyValuesByDate for today (%s): %s", dateString, e)
-	}
-	dailyValuesBaseSold, ok := queryResult.(*queries.DailyVolume)
-	if !ok {
-		return nil, fmt.Errorf("incorrect type returned from DailyVolumeByDate query, expecting '*queries.DailyVolume' but was '%T'", queryResult)
+		return nil, fmt.Errorf("could not load volume for the current window: %s", e)
 	}
 
-	log.Printf("dailyValuesByDate for today (%s): baseSoldUnits = %.8f %s, quoteCostUnits = %.8f %s (%s)\n",
-		dateString, dailyValuesBaseSold.BaseVol, utils.Asset2String(f.baseAsset), dailyValuesBaseSold.QuoteVol, utils.Asset2String(f.quoteAsset), f.config)
+	log.Printf("volume for the current window (%s): baseSoldUnits = %.8f %s, quoteCostUnits = %.8f %s, notionalUnits = %.8f (%s)\n",
+		windowString, dailyValuesBaseSold.BaseVol, utils.Asset2String(f.baseAsset), dailyValuesBaseSold.QuoteVol, utils.Asset2String(f.quoteAsset), dailyValuesBaseSold.NotionalVol, f.config)
+
+	notionalPrice := 0.0
+	if f.config.BaseAssetCapInNotionalUnits != nil {
+		notionalPrice, e = f.config.notionalFeed.GetPrice()
+		if e != nil {
+			return nil, fmt.Errorf("could not fetch notional price of the quote asset: %s", e)
+		}
+		if notionalPrice <= 0.0 {
+			return nil, fmt.Errorf("notional price of the quote asset needs to be > 0.0, was %.10f", notionalPrice)
+		}
+
+		// trades on our market without a notional value (such as backfilled trades) are valued at the current notional price so they still
+		// count towards the cap, trades on the other markets can have a different quote asset so they need a notional value to be counted
+		if dailyValuesBaseSold.NumMissingNotional > 0 {
+			missingNotional := dailyValuesBaseSold.MissingNotionalQuoteVol * notionalPrice
+			log.Printf("%d trades in the current window do not have a notional value, counting the ones on this market (quote volume = %.8f) at the current notional price as notionalUnits = %.8f, the ones on other markets are not counted\n",
+				dailyValuesBaseSold.NumMissingNotional, dailyValuesBaseSold.MissingNotionalQuoteVol, missingNotional)
+			dailyValuesBaseSold.NotionalVol += missingNotional
+		}
+	}
 
 	// daily on-the-books
 	dailyOTB := &VolumeFilterConfig{
-		BaseAssetCapInBaseUnits:  &dailyValuesBaseSold.BaseVol,
-		BaseAssetCapInQuoteUnits: &dailyValuesBaseSold.QuoteVol,
+		BaseAssetCapInBaseUnits:     &dailyValuesBaseSold.BaseVol,
+		BaseAssetCapInQuoteUnits:    &dailyValuesBaseSold.QuoteVol,
+		BaseAssetCapInNotionalUnits: &dailyValuesBaseSold.NotionalVol,
 	}
 	// daily to-be-booked starts out as empty and accumulates the values of the operations
 	dailyTbbBase := 0.0
 	dailyTbbSellQuote := 0.0
+	dailyTbbNotional := 0.0
 	dailyTBB := &VolumeFilterConfig{
-		BaseAssetCapInBaseUnits:  &dailyTbbBase,
-		BaseAssetCapInQuoteUnits: &dailyTbbSellQuote,
+		BaseAssetCapInBaseUnits:     &dailyTbbBase,
+		BaseAssetCapInQuoteUnits:    &dailyTbbSellQuote,
+		BaseAssetCapInNotionalUnits: &dailyTbbNotional,
 	}
 
 	innerFn := func(op *txnbuild.ManageSellOffer) (*txnbuild.ManageSellOffer, error) {
 		limitParameters := limitParameters{
-			baseAssetCapInBaseUnits:  f.config.BaseAssetCapInBaseUnits,
-			baseAssetCapInQuoteUnits: f.config.BaseAssetCapInQuoteUnits,
-			mode:                     f.config.mode,
+			baseAssetCapInBaseUnits:     f.config.BaseAssetCapInBaseUnits,
+			baseAssetCapInQuoteUnits:    f.config.BaseAssetCapInQuoteUnits,
+			baseAssetCapInNotionalUnits: f.config.BaseAssetCapInNotionalUnits,
+			notionalPrice:               notionalPrice,
+			mode:                        f.config.mode,
 		}
 		return volumeFilterFn(dailyOTB, dailyTBB, op, f.baseAsset, f.quoteAsset, limitParameters)
 	}
@@ -174,6 +225,46 @@ func (f *volumeFilter) Apply(ops []txnbuild.Operation, sellingOffers []hProtocol
 	return ops, nil
 }
 
+// queryVolume fetches the volume traded in the window that contains now, along with a description of the window for logging
+func (f *volumeFilter) queryVolume(now time.Time) (*queries.DailyVolume, string, error) {
+	var queryResult interface{}
+	var windowString string
+	var e error
+	if f.dailyVolumeByDateQuery != nil {
+		windowString = now.UTC().Format(postgresdb.DateFormatString)
+		queryResult, e = f.dailyVolumeByDateQuery.QueryRow(windowString)
+		if e != nil {
+			return nil, "", fmt.Errorf("could not load dailyValuesByDate for today (%s): %s", windowString, e)
+		}
+	} else if f.volumeByTimeRangeQuery != nil {
+		start, end := f.config.window.bounds(now)
+		windowString = fmt.Sprintf("%s from %s to %s", f.config.window, start.Format(time.RFC3339), end.Format(time.RFC3339))
+		queryResult, e = f.volumeByTimeRangeQuery.QueryRow(
+			start.UTC().Format(postgresdb.TimestampFormatString),
+			end.UTC().Format(postgresdb.TimestampFormatString),
+		)
+		if e != nil {
+			return nil, "", fmt.Errorf("could not load volumeByTimeRange for window (%s): %s", windowString, e)
+		}
+	} else {
+		return nil, "", fmt.Errorf("volume filter has no query, was it made using makeFilterVolume?")
+	}
+
+	volume, ok := queryResult.(*queries.DailyVolume)
+	if !ok {
+		return nil, "", fmt.Errorf("incorrect type returned from volume query, expecting '*queries.DailyVolume' but was '%T'", queryResult)
+	}
+	return volume, windowString, nil
+}
+
+// location is the timezone of the boundaries of the window of this filter
+func (f *volumeFilter) location() *time.Location {
+	if f.config == nil {
+		return time.UTC
+	}
+	return f.config.window.getLocation()
+}
+
 func volumeFilterFn(dailyOTB *VolumeFilterConfig, dailyTBBAccumulator *VolumeFilterConfig, op *txnbuild.ManageSellOffer, baseAsset hProtocol.Asset, quoteAsset hProtocol.Asset, lp limitParameters) (*txnbuild.ManageSellOffer, error) {
 	isFilterApplicable, e := offerSameTypeAsFilter(dailyOTB, op, baseAsset, quoteAsset)
 	if e != nil {
@@ -203,10 +294,13 @@ func volumeFilterFn(dailyOTB *VolumeFilterConfig, dailyTBBAccumulator *VolumeFil
 	}
 
 	// capPrice is used when computing amounts to sell or buy
-	// it's the offer price when capping on quote, and 1.0 when capping on base
+	// it's the offer price when capping on quote, 1.0 when capping on base, and the offer price converted to the reference currency
+	// when capping on notional
 	capPrice := offerPrice
 	if lp.baseAssetCapInBaseUnits != nil {
 		capPrice = 1.0
+	} else if lp.baseAssetCapInNotionalUnits != nil {
+		capPrice = offerPrice * lp.notionalPrice
 	}
 
 	// extracts from base or quote side, depending on filter
@@ -218,7 +312,7 @@ func volumeFilterFn(dailyOTB *VolumeFilterConfig, dailyTBBAccumulator *VolumeFil
 	// if projected is under the cap, update the tbb and return the original op
 	projected := otb + tbb + offerAmount*capPrice
 	if projected <= cap {
-		dailyTBBAccumulator = updateTBB(dailyTBBAccumulator, offerAmount, offerPrice)
+		dailyTBBAccumulator = updateTBB(dailyTBBAccumulator, offerAmount, offerPrice, lp.notionalPrice)
 		return op, nil
 	}
 
@@ -232,7 +326,7 @@ func volumeFilterFn(dailyOTB *VolumeFilterConfig, dailyTBBAccumulator *VolumeFil
 	if newOfferAmount <= 0 {
 		return nil, nil
 	}
-	dailyTBBAccumulator = updateTBB(dailyTBBAccumulator, newOfferAmount, offerPrice)
+	dailyTBBAccumulator = updateTBB(dailyTBBAccumulator, newOfferAmount, offerPrice, lp.notionalPrice)
 	// if we have a buy operation, we want to make sure buy ops have the same relationship between price and amount
 	// to do this, we apply the same amount adjustment as `makeBuyOpAmtPrice`
 	// The following conversion is done above on input:
@@ -270,13 +364,24 @@ func extractAllCaps(dailyOTB *VolumeFilterConfig, dailyTBB *VolumeFilterConfig,
 		return *dailyOTB.BaseAssetCapInQuoteUnits, *dailyTBB.BaseAssetCapInQuoteUnits, *lp.baseAssetCapInQuoteUnits, nil
 	}
 
+	if lp.baseAssetCapInNotionalUnits != nil {
+		if dailyOTB.BaseAssetCapInNotionalUnits == nil || dailyTBB.BaseAssetCapInNotionalUnits == nil {
+			return -1, -1, -1, fmt.Errorf("notional values were not provided for a notional cap")
+		}
+		return *dailyOTB.BaseAssetCapInNotionalUnits, *dailyTBB.BaseAssetCapInNotionalUnits, *lp.baseAssetCapInNotionalUnits, nil
+	}
+
 	// should never reach this code - means that the configs were not validated properly
 	return -1, -1, -1, fmt.Errorf("found two nil filters")
 }
 
-func updateTBB(tbb *VolumeFilterConfig, amount float64, price float64) *VolumeFilterConfig {
+func updateTBB(tbb *VolumeFilterConfig, amount float64, price float64, notionalPrice float64) *VolumeFilterConfig {
 	*tbb.BaseAssetCapInBaseUnits += amount
 	*tbb.BaseAssetCapInQuoteUnits += amount * price
+	// the notional accumulator is only set when the filter has a notional cap
+	if tbb.BaseAssetCapInNotionalUnits != nil {
+		*tbb.BaseAssetCapInNotionalUnits += amount * price * notionalPrice
+	}
 	return tbb
 }
 
@@ -0,0 +1,142 @@
+package plugins
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type volumeWindowUnit string
+
+// type of volumeWindowUnit
+const (
+	volumeWindowHourly  volumeWindowUnit = "hourly"
+	volumeWindowDaily   volumeWindowUnit = "daily"
+	volumeWindowWeekly  volumeWindowUnit = "weekly"
+	volumeWindowMonthly volumeWindowUnit = "monthly"
+	volumeWindowRolling volumeWindowUnit = "rolling"
+)
+
+// volumeFilterTimezonePrefix is the prefix of the modifier that sets the timezone of the boundaries of calendar windows
+const volumeFilterTimezonePrefix = "tz="
+
+var rollingWindowRegex *regexp.Regexp
+
+func init() {
+	rxp, e := regexp.Compile("^rolling([0-9]+)(h|d)$")
+	if e != nil {
+		panic("unable to compile rollingWindow regexp")
+	}
+	rollingWindowRegex = rxp
+}
+
+// volumeFilterWindow is the window of time over which the volume is capped. Calendar windows (hourly, daily, weekly, monthly) reset on
+// their boundaries in the configured location, whereas rolling windows always cover the duration leading up to now
+type volumeFilterWindow struct {
+	unit            volumeWindowUnit
+	rollingDuration time.Duration // only set for rolling windows
+	location        *time.Location
+}
+
+// makeDefaultVolumeFilterWindow is the calendar day in UTC which was the only window supported before windows were configurable
+func makeDefaultVolumeFilterWindow() volumeFilterWindow {
+	return volumeFilterWindow{
+		unit:     volumeWindowDaily,
+		location: time.UTC,
+	}
+}
+
+// parseVolumeFilterWindow parses the window, which is one of hourly, daily, weekly, monthly or rolling<N>h / rolling<N>d (ex: rolling24h)
+func parseVolumeFilterWindow(window string) (volumeFilterWindow, error) {
+	switch volumeWindowUnit(window) {
+	case volumeWindowHourly, volumeWindowDaily, volumeWindowWeekly, volumeWindowMonthly:
+		return volumeFilterWindow{
+			unit:     volumeWindowUnit(window),
+			location: time.UTC,
+		}, nil
+	}
+
+	matches := rollingWindowRegex.FindStringSubmatch(window)
+	if matches == nil {
+		return volumeFilterWindow{}, fmt.Errorf("invalid window '%s', needs to be one of hourly, daily, weekly, monthly or rolling<N>h / rolling<N>d (ex: rolling24h)", window)
+	}
+	n, e := strconv.Atoi(matches[1])
+	if e != nil {
+		return volumeFilterWindow{}, fmt.Errorf("could not parse the length of the rolling window '%s': %s", window, e)
+	}
+	if n <= 0 {
+		return volumeFilterWindow{}, fmt.Errorf("the length of the rolling window '%s' needs to be > 0", window)
+	}
+	unitDuration := time.Hour
+	if matches[2] == "d" {
+		unitDuration = 24 * time.Hour
+	}
+	return volumeFilterWindow{
+		unit:            volumeWindowRolling,
+		rollingDuration: time.Duration(n) * unitDuration,
+		location:        time.UTC,
+	}, nil
+}
+
+// setTimezone parses a modifier like tz=America/New_York and uses it for the boundaries of the window
+func (w *volumeFilterWindow) setTimezone(modifier string) error {
+	if !strings.HasPrefix(modifier, volumeFilterTimezonePrefix) {
+		return fmt.Errorf("invalid prefix for timezone modifier '%s'", modifier)
+	}
+	if w.unit == volumeWindowRolling {
+		return fmt.Errorf("timezone modifier '%s' cannot be used with rolling windows since they do not have calendar boundaries", modifier)
+	}
+
+	location, e := time.LoadLocation(strings.TrimPrefix(modifier, volumeFilterTimezonePrefix))
+	if e != nil {
+		return fmt.Errorf("could not load timezone from modifier '%s': %s", modifier, e)
+	}
+	w.location = location
+	return nil
+}
+
+// isDailyUTC returns true if the window is the calendar day in UTC, which can use the daily volume query directly
+func (w volumeFilterWindow) isDailyUTC() bool {
+	return w.unit == volumeWindowDaily && w.getLocation() == time.UTC
+}
+
+func (w volumeFilterWindow) getLocation() *time.Location {
+	if w.location == nil {
+		return time.UTC
+	}
+	return w.location
+}
+
+// bounds returns the [start, end) range of the window that contains now
+func (w volumeFilterWindow) bounds(now time.Time) (time.Time, time.Time) {
+	if w.unit == volumeWindowRolling {
+		return now.Add(-w.rollingDuration), now
+	}
+
+	t := now.In(w.getLocation())
+	switch w.unit {
+	case volumeWindowHourly:
+		start := time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+		return start, start.Add(time.Hour)
+	case volumeWindowWeekly:
+		// weeks start on Monday
+		daysSinceMonday := (int(t.Weekday()) + 6) % 7
+		start := time.Date(t.Year(), t.Month(), t.Day()-daysSinceMonday, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 0, 7)
+	case volumeWindowMonthly:
+		start := time.Date(t.Year(), t.Month(), 1, 0, 0, 0, 0, t.Location())
+		return start, start.AddDate(0, 1, 0)
+	}
+	start := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	return start, start.AddDate(0, 0, 1)
+}
+
+// String is the Stringer method
+func (w volumeFilterWindow) String() string {
+	if w.unit == volumeWindowRolling {
+		return fmt.Sprintf("%s(%s)", w.unit, w.rollingDuration)
+	}
+	return fmt.Sprintf("%s(%s)", w.unit, w.getLocation())
+}
@@ -0,0 +1,90 @@
+package plugins
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVolumeFilterWindowBounds(t *testing.T) {
+	newYork, e := time.LoadLocation("America/New_York")
+	if !assert.NoError(t, e) {
+		return
+	}
+	// Wednesday 2020-01-22 03:30 UTC, which is Tuesday 2020-01-21 22:30 in New York
+	now := time.Date(2020, 1, 22, 3, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		window    string
+		timezone  string
+		wantStart time.Time
+		wantEnd   time.Time
+	}{
+		{
+			window:    "daily",
+			wantStart: time.Date(2020, 1, 22, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2020, 1, 23, 0, 0, 0, 0, time.UTC),
+		}, {
+			window:    "daily",
+			timezone:  "tz=America/New_York",
+			wantStart: time.Date(2020, 1, 21, 0, 0, 0, 0, newYork),
+			wantEnd:   time.Date(2020, 1, 22, 0, 0, 0, 0, newYork),
+		}, {
+			window:    "hourly",
+			wantStart: time.Date(2020, 1, 22, 3, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2020, 1, 22, 4, 0, 0, 0, time.UTC),
+		}, {
+			window:    "weekly",
+			wantStart: time.Date(2020, 1, 20, 0, 0, 0, 0, time.UTC),
+			wantEnd:   time.Date(2020, 1, 27, 0, 0, 0, 0, time.UTC),
+		}, {
+			window:    "monthly",
+			timezone:  "tz=America/New_York",
+			wantStart: time.Date(2020, 1, 1, 0, 0, 0, 0, newYork),
+			wantEnd:   time.Date(2020, 2, 1, 0, 0, 0, 0, newYork),
+		}, {
+			window:    "rolling24h",
+			wantStart: time.Date(2020, 1, 21, 3, 30, 0, 0, time.UTC),
+			wantEnd:   now,
+		}, {
+			window:    "rolling7d",
+			wantStart: time.Date(2020, 1, 15, 3, 30, 0, 0, time.UTC),
+			wantEnd:   now,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.window+kase.timezone, func(t *testing.T) {
+			w, e := parseVolumeFilterWindow(kase.window)
+			if !assert.NoError(t, e) {
+				return
+			}
+			if kase.timezone != "" {
+				e = w.setTimezone(kase.timezone)
+				if !assert.NoError(t, e) {
+					return
+				}
+			}
+
+			start, end := w.bounds(now)
+			assert.True(t, kase.wantStart.Equal(start), "start: want %s, got %s", kase.wantStart, start)
+			assert.True(t, kase.wantEnd.Equal(end), "end: want %s, got %s", kase.wantEnd, end)
+		})
+	}
+}
+
+func TestParseVolumeFilterWindow_Invalid(t *testing.T) {
+	for _, window := range []string{"", "yearly", "rolling", "rolling0h", "rolling24", "rolling1.5h"} {
+		t.Run(window, func(t *testing.T) {
+			_, e := parseVolumeFilterWindow(window)
+			assert.Error(t, e)
+		})
+	}
+
+	w, e := parseVolumeFilterWindow("rolling24h")
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Error(t, w.setTimezone("tz=America/New_York"))
+}
@@ -1200,6 +1200,76 @@ func TestVolumeFilterFn_QuoteCap_Exact(t *testing.T) {
 	}
 }
 
+func TestVolumeFilterFn_NotionalCap(t *testing.T) {
+	testCases := []struct {
+		name        string
+		mode        volumeFilterMode
+		action      queries.DailyVolumeAction
+		notionalOTB float64
+		inputOp     *txnbuild.ManageSellOffer
+		wantOp      *txnbuild.ManageSellOffer
+		wantTBB     float64
+	}{
+		{
+			name:        "sell under cap",
+			mode:        volumeFilterModeExact,
+			action:      queries.DailyVolumeActionSell,
+			notionalOTB: 0.0,
+			inputOp:     makeSellOpAmtPrice(10.0, 2.0),
+			wantOp:      makeSellOpAmtPrice(10.0, 2.0),
+			wantTBB:     10.0 * 2.0 * 0.5,
+		}, {
+			// 50 - 40 = 10 units of notional remaining, at a notional price of 2.0 * 0.5 = 1.0 per unit of base
+			name:        "sell exact over cap",
+			mode:        volumeFilterModeExact,
+			action:      queries.DailyVolumeActionSell,
+			notionalOTB: 40.0,
+			inputOp:     makeSellOpAmtPrice(20.0, 2.0),
+			wantOp:      makeSellOpAmtPrice(10.0, 2.0),
+			wantTBB:     10.0,
+		}, {
+			name:        "sell ignore over cap",
+			mode:        volumeFilterModeIgnore,
+			action:      queries.DailyVolumeActionSell,
+			notionalOTB: 40.0,
+			inputOp:     makeSellOpAmtPrice(20.0, 2.0),
+			wantOp:      nil,
+			wantTBB:     0.0,
+		}, {
+			name:        "buy exact over cap",
+			mode:        volumeFilterModeExact,
+			action:      queries.DailyVolumeActionBuy,
+			notionalOTB: 40.0,
+			inputOp:     makeBuyOpAmtPrice(20.0, 2.0),
+			wantOp:      makeBuyOpAmtPrice(10.0, 2.0),
+			wantTBB:     10.0,
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			dailyOTB := makeRawVolumeFilterConfig(pointy.Float64(0.0), pointy.Float64(0.0), k.action, k.mode, nil, nil)
+			dailyOTB.BaseAssetCapInNotionalUnits = pointy.Float64(k.notionalOTB)
+			dailyTBB := makeRawVolumeFilterConfig(pointy.Float64(0.0), pointy.Float64(0.0), k.action, k.mode, nil, nil)
+			dailyTBB.BaseAssetCapInNotionalUnits = pointy.Float64(0.0)
+			lp := limitParameters{
+				baseAssetCapInNotionalUnits: pointy.Float64(50.0),
+				notionalPrice:               0.5,
+				mode:                        k.mode,
+			}
+
+			base := utils.Asset2Asset2(testBaseAsset)
+			quote := utils.Asset2Asset2(testQuoteAsset)
+			actual, e := volumeFilterFn(dailyOTB, dailyTBB, k.inputOp, base, quote, lp)
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, k.wantOp, actual)
+			assert.InDelta(t, k.wantTBB, *dailyTBB.BaseAssetCapInNotionalUnits, 0.0000001)
+		})
+	}
+}
+
 func runTestVolumeFilterFn(
 	t *testing.T,
 	name string,
@@ -10,10 +10,10 @@ import (
 )
 
 // sqlQueryDailyValuesTemplateAllAccounts queries the trades table to get the values for a given day
-const sqlQueryDailyValuesTemplateAllAccounts = "SELECT SUM(base_volume) as total_base_volume, SUM(counter_cost) as total_counter_volume FROM trades WHERE market_id IN (%s) AND DATE(date_utc) = $1 and action = $2 group by DATE(date_utc)"
+const sqlQueryDailyValuesTemplateAllAccounts = "SELECT SUM(base_volume) as total_base_volume, SUM(counter_cost) as total_counter_volume, COALESCE(SUM(notional_value), 0) as total_notional_volume, COUNT(*) - COUNT(notional_value) as num_missing_notional, COALESCE(SUM(CASE WHEN notional_value IS NULL AND market_id = %s THEN counter_cost ELSE 0 END), 0) as missing_notional_counter_volume FROM trades WHERE market_id IN (%s) AND DATE(date_utc) = $1 and action = $2 group by DATE(date_utc)"
 
 // sqlQueryDailyValuesTemplateSpecificAccounts queries the trades table to get the values for a given day filtered by specific accounts
-const sqlQueryDailyValuesTemplateSpecificAccounts = "SELECT SUM(base_volume) as total_base_volume, SUM(counter_cost) as total_counter_volume FROM trades WHERE market_id IN (%s) AND account_id IN (%s) AND DATE(date_utc) = $1 and action = $2 group by DATE(date_utc)"
+const sqlQueryDailyValuesTemplateSpecificAccounts = "SELECT SUM(base_volume) as total_base_volume, SUM(counter_cost) as total_counter_volume, COALESCE(SUM(notional_value), 0) as total_notional_volume, COUNT(*) - COUNT(notional_value) as num_missing_notional, COALESCE(SUM(CASE WHEN notional_value IS NULL AND market_id = %s THEN counter_cost ELSE 0 END), 0) as missing_notional_counter_volume FROM trades WHERE market_id IN (%s) AND account_id IN (%s) AND DATE(date_utc) = $1 and action = $2 group by DATE(date_utc)"
 
 // DailyVolumeAction represents either a sell or a buy
 type DailyVolumeAction string
@@ -58,10 +58,16 @@ type DailyVolumeByDate struct {
 
 var _ api.Query = &DailyVolumeByDate{}
 
-// DailyVolume represents any volume value which can be either bought or sold depending on the query
+// DailyVolume represents any volume value which can be either bought or sold depending on the query, it is also used for windows other than a day
 type DailyVolume struct {
-	BaseVol  float64
-	QuoteVol float64
+	BaseVol     float64
+	QuoteVol    float64
+	NotionalVol float64
+	// NumMissingNotional is the number of trades that do not have a notional value and are not included in NotionalVol
+	NumMissingNotional int64
+	// MissingNotionalQuoteVol is the quote volume of the trades on the first market that do not have a notional value, trades on other
+	// markets are excluded since their quote asset can be different
+	MissingNotionalQuoteVol float64
 }
 
 // MakeDailyVolumeByDateForMarketIdsAction makes the DailyVolumeByDate query for a set of marketIds and an action
@@ -76,7 +82,7 @@ func MakeDailyVolumeByDateForMarketIdsAction(
 		return nil, fmt.Errorf("the provided db should be non-nil")
 	}
 
-	sqlQuery := makeSQLQueryDailyVolume(marketIDs, optionalAccountIDs)
+	sqlQuery := makeSQLQueryVolume(sqlQueryDailyValuesTemplateAllAccounts, sqlQueryDailyValuesTemplateSpecificAccounts, marketIDs, optionalAccountIDs)
 	return &DailyVolumeByDate{
 		db:       db,
 		sqlQuery: sqlQuery,
@@ -101,7 +107,10 @@ func (q *DailyVolumeByDate) QueryRow(args ...interface{}) (interface{}, error) {
 
 	var baseVol sql.NullFloat64
 	var quoteVol sql.NullFloat64
-	e := row.Scan(&baseVol, &quoteVol)
+	var notionalVol float64
+	var numMissingNotional int64
+	var missingNotionalQuoteVol float64
+	e := row.Scan(&baseVol, &quoteVol, &notionalVol, &numMissingNotional, &missingNotionalQuoteVol)
 	if e != nil {
 		if strings.Contains(e.Error(), "no rows in result set") {
 			return &DailyVolume{
@@ -120,12 +129,22 @@ func (q *DailyVolumeByDate) QueryRow(args ...interface{}) (interface{}, error) {
 	}
 
 	return &DailyVolume{
-		BaseVol:  baseVol.Float64,
-		QuoteVol: quoteVol.Float64,
+		BaseVol:                 baseVol.Float64,
+		QuoteVol:                quoteVol.Float64,
+		NotionalVol:             notionalVol,
+		NumMissingNotional:      numMissingNotional,
+		MissingNotionalQuoteVol: missingNotionalQuoteVol,
 	}, nil
 }
 
-func makeSQLQueryDailyVolume(marketIDs []string, optionalAccountIDs []string) string {
+// makeSQLQueryVolume fills in the market and account filters of the template, where the specific accounts template is used only when accounts are provided.
+// The first market ID is the market of the bot
+func makeSQLQueryVolume(templateAllAccounts string, templateSpecificAccounts string, marketIDs []string, optionalAccountIDs []string) string {
+	ownMarketValue := "''"
+	if len(marketIDs) > 0 {
+		ownMarketValue = fmt.Sprintf("'%s'", marketIDs[0])
+	}
+
 	// add filter on marketIDs
 	marketsInClauseParts := []string{}
 	for _, mid := range marketIDs {
@@ -136,7 +155,7 @@ func makeSQLQueryDailyVolume(marketIDs []string, optionalAccountIDs []string) st
 
 	// len(a), where a is a nil array, is valid and returns 0
 	if len(optionalAccountIDs) == 0 {
-		return fmt.Sprintf(sqlQueryDailyValuesTemplateAllAccounts, marketsInClause)
+		return fmt.Sprintf(templateAllAccounts, ownMarketValue, marketsInClause)
 	}
 
 	// include filter on account_id
@@ -146,5 +165,5 @@ func makeSQLQueryDailyVolume(marketIDs []string, optionalAccountIDs []string) st
 		accountsInClauseParts = append(accountsInClauseParts, accountsInValue)
 	}
 	accountsInClause := strings.Join(accountsInClauseParts, ", ")
-	return fmt.Sprintf(sqlQueryDailyValuesTemplateSpecificAccounts, marketsInClause, accountsInClause)
+	return fmt.Sprintf(templateSpecificAccounts, ownMarketValue, marketsInClause, accountsInClause)
 }
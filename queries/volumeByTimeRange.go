@@ -0,0 +1,81 @@
+package queries
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/stellar/kelp/api"
+	"github.com/stellar/kelp/support/utils"
+)
+
+// sqlQueryVolumeByTimeRangeTemplateAllAccounts queries the trades table to get the values for a time range [start, end)
+const sqlQueryVolumeByTimeRangeTemplateAllAccounts = "SELECT COALESCE(SUM(base_volume), 0) as total_base_volume, COALESCE(SUM(counter_cost), 0) as total_counter_volume, COALESCE(SUM(notional_value), 0) as total_notional_volume, COUNT(*) - COUNT(notional_value) as num_missing_notional, COALESCE(SUM(CASE WHEN notional_value IS NULL AND market_id = %s THEN counter_cost ELSE 0 END), 0) as missing_notional_counter_volume FROM trades WHERE market_id IN (%s) AND date_utc >= $1 AND date_utc < $2 and action = $3"
+
+// sqlQueryVolumeByTimeRangeTemplateSpecificAccounts queries the trades table to get the values for a time range [start, end) filtered by specific accounts
+const sqlQueryVolumeByTimeRangeTemplateSpecificAccounts = "SELECT COALESCE(SUM(base_volume), 0) as total_base_volume, COALESCE(SUM(counter_cost), 0) as total_counter_volume, COALESCE(SUM(notional_value), 0) as total_notional_volume, COUNT(*) - COUNT(notional_value) as num_missing_notional, COALESCE(SUM(CASE WHEN notional_value IS NULL AND market_id = %s THEN counter_cost ELSE 0 END), 0) as missing_notional_counter_volume FROM trades WHERE market_id IN (%s) AND account_id IN (%s) AND date_utc >= $1 AND date_utc < $2 and action = $3"
+
+// VolumeByTimeRange is a query that fetches the volume of trades in a time range, it is used for windows that do not line up with a day in UTC
+type VolumeByTimeRange struct {
+	db       *sql.DB
+	sqlQuery string
+	action   DailyVolumeAction
+}
+
+var _ api.Query = &VolumeByTimeRange{}
+
+// MakeVolumeByTimeRangeForMarketIdsAction makes the VolumeByTimeRange query for a set of marketIds and an action
+func MakeVolumeByTimeRangeForMarketIdsAction(
+	db *sql.DB,
+	marketIDs []string,
+	action DailyVolumeAction,
+	optionalAccountIDs []string,
+) (*VolumeByTimeRange, error) {
+	if db == nil {
+		utils.PrintErrorHintf("the provided POSTGRES_DB config in the trader.cfg file should be non-nil")
+		return nil, fmt.Errorf("the provided db should be non-nil")
+	}
+
+	sqlQuery := makeSQLQueryVolume(sqlQueryVolumeByTimeRangeTemplateAllAccounts, sqlQueryVolumeByTimeRangeTemplateSpecificAccounts, marketIDs, optionalAccountIDs)
+	return &VolumeByTimeRange{
+		db:       db,
+		sqlQuery: sqlQuery,
+		action:   action,
+	}, nil
+}
+
+// Name impl.
+func (q *VolumeByTimeRange) Name() string {
+	return "VolumeByTimeRange"
+}
+
+// QueryRow impl.
+func (q *VolumeByTimeRange) QueryRow(args ...interface{}) (interface{}, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("expected 2 args (startUTC string, endUTC string), but got args %v", args)
+	}
+	for i, arg := range args {
+		if _, ok := arg.(string); !ok {
+			return nil, fmt.Errorf("input arg at index %d needs to be of type 'string', but was of type '%T'", i, arg)
+		}
+	}
+
+	row := q.db.QueryRow(q.sqlQuery, args[0], args[1], q.action.String())
+
+	var baseVol float64
+	var quoteVol float64
+	var notionalVol float64
+	var numMissingNotional int64
+	var missingNotionalQuoteVol float64
+	e := row.Scan(&baseVol, &quoteVol, &notionalVol, &numMissingNotional, &missingNotionalQuoteVol)
+	if e != nil {
+		return nil, fmt.Errorf("could not read data from VolumeByTimeRange query: %s", e)
+	}
+
+	return &DailyVolume{
+		BaseVol:                 baseVol,
+		QuoteVol:                quoteVol,
+		NotionalVol:             notionalVol,
+		NumMissingNotional:      numMissingNotional,
+		MissingNotionalQuoteVol: missingNotionalQuoteVol,
+	}, nil
+}
@@ -0,0 +1,386 @@
+package networking
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stellar/go/clients/horizonclient"
+)
+
+// latestLedgerHeader is set by horizon on every response to the latest ledger it has ingested
+const latestLedgerHeader = "Latest-Ledger"
+
+// horizonEndpoint holds the health of a single horizon instance
+type horizonEndpoint struct {
+	url *url.URL
+
+	// uninitialized
+	latestLedger int64
+	reachable    bool
+	numRequests  int64 // since the last health check
+	numErrors    int64 // since the last health check
+	errorRate    float64
+}
+
+// horizonRoot is the subset of the response from the root endpoint of horizon that is used for health checks
+type horizonRoot struct {
+	HistoryLatestLedger int64  `json:"history_latest_ledger"`
+	NetworkPassphrase   string `json:"network_passphrase"`
+}
+
+// horizonSubmitResponse is the subset of the response from a successful transaction submission that we need
+type horizonSubmitResponse struct {
+	Ledger int64 `json:"ledger"`
+}
+
+// HorizonFailover is a horizonclient.HTTP that spreads requests over a list of horizon instances. Requests go to the first healthy instance
+// in the list, where an instance is healthy when it responds to health checks, is not lagging the most recent instance by more than maxLedgerLag
+// ledgers, and does not have an error rate above maxErrorRate. Reads are only sent to instances that have ingested the ledger of our last submitted
+// transaction so we never read stale offers or balances from a lagging instance right after submitting to another one.
+//
+// The horizonclient.Client using this should have its HorizonURL set to the first URL, requests are rewritten to the selected instance.
+type HorizonFailover struct {
+	httpClient          *http.Client
+	endpoints           []*horizonEndpoint
+	maxLedgerLag        int64
+	maxErrorRate        float64
+	healthCheckInterval time.Duration
+
+	// initialized runtime vars
+	mutex *sync.Mutex
+
+	// uninitialized
+	activeIndex     int
+	minReadLedger   int64 // ledger of our last submitted transaction
+	lastSubmitIndex int
+}
+
+// enforce HorizonFailover implementing horizonclient.HTTP
+var _ horizonclient.HTTP = &HorizonFailover{}
+
+// MakeHorizonFailover is a factory method for HorizonFailover
+func MakeHorizonFailover(
+	horizonURLs []string,
+	httpClient *http.Client,
+	maxLedgerLag int64,
+	maxErrorRate float64,
+	healthCheckInterval time.Duration,
+) (*HorizonFailover, error) {
+	if len(horizonURLs) == 0 {
+		return nil, fmt.Errorf("need at least one horizon URL")
+	}
+
+	endpoints := []*horizonEndpoint{}
+	seen := map[string]bool{}
+	for _, u := range horizonURLs {
+		trimmed := strings.TrimSuffix(u, "/")
+		if seen[trimmed] {
+			continue
+		}
+		seen[trimmed] = true
+
+		parsed, e := url.Parse(trimmed)
+		if e != nil {
+			return nil, fmt.Errorf("could not parse horizon URL '%s': %s", u, e)
+		}
+		if parsed.Scheme == "" || parsed.Host == "" {
+			return nil, fmt.Errorf("horizon URL '%s' needs to include the scheme and host", u)
+		}
+		endpoints = append(endpoints, &horizonEndpoint{
+			url:       parsed,
+			reachable: true,
+		})
+	}
+
+	return &HorizonFailover{
+		httpClient:          httpClient,
+		endpoints:           endpoints,
+		maxLedgerLag:        maxLedgerLag,
+		maxErrorRate:        maxErrorRate,
+		healthCheckInterval: healthCheckInterval,
+		mutex:               &sync.Mutex{},
+		activeIndex:         0,
+		lastSubmitIndex:     -1,
+	}, nil
+}
+
+// PrimaryURL is the URL that the horizonclient.Client using this should be configured with
+func (h *HorizonFailover) PrimaryURL() string {
+	return h.endpoints[0].url.String()
+}
+
+// Start runs a health check on all instances and then keeps running health checks in the background
+func (h *HorizonFailover) Start() error {
+	networkPassphrase := ""
+	for _, ep := range h.endpoints {
+		root, e := h.fetchRoot(ep)
+		if e != nil {
+			log.Printf("horizon failover: initial health check failed for %s: %s\n", ep.url, e)
+			continue
+		}
+
+		if networkPassphrase == "" {
+			networkPassphrase = root.NetworkPassphrase
+		} else if root.NetworkPassphrase != networkPassphrase {
+			return fmt.Errorf("horizon instance %s is on network '%s' but other instances are on network '%s'", ep.url, root.NetworkPassphrase, networkPassphrase)
+		}
+	}
+	h.checkHealth()
+
+	go func() {
+		for {
+			time.Sleep(h.healthCheckInterval)
+			h.checkHealth()
+		}
+	}()
+	return nil
+}
+
+// checkHealth fetches the latest ledger from every instance and selects the active instance
+func (h *HorizonFailover) checkHealth() {
+	results := make([]*horizonRoot, len(h.endpoints))
+	for i, ep := range h.endpoints {
+		root, e := h.fetchRoot(ep)
+		if e != nil {
+			log.Printf("horizon failover: health check failed for %s: %s\n", ep.url, e)
+			continue
+		}
+		results[i] = root
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	for i, ep := range h.endpoints {
+		ep.reachable = results[i] != nil
+		if results[i] != nil && results[i].HistoryLatestLedger > ep.latestLedger {
+			ep.latestLedger = results[i].HistoryLatestLedger
+		}
+
+		if ep.numRequests > 0 {
+			ep.errorRate = float64(ep.numErrors) / float64(ep.numRequests)
+		} else {
+			ep.errorRate = 0
+		}
+		ep.numRequests = 0
+		ep.numErrors = 0
+	}
+	h.selectActive()
+}
+
+func (h *HorizonFailover) fetchRoot(ep *horizonEndpoint) (*horizonRoot, error) {
+	resp, e := h.httpClient.Get(ep.url.String() + "/")
+	if e != nil {
+		return nil, fmt.Errorf("could not fetch root: %s", e)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("root returned status %d", resp.StatusCode)
+	}
+
+	var root horizonRoot
+	e = json.NewDecoder(resp.Body).Decode(&root)
+	if e != nil {
+		return nil, fmt.Errorf("could not parse root: %s", e)
+	}
+	return &root, nil
+}
+
+// maxLatestLedger returns the most recent ledger ingested by any instance, needs to be called while holding the mutex
+func (h *HorizonFailover) maxLatestLedger() int64 {
+	maxLedger := int64(0)
+	for _, ep := range h.endpoints {
+		if ep.latestLedger > maxLedger {
+			maxLedger = ep.latestLedger
+		}
+	}
+	return maxLedger
+}
+
+// isHealthy needs to be called while holding the mutex
+func (h *HorizonFailover) isHealthy(ep *horizonEndpoint, maxLedger int64) bool {
+	return ep.reachable && maxLedger-ep.latestLedger <= h.maxLedgerLag && ep.errorRate <= h.maxErrorRate
+}
+
+// selectActive picks the first healthy instance as the active instance, needs to be called while holding the mutex
+func (h *HorizonFailover) selectActive() {
+	maxLedger := h.maxLatestLedger()
+	newIndex := -1
+	for i, ep := range h.endpoints {
+		if h.isHealthy(ep, maxLedger) {
+			newIndex = i
+			break
+		}
+	}
+	if newIndex == -1 {
+		log.Printf("horizon failover: no healthy horizon instances, staying on %s\n", h.endpoints[h.activeIndex].url)
+		return
+	}
+
+	if newIndex != h.activeIndex {
+		prev := h.endpoints[h.activeIndex]
+		log.Printf("horizon failover: switching from %s (latestLedger=%d, errorRate=%.2f, reachable=%v) to %s (latestLedger=%d)\n",
+			prev.url, prev.latestLedger, prev.errorRate, prev.reachable, h.endpoints[newIndex].url, h.endpoints[newIndex].latestLedger)
+		h.activeIndex = newIndex
+	}
+}
+
+// candidates returns the indices of the instances to try for a request in order of preference
+func (h *HorizonFailover) candidates(isRead bool) []int {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	maxLedger := h.maxLatestLedger()
+	consistent := func(i int) bool {
+		// the instance we submitted to has our transaction even if we have not seen its latest ledger move yet
+		return !isRead || h.minReadLedger == 0 || i == h.lastSubmitIndex || h.endpoints[i].latestLedger >= h.minReadLedger
+	}
+
+	ordered := []int{}
+	added := map[int]bool{}
+	add := func(i int) {
+		if !added[i] {
+			added[i] = true
+			ordered = append(ordered, i)
+		}
+	}
+
+	if consistent(h.activeIndex) {
+		add(h.activeIndex)
+	}
+	for i, ep := range h.endpoints {
+		if h.isHealthy(ep, maxLedger) && consistent(i) {
+			add(i)
+		}
+	}
+	if isRead && h.lastSubmitIndex >= 0 {
+		add(h.lastSubmitIndex)
+	}
+	// as a last resort try everything else
+	for i := range h.endpoints {
+		add(i)
+	}
+	return ordered
+}
+
+// recordResponse updates the health of the instance after a request, needs to be called while holding the mutex
+func (h *HorizonFailover) recordResponse(i int, resp *http.Response, e error) {
+	ep := h.endpoints[i]
+	ep.numRequests++
+	if e != nil || resp.StatusCode >= 500 {
+		ep.numErrors++
+		return
+	}
+
+	if latestLedger, e := strconv.ParseInt(resp.Header.Get(latestLedgerHeader), 10, 64); e == nil && latestLedger > ep.latestLedger {
+		ep.latestLedger = latestLedger
+	}
+}
+
+// rewrite points the request at the instance with the given index
+func (h *HorizonFailover) rewrite(req *http.Request, i int) *http.Request {
+	primary := h.endpoints[0].url
+	target := h.endpoints[i].url
+
+	newReq := req.WithContext(req.Context())
+	newURL := *req.URL
+	newURL.Scheme = target.Scheme
+	newURL.Host = target.Host
+	newURL.Path = target.Path + strings.TrimPrefix(req.URL.Path, primary.Path)
+	newReq.URL = &newURL
+	newReq.Host = target.Host
+	return newReq
+}
+
+// Do impl
+func (h *HorizonFailover) Do(req *http.Request) (*http.Response, error) {
+	isRead := req.Method == "GET"
+	var resp *http.Response
+	var e error
+	for _, i := range h.candidates(isRead) {
+		resp, e = h.httpClient.Do(h.rewrite(req, i))
+
+		h.mutex.Lock()
+		h.recordResponse(i, resp, e)
+		h.mutex.Unlock()
+
+		if !isRead {
+			// submitting the same transaction to another instance is not safe to do blindly (a timed out transaction may still
+			// get into a ledger), the caller handles retries of writes
+			if e == nil && resp.StatusCode == http.StatusOK && strings.HasSuffix(req.URL.Path, "/transactions") {
+				h.recordSubmission(i, resp)
+			}
+			return resp, e
+		}
+
+		if e == nil && resp.StatusCode < 500 {
+			return resp, nil
+		}
+		if e == nil {
+			log.Printf("horizon failover: GET %s returned status %d from %s, trying the next instance\n", req.URL.Path, resp.StatusCode, h.endpoints[i].url)
+			resp.Body.Close()
+		} else {
+			log.Printf("horizon failover: GET %s failed on %s, trying the next instance: %s\n", req.URL.Path, h.endpoints[i].url, e)
+		}
+	}
+	if e == nil {
+		return nil, fmt.Errorf("GET %s failed on all %d horizon instances", req.URL.Path, len(h.endpoints))
+	}
+	return nil, e
+}
+
+// recordSubmission remembers the ledger of a successfully submitted transaction so later reads only go to instances that have ingested it
+func (h *HorizonFailover) recordSubmission(i int, resp *http.Response) {
+	body, e := ioutil.ReadAll(resp.Body)
+	resp.Body.Close()
+	resp.Body = ioutil.NopCloser(bytes.NewReader(body))
+	if e != nil {
+		log.Printf("horizon failover: could not read transaction submission response: %s\n", e)
+		return
+	}
+
+	var submitResp horizonSubmitResponse
+	e = json.Unmarshal(body, &submitResp)
+	if e != nil || submitResp.Ledger == 0 {
+		return
+	}
+
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+	if submitResp.Ledger > h.minReadLedger {
+		h.minReadLedger = submitResp.Ledger
+	}
+	h.lastSubmitIndex = i
+	if submitResp.Ledger > h.endpoints[i].latestLedger {
+		h.endpoints[i].latestLedger = submitResp.Ledger
+	}
+}
+
+// Get impl
+func (h *HorizonFailover) Get(url string) (*http.Response, error) {
+	req, e := http.NewRequest("GET", url, nil)
+	if e != nil {
+		return nil, e
+	}
+	return h.Do(req)
+}
+
+// PostForm impl
+func (h *HorizonFailover) PostForm(url string, data url.Values) (*http.Response, error) {
+	req, e := http.NewRequest("POST", url, strings.NewReader(data.Encode()))
+	if e != nil {
+		return nil, e
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return h.Do(req)
+}
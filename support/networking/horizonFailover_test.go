@@ -0,0 +1,116 @@
+package networking
+
+import (
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// makeTestHorizon makes a fake horizon instance that reports the given latest ledger and responds to every other request with the given status
+func makeTestHorizon(name string, latestLedger int64, status int) *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(latestLedgerHeader, fmt.Sprintf("%d", latestLedger))
+		if r.URL.Path == "/" {
+			fmt.Fprintf(w, `{"history_latest_ledger": %d, "network_passphrase": "test"}`, latestLedger)
+			return
+		}
+		if r.Method == "POST" {
+			w.WriteHeader(status)
+			fmt.Fprintf(w, `{"ledger": %d}`, latestLedger+1)
+			return
+		}
+		w.WriteHeader(status)
+		fmt.Fprint(w, name)
+	}))
+}
+
+func TestHorizonFailover(t *testing.T) {
+	testCases := []struct {
+		name          string
+		primaryLedger int64
+		primaryStatus int
+		backupLedger  int64
+		wantResponder string
+	}{
+		{
+			name:          "healthy primary",
+			primaryLedger: 100,
+			primaryStatus: http.StatusOK,
+			backupLedger:  100,
+			wantResponder: "primary",
+		}, {
+			name:          "lagging primary",
+			primaryLedger: 90,
+			primaryStatus: http.StatusOK,
+			backupLedger:  100,
+			wantResponder: "backup",
+		}, {
+			name:          "failing primary",
+			primaryLedger: 100,
+			primaryStatus: http.StatusInternalServerError,
+			backupLedger:  100,
+			wantResponder: "backup",
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.name, func(t *testing.T) {
+			primary := makeTestHorizon("primary", kase.primaryLedger, kase.primaryStatus)
+			defer primary.Close()
+			backup := makeTestHorizon("backup", kase.backupLedger, http.StatusOK)
+			defer backup.Close()
+
+			h, e := MakeHorizonFailover([]string{primary.URL, backup.URL}, http.DefaultClient, 5, 0.5, time.Hour)
+			if !assert.NoError(t, e) {
+				return
+			}
+			if !assert.NoError(t, h.Start()) {
+				return
+			}
+
+			resp, e := h.Get(h.PrimaryURL() + "/offers")
+			if !assert.NoError(t, e) {
+				return
+			}
+			defer resp.Body.Close()
+			body, e := ioutil.ReadAll(resp.Body)
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, kase.wantResponder, string(body))
+		})
+	}
+}
+
+func TestHorizonFailoverReadsAfterSubmit(t *testing.T) {
+	primary := makeTestHorizon("primary", 100, http.StatusOK)
+	defer primary.Close()
+	backup := makeTestHorizon("backup", 98, http.StatusOK)
+	defer backup.Close()
+
+	h, e := MakeHorizonFailover([]string{primary.URL, backup.URL}, http.DefaultClient, 5, 0.5, time.Hour)
+	if !assert.NoError(t, e) {
+		return
+	}
+	if !assert.NoError(t, h.Start()) {
+		return
+	}
+
+	resp, e := h.PostForm(h.PrimaryURL()+"/transactions", nil)
+	if !assert.NoError(t, e) {
+		return
+	}
+	resp.Body.Close()
+	assert.Equal(t, int64(101), h.minReadLedger)
+
+	// even if the instance we submitted to goes down, the backup has not ingested our transaction so it is not preferred for reads
+	h.mutex.Lock()
+	h.endpoints[0].reachable = false
+	h.mutex.Unlock()
+	assert.Equal(t, []int{0, 1}, h.candidates(true))
+}
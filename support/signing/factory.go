@@ -0,0 +1,50 @@
+package signing
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/stellar/kelp/api"
+)
+
+// the types of signers that can be made with MakeSigner
+const (
+	SignerTypeSeed     = "seed"
+	SignerTypeKeystore = "keystore"
+	SignerTypeRemote   = "remote"
+)
+
+// defaultRemoteTimeout is used when a timeout is not specified for the remote signer
+const defaultRemoteTimeout = 5 * time.Second
+
+// DefaultKeystorePassphraseEnvVar is the environment variable used to read the keystore passphrase when one is not specified
+const DefaultKeystorePassphraseEnvVar = "KELP_KEYSTORE_PASSPHRASE"
+
+// MakeSigner creates a Signer based on the type of signer, the seeds are only used by the seed signer (which is the default)
+func MakeSigner(
+	signerType string,
+	seeds []string,
+	keystorePath string,
+	keystorePassphraseEnvVar string,
+	remoteURL string,
+	remoteAuthToken string,
+	remoteTimeout time.Duration,
+) (api.Signer, error) {
+	switch signerType {
+	case "", SignerTypeSeed:
+		return MakeSeedSigner(seeds...)
+	case SignerTypeKeystore:
+		if keystorePassphraseEnvVar == "" {
+			keystorePassphraseEnvVar = DefaultKeystorePassphraseEnvVar
+		}
+		return MakeKeystoreSigner(keystorePath, os.Getenv(keystorePassphraseEnvVar))
+	case SignerTypeRemote:
+		if remoteTimeout <= 0 {
+			remoteTimeout = defaultRemoteTimeout
+		}
+		return MakeRemoteSigner(remoteURL, remoteAuthToken, remoteTimeout)
+	default:
+		return nil, fmt.Errorf("unrecognized signer type '%s', needs to be one of '%s', '%s' or '%s'", signerType, SignerTypeSeed, SignerTypeKeystore, SignerTypeRemote)
+	}
+}
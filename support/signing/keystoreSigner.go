@@ -0,0 +1,178 @@
+package signing
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/kelp/api"
+	"golang.org/x/crypto/nacl/secretbox"
+	"golang.org/x/crypto/scrypt"
+)
+
+const keystoreVersion = 1
+
+// scrypt parameters used to derive the encryption key from the passphrase
+const scryptN = 32768
+const scryptR = 8
+const scryptP = 1
+
+// keystoreFile is the on-disk representation of an encrypted keystore
+type keystoreFile struct {
+	Version int           `json:"version"`
+	Keys    []keystoreKey `json:"keys"`
+}
+
+// keystoreKey is a single secret seed encrypted with a key derived from the passphrase and its own salt
+type keystoreKey struct {
+	Address    string `json:"address"`
+	Salt       string `json:"salt"`
+	Nonce      string `json:"nonce"`
+	Ciphertext string `json:"ciphertext"`
+}
+
+// MakeKeystoreSigner makes a signer from the secret seeds in the encrypted keystore file at the given path
+func MakeKeystoreSigner(keystorePath string, passphrase string) (api.Signer, error) {
+	if passphrase == "" {
+		return nil, fmt.Errorf("passphrase for keystore file %s cannot be empty", keystorePath)
+	}
+
+	data, e := ioutil.ReadFile(keystorePath)
+	if e != nil {
+		return nil, fmt.Errorf("could not read keystore file %s: %s", keystorePath, e)
+	}
+
+	var ksFile keystoreFile
+	e = json.Unmarshal(data, &ksFile)
+	if e != nil {
+		return nil, fmt.Errorf("could not parse keystore file %s: %s", keystorePath, e)
+	}
+	if ksFile.Version != keystoreVersion {
+		return nil, fmt.Errorf("unsupported keystore version %d in file %s, expected %d", ksFile.Version, keystorePath, keystoreVersion)
+	}
+
+	seeds := []string{}
+	for i, k := range ksFile.Keys {
+		seed, e := decryptKey(k, passphrase)
+		if e != nil {
+			return nil, fmt.Errorf("could not decrypt key at index %d in keystore file %s: %s", i, keystorePath, e)
+		}
+		seeds = append(seeds, seed)
+	}
+	return MakeSeedSigner(seeds...)
+}
+
+// WriteKeystore encrypts the passed in secret seeds with the passphrase and writes them to a keystore file at the given path
+func WriteKeystore(keystorePath string, passphrase string, seeds []string) error {
+	if passphrase == "" {
+		return fmt.Errorf("passphrase for keystore file cannot be empty")
+	}
+
+	ksFile := keystoreFile{
+		Version: keystoreVersion,
+		Keys:    []keystoreKey{},
+	}
+	for i, s := range seeds {
+		k, e := encryptKey(s, passphrase)
+		if e != nil {
+			return fmt.Errorf("could not encrypt seed at index %d: %s", i, e)
+		}
+		ksFile.Keys = append(ksFile.Keys, *k)
+	}
+
+	data, e := json.MarshalIndent(ksFile, "", "  ")
+	if e != nil {
+		return fmt.Errorf("could not serialize keystore: %s", e)
+	}
+
+	// only the owner should be able to read the keystore file
+	e = ioutil.WriteFile(keystorePath, data, 0600)
+	if e != nil {
+		return fmt.Errorf("could not write keystore file %s: %s", keystorePath, e)
+	}
+	return nil
+}
+
+func encryptKey(seed string, passphrase string) (*keystoreKey, error) {
+	kp, e := keypair.Parse(seed)
+	if e != nil {
+		return nil, fmt.Errorf("cannot parse seed into keypair: %s", e)
+	}
+	if _, ok := kp.(*keypair.Full); !ok {
+		return nil, fmt.Errorf("value was not a secret seed")
+	}
+
+	var salt [32]byte
+	if _, e := io.ReadFull(rand.Reader, salt[:]); e != nil {
+		return nil, fmt.Errorf("could not generate salt: %s", e)
+	}
+	var nonce [24]byte
+	if _, e := io.ReadFull(rand.Reader, nonce[:]); e != nil {
+		return nil, fmt.Errorf("could not generate nonce: %s", e)
+	}
+
+	key, e := deriveKey(passphrase, salt[:])
+	if e != nil {
+		return nil, e
+	}
+	ciphertext := secretbox.Seal(nil, []byte(seed), &nonce, key)
+
+	return &keystoreKey{
+		Address:    kp.Address(),
+		Salt:       base64.StdEncoding.EncodeToString(salt[:]),
+		Nonce:      base64.StdEncoding.EncodeToString(nonce[:]),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+func decryptKey(k keystoreKey, passphrase string) (string, error) {
+	salt, e := base64.StdEncoding.DecodeString(k.Salt)
+	if e != nil {
+		return "", fmt.Errorf("could not decode salt: %s", e)
+	}
+	nonceBytes, e := base64.StdEncoding.DecodeString(k.Nonce)
+	if e != nil {
+		return "", fmt.Errorf("could not decode nonce: %s", e)
+	}
+	if len(nonceBytes) != 24 {
+		return "", fmt.Errorf("invalid nonce length %d", len(nonceBytes))
+	}
+	ciphertext, e := base64.StdEncoding.DecodeString(k.Ciphertext)
+	if e != nil {
+		return "", fmt.Errorf("could not decode ciphertext: %s", e)
+	}
+
+	key, e := deriveKey(passphrase, salt)
+	if e != nil {
+		return "", e
+	}
+	var nonce [24]byte
+	copy(nonce[:], nonceBytes)
+	seed, ok := secretbox.Open(nil, ciphertext, &nonce, key)
+	if !ok {
+		return "", fmt.Errorf("could not decrypt key for account %s, the passphrase is probably incorrect", k.Address)
+	}
+
+	kp, e := keypair.Parse(string(seed))
+	if e != nil {
+		return "", fmt.Errorf("decrypted value was not a valid seed: %s", e)
+	}
+	if kp.Address() != k.Address {
+		return "", fmt.Errorf("decrypted seed is for account %s but the keystore lists account %s", kp.Address(), k.Address)
+	}
+	return string(seed), nil
+}
+
+func deriveKey(passphrase string, salt []byte) (*[32]byte, error) {
+	keyBytes, e := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, 32)
+	if e != nil {
+		return nil, fmt.Errorf("could not derive key from passphrase: %s", e)
+	}
+	var key [32]byte
+	copy(key[:], keyBytes)
+	return &key, nil
+}
@@ -0,0 +1,43 @@
+package signing
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKeystoreRoundTrip(t *testing.T) {
+	dir, e := ioutil.TempDir("", "kelp-keystore")
+	if !assert.NoError(t, e) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	keystorePath := filepath.Join(dir, "keystore.json")
+
+	kp, e := keypair.Random()
+	if !assert.NoError(t, e) {
+		return
+	}
+	e = WriteKeystore(keystorePath, "correct horse", []string{kp.Seed()})
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	_, e = MakeKeystoreSigner(keystorePath, "wrong passphrase")
+	assert.Error(t, e)
+
+	signer, e := MakeKeystoreSigner(keystorePath, "correct horse")
+	if !assert.NoError(t, e) {
+		return
+	}
+	signedTx, e := signer.Sign(makeTestTx(t, kp.Address()), network.TestNetworkPassphrase, kp.Address())
+	if !assert.NoError(t, e) {
+		return
+	}
+	assert.Equal(t, 1, len(signedTx.Signatures()))
+}
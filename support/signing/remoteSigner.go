@@ -0,0 +1,178 @@
+package signing
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"time"
+
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/api"
+)
+
+// signRequest is the body sent to the remote signing service
+type signRequest struct {
+	NetworkPassphrase string   `json:"network_passphrase"`
+	TxXDR             string   `json:"tx_xdr"`
+	Accounts          []string `json:"accounts"`
+}
+
+// signResponse is the body returned by the remote signing service, Error is set when the service refuses to sign
+type signResponse struct {
+	TxXDR string `json:"tx_xdr"`
+	Error string `json:"error"`
+}
+
+// remoteSigner sends transactions to a remote signing service over HTTP so the signing keys never live on this host
+type remoteSigner struct {
+	url       string
+	authToken string
+	client    *http.Client
+}
+
+// ensure remoteSigner implements the api.Signer interface
+var _ api.Signer = &remoteSigner{}
+
+// MakeRemoteSigner makes a signer that delegates signing to the remote service at the given URL, authToken is optional
+func MakeRemoteSigner(url string, authToken string, timeout time.Duration) (api.Signer, error) {
+	if url == "" {
+		return nil, fmt.Errorf("url for the remote signer cannot be empty")
+	}
+
+	return &remoteSigner{
+		url:       url,
+		authToken: authToken,
+		client:    &http.Client{Timeout: timeout},
+	}, nil
+}
+
+// Sign impl
+func (s *remoteSigner) Sign(tx *txnbuild.Transaction, network string, accounts ...string) (*txnbuild.Transaction, error) {
+	txeB64, e := tx.Base64()
+	if e != nil {
+		return nil, fmt.Errorf("could not encode transaction: %s", e)
+	}
+
+	signedXDR, e := s.requestSignature(txeB64, network, accounts)
+	if e != nil {
+		return nil, e
+	}
+
+	signedTx, e := parseTransaction(signedXDR)
+	if e != nil {
+		return nil, fmt.Errorf("could not parse transaction returned by remote signer: %s", e)
+	}
+
+	// the remote signer should only add signatures, make sure it did not return a different transaction
+	originalHash, e := tx.Hash(network)
+	if e != nil {
+		return nil, fmt.Errorf("could not hash transaction: %s", e)
+	}
+	signedHash, e := signedTx.Hash(network)
+	if e != nil {
+		return nil, fmt.Errorf("could not hash transaction returned by remote signer: %s", e)
+	}
+	if originalHash != signedHash {
+		return nil, fmt.Errorf("remote signer returned a different transaction (hash=%x) than the one sent (hash=%x)", signedHash, originalHash)
+	}
+	return signedTx, nil
+}
+
+// SignFeeBump impl
+func (s *remoteSigner) SignFeeBump(tx *txnbuild.FeeBumpTransaction, network string, accounts ...string) (*txnbuild.FeeBumpTransaction, error) {
+	txeB64, e := tx.Base64()
+	if e != nil {
+		return nil, fmt.Errorf("could not encode fee bump transaction: %s", e)
+	}
+
+	signedXDR, e := s.requestSignature(txeB64, network, accounts)
+	if e != nil {
+		return nil, e
+	}
+
+	signedTx, e := parseFeeBumpTransaction(signedXDR)
+	if e != nil {
+		return nil, fmt.Errorf("could not parse fee bump transaction returned by remote signer: %s", e)
+	}
+
+	originalHash, e := tx.Hash(network)
+	if e != nil {
+		return nil, fmt.Errorf("could not hash fee bump transaction: %s", e)
+	}
+	signedHash, e := signedTx.Hash(network)
+	if e != nil {
+		return nil, fmt.Errorf("could not hash fee bump transaction returned by remote signer: %s", e)
+	}
+	if originalHash != signedHash {
+		return nil, fmt.Errorf("remote signer returned a different fee bump transaction (hash=%x) than the one sent (hash=%x)", signedHash, originalHash)
+	}
+	return signedTx, nil
+}
+
+// requestSignature sends the transaction XDR to the remote signer and returns the signed transaction XDR
+func (s *remoteSigner) requestSignature(txeB64 string, network string, accounts []string) (string, error) {
+	reqBody, e := json.Marshal(signRequest{
+		NetworkPassphrase: network,
+		TxXDR:             txeB64,
+		Accounts:          accounts,
+	})
+	if e != nil {
+		return "", fmt.Errorf("could not serialize sign request: %s", e)
+	}
+
+	req, e := http.NewRequest("POST", s.url, bytes.NewReader(reqBody))
+	if e != nil {
+		return "", fmt.Errorf("could not make sign request: %s", e)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if s.authToken != "" {
+		req.Header.Set("Authorization", "Bearer "+s.authToken)
+	}
+
+	resp, e := s.client.Do(req)
+	if e != nil {
+		return "", fmt.Errorf("error calling remote signer: %s", e)
+	}
+	defer resp.Body.Close()
+
+	respBody, e := ioutil.ReadAll(resp.Body)
+	if e != nil {
+		return "", fmt.Errorf("could not read response from remote signer: %s", e)
+	}
+
+	var signResp signResponse
+	e = json.Unmarshal(respBody, &signResp)
+	if e != nil {
+		return "", fmt.Errorf("could not parse response from remote signer (status=%d): %s", resp.StatusCode, e)
+	}
+	if resp.StatusCode != http.StatusOK || signResp.Error != "" {
+		return "", fmt.Errorf("remote signer refused to sign transaction (status=%d): %s", resp.StatusCode, signResp.Error)
+	}
+	return signResp.TxXDR, nil
+}
+
+func parseTransaction(txeB64 string) (*txnbuild.Transaction, error) {
+	genericTx, e := txnbuild.TransactionFromXDR(txeB64)
+	if e != nil {
+		return nil, e
+	}
+	tx, ok := genericTx.Transaction()
+	if !ok {
+		return nil, fmt.Errorf("XDR was not a regular transaction")
+	}
+	return tx, nil
+}
+
+func parseFeeBumpTransaction(txeB64 string) (*txnbuild.FeeBumpTransaction, error) {
+	genericTx, e := txnbuild.TransactionFromXDR(txeB64)
+	if e != nil {
+		return nil, e
+	}
+	tx, ok := genericTx.FeeBump()
+	if !ok {
+		return nil, fmt.Errorf("XDR was not a fee bump transaction")
+	}
+	return tx, nil
+}
@@ -0,0 +1,97 @@
+package signing
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/api"
+)
+
+// SignPolicy is enforced by the signing service before it signs a transaction, returning an error refuses to sign.
+// For fee bump transactions the policy is enforced on the inner transaction
+type SignPolicy func(tx *txnbuild.Transaction, network string, accounts []string) error
+
+// MakeRemoteSignerStandIn makes an http.Handler that speaks the same protocol as the remote signing service, but signs with the
+// passed in local signer. This is a stand-in for the real service that can be used in tests or when running everything locally.
+// policy is optional and authToken is only checked when it is non-empty
+func MakeRemoteSignerStandIn(signer api.Signer, policy SignPolicy, authToken string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != "POST" {
+			writeSignResponse(w, http.StatusMethodNotAllowed, signResponse{Error: fmt.Sprintf("method %s not allowed", r.Method)})
+			return
+		}
+		if authToken != "" && r.Header.Get("Authorization") != "Bearer "+authToken {
+			writeSignResponse(w, http.StatusUnauthorized, signResponse{Error: "unauthorized"})
+			return
+		}
+
+		var req signRequest
+		e := json.NewDecoder(r.Body).Decode(&req)
+		if e != nil {
+			writeSignResponse(w, http.StatusBadRequest, signResponse{Error: fmt.Sprintf("could not parse request: %s", e)})
+			return
+		}
+
+		genericTx, e := txnbuild.TransactionFromXDR(req.TxXDR)
+		if e != nil {
+			writeSignResponse(w, http.StatusBadRequest, signResponse{Error: fmt.Sprintf("could not parse transaction: %s", e)})
+			return
+		}
+		tx, isRegularTx := genericTx.Transaction()
+		feeBumpTx, isFeeBumpTx := genericTx.FeeBump()
+		if !isRegularTx && !isFeeBumpTx {
+			writeSignResponse(w, http.StatusBadRequest, signResponse{Error: "could not parse transaction: unknown transaction type"})
+			return
+		}
+		if isFeeBumpTx {
+			tx = feeBumpTx.InnerTransaction()
+		}
+
+		if policy != nil {
+			e = policy(tx, req.NetworkPassphrase, req.Accounts)
+			if e != nil {
+				log.Printf("remote signer stand-in: policy refused to sign transaction: %s\n", e)
+				writeSignResponse(w, http.StatusForbidden, signResponse{Error: fmt.Sprintf("policy violation: %s", e)})
+				return
+			}
+		}
+
+		var txeB64 string
+		if isFeeBumpTx {
+			signedTx, e := signer.SignFeeBump(feeBumpTx, req.NetworkPassphrase, req.Accounts...)
+			if e != nil {
+				writeSignResponse(w, http.StatusInternalServerError, signResponse{Error: fmt.Sprintf("could not sign fee bump transaction: %s", e)})
+				return
+			}
+			txeB64, e = signedTx.Base64()
+			if e != nil {
+				writeSignResponse(w, http.StatusInternalServerError, signResponse{Error: fmt.Sprintf("could not encode fee bump transaction: %s", e)})
+				return
+			}
+		} else {
+			signedTx, e := signer.Sign(tx, req.NetworkPassphrase, req.Accounts...)
+			if e != nil {
+				writeSignResponse(w, http.StatusInternalServerError, signResponse{Error: fmt.Sprintf("could not sign transaction: %s", e)})
+				return
+			}
+			txeB64, e = signedTx.Base64()
+			if e != nil {
+				writeSignResponse(w, http.StatusInternalServerError, signResponse{Error: fmt.Sprintf("could not encode transaction: %s", e)})
+				return
+			}
+		}
+		writeSignResponse(w, http.StatusOK, signResponse{TxXDR: txeB64})
+	})
+}
+
+func writeSignResponse(w http.ResponseWriter, status int, resp signResponse) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	e := json.NewEncoder(w).Encode(resp)
+	if e != nil {
+		log.Printf("remote signer stand-in: unable to write response: %s\n", e)
+	}
+}
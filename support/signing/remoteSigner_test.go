@@ -0,0 +1,125 @@
+package signing
+
+import (
+	"fmt"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/network"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stretchr/testify/assert"
+)
+
+func makeTestTx(t *testing.T, account string) *txnbuild.Transaction {
+	tx, e := txnbuild.NewTransaction(
+		txnbuild.TransactionParams{
+			SourceAccount:        &txnbuild.SimpleAccount{AccountID: account, Sequence: 1},
+			IncrementSequenceNum: true,
+			BaseFee:              100,
+			Operations:           []txnbuild.Operation{&txnbuild.BumpSequence{BumpTo: 10}},
+			Timebounds:           txnbuild.NewInfiniteTimeout(),
+		},
+	)
+	if !assert.NoError(t, e) {
+		t.FailNow()
+	}
+	return tx
+}
+
+func TestRemoteSigner(t *testing.T) {
+	kp, e := keypair.Random()
+	if !assert.NoError(t, e) {
+		return
+	}
+	localSigner, e := MakeSeedSigner(kp.Seed())
+	if !assert.NoError(t, e) {
+		return
+	}
+	refuseAll := func(tx *txnbuild.Transaction, network string, accounts []string) error {
+		return fmt.Errorf("refusing all transactions")
+	}
+
+	testCases := []struct {
+		name        string
+		policy      SignPolicy
+		serverToken string
+		clientToken string
+		wantErr     bool
+	}{
+		{
+			name:        "no policy",
+			policy:      nil,
+			serverToken: "",
+			clientToken: "",
+			wantErr:     false,
+		}, {
+			name:        "matching auth token",
+			policy:      nil,
+			serverToken: "token",
+			clientToken: "token",
+			wantErr:     false,
+		}, {
+			name:        "wrong auth token",
+			policy:      nil,
+			serverToken: "token",
+			clientToken: "wrong",
+			wantErr:     true,
+		}, {
+			name:        "policy refuses",
+			policy:      refuseAll,
+			serverToken: "",
+			clientToken: "",
+			wantErr:     true,
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.name, func(t *testing.T) {
+			server := httptest.NewServer(MakeRemoteSignerStandIn(localSigner, kase.policy, kase.serverToken))
+			defer server.Close()
+
+			signer, e := MakeRemoteSigner(server.URL, kase.clientToken, 5*time.Second)
+			if !assert.NoError(t, e) {
+				return
+			}
+
+			tx := makeTestTx(t, kp.Address())
+			signedTx, e := signer.Sign(tx, network.TestNetworkPassphrase, kp.Address())
+			if kase.wantErr {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, 1, len(signedTx.Signatures()))
+
+			// the remote signature should be the same as signing locally
+			locallySignedTx, e := localSigner.Sign(tx, network.TestNetworkPassphrase, kp.Address())
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, locallySignedTx.Signatures(), signedTx.Signatures())
+		})
+	}
+}
+
+func TestSeedSignerUnknownAccount(t *testing.T) {
+	kp, e := keypair.Random()
+	if !assert.NoError(t, e) {
+		return
+	}
+	other, e := keypair.Random()
+	if !assert.NoError(t, e) {
+		return
+	}
+	signer, e := MakeSeedSigner(kp.Seed(), "")
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	_, e = signer.Sign(makeTestTx(t, kp.Address()), network.TestNetworkPassphrase, other.Address())
+	assert.Error(t, e)
+}
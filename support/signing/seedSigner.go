@@ -0,0 +1,88 @@
+package signing
+
+import (
+	"fmt"
+
+	"github.com/stellar/go/keypair"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/api"
+)
+
+// seedSigner signs transactions with secret seeds held in memory
+type seedSigner struct {
+	keypairs map[string]*keypair.Full
+}
+
+// ensure seedSigner implements the api.Signer interface
+var _ api.Signer = &seedSigner{}
+
+// MakeSeedSigner makes a signer from the passed in secret seeds, empty seeds are ignored
+func MakeSeedSigner(seeds ...string) (api.Signer, error) {
+	keypairs := map[string]*keypair.Full{}
+	for i, s := range seeds {
+		if s == "" {
+			continue
+		}
+
+		kp, e := keypair.Parse(s)
+		if e != nil {
+			return nil, fmt.Errorf("cannot parse seed into keypair at index %d: %s", i, e)
+		}
+		fullKP, ok := kp.(*keypair.Full)
+		if !ok {
+			return nil, fmt.Errorf("value at index %d was not a secret seed", i)
+		}
+		keypairs[fullKP.Address()] = fullKP
+	}
+
+	return &seedSigner{
+		keypairs: keypairs,
+	}, nil
+}
+
+// Sign impl
+func (s *seedSigner) Sign(tx *txnbuild.Transaction, network string, accounts ...string) (*txnbuild.Transaction, error) {
+	kps, e := s.lookup(accounts)
+	if e != nil {
+		return nil, e
+	}
+
+	signedTx, e := tx.Sign(network, kps...)
+	if e != nil {
+		return nil, fmt.Errorf("error signing transaction: %s", e)
+	}
+	return signedTx, nil
+}
+
+// SignFeeBump impl
+func (s *seedSigner) SignFeeBump(tx *txnbuild.FeeBumpTransaction, network string, accounts ...string) (*txnbuild.FeeBumpTransaction, error) {
+	kps, e := s.lookup(accounts)
+	if e != nil {
+		return nil, e
+	}
+
+	signedTx, e := tx.Sign(network, kps...)
+	if e != nil {
+		return nil, fmt.Errorf("error signing fee bump transaction: %s", e)
+	}
+	return signedTx, nil
+}
+
+// lookup returns the keypairs for the accounts, skipping duplicate accounts
+func (s *seedSigner) lookup(accounts []string) ([]*keypair.Full, error) {
+	kps := []*keypair.Full{}
+	seen := map[string]bool{}
+	for _, a := range accounts {
+		if seen[a] {
+			continue
+		}
+		seen[a] = true
+
+		kp, ok := s.keypairs[a]
+		if !ok {
+			return nil, fmt.Errorf("no secret seed available to sign for account %s", a)
+		}
+		kps = append(kps, kp)
+	}
+	return kps, nil
+}
@@ -19,3 +19,22 @@ func (t *ExchangeAPIKeysToml) ToExchangeAPIKeys() []api.ExchangeAPIKey {
 	}
 	return apiKeys
 }
+
+// FeedHeadersToml is the toml representation of the named sets of headers used by json price feeds
+type FeedHeadersToml []struct {
+	Name   string `valid:"-" toml:"NAME"`
+	Header string `valid:"-" toml:"HEADER"`
+	Value  string `valid:"-" toml:"VALUE"`
+}
+
+// ToPriceFeedHeaders converts object, grouping the headers by name
+func (t *FeedHeadersToml) ToPriceFeedHeaders() map[string]map[string]string {
+	feedHeaders := map[string]map[string]string{}
+	for _, header := range *t {
+		if _, ok := feedHeaders[header.Name]; !ok {
+			feedHeaders[header.Name] = map[string]string{}
+		}
+		feedHeaders[header.Name][header.Header] = header.Value
+	}
+	return feedHeaders
+}
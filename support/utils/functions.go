@@ -201,6 +201,15 @@ func ParseNetwork(horizonURL string) string {
 	return network.PublicNetworkPassphrase
 }
 
+// ResolveNetwork returns the networkPassphrase if it is set, otherwise it falls back to ParseNetwork on the horizon url.
+// This allows using private and standalone networks whose horizon url does not tell us which network they are on
+func ResolveNetwork(networkPassphrase string, horizonURL string) string {
+	if networkPassphrase != "" {
+		return networkPassphrase
+	}
+	return ParseNetwork(horizonURL)
+}
+
 // GetJSON is a helper method to get json from a URL
 func GetJSON(client http.Client, url string, target interface{}) error {
 	r, err := client.Get(url)
@@ -13,6 +13,7 @@ type Config struct {
 	AllowInactiveMinutes int32  `valid:"-" toml:"ALLOW_INACTIVE_MINUTES"` // bots that are inactive for more than this time will have its offers deleted
 	TickIntervalSeconds  int32  `valid:"-" toml:"TICK_INTERVAL_SECONDS"`
 	HorizonURL           string `valid:"-" toml:"HORIZON_URL"`
+	NetworkPassphrase    string `valid:"-" toml:"NETWORK_PASSPHRASE"` // optional, needed when the HORIZON_URL is not on the public or test network
 
 	TradingAccount *string
 	SourceAccount  *string // can be nil
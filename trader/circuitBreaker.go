@@ -0,0 +1,138 @@
+package trader
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/stellar/kelp/api"
+)
+
+// priceSample is a price observed by the circuit breaker at a point in time
+type priceSample struct {
+	time  time.Time
+	price float64
+}
+
+// CircuitBreaker halts quoting when the price moves too fast, i.e. by more than maxMove within the window. Quoting resumes once
+// the price has not moved too fast for the cooldown, after which spreads are widened for a while to protect against aftershocks
+type CircuitBreaker struct {
+	maxMove       float64
+	window        time.Duration
+	cooldown      time.Duration
+	deleteOffers  bool
+	widenSpread   float64
+	widenDuration time.Duration
+	feed          api.PriceFeed // can be nil, in which case the mid price of the market is used
+	now           func() time.Time
+
+	// uninitialized runtime vars
+	samples     []priceSample
+	haltedUntil time.Time
+	widenUntil  time.Time
+}
+
+// MakeCircuitBreaker is a factory method, maxMove and widenSpread are fractions (ex: 0.05 for 5%)
+func MakeCircuitBreaker(
+	maxMove float64,
+	window time.Duration,
+	cooldown time.Duration,
+	deleteOffers bool,
+	widenSpread float64,
+	widenDuration time.Duration,
+	feed api.PriceFeed,
+) (*CircuitBreaker, error) {
+	return makeCircuitBreaker(maxMove, window, cooldown, deleteOffers, widenSpread, widenDuration, feed, time.Now)
+}
+
+func makeCircuitBreaker(
+	maxMove float64,
+	window time.Duration,
+	cooldown time.Duration,
+	deleteOffers bool,
+	widenSpread float64,
+	widenDuration time.Duration,
+	feed api.PriceFeed,
+	now func() time.Time,
+) (*CircuitBreaker, error) {
+	if maxMove <= 0.0 {
+		return nil, fmt.Errorf("max move of the circuit breaker needs to be > 0.0, was %.4f", maxMove)
+	}
+	if window <= 0 {
+		return nil, fmt.Errorf("window of the circuit breaker needs to be > 0, was %s", window)
+	}
+	if widenSpread < 0.0 || widenSpread >= 1.0 {
+		return nil, fmt.Errorf("spread widening of the circuit breaker needs to be >= 0.0 and < 1.0, was %.4f", widenSpread)
+	}
+
+	return &CircuitBreaker{
+		maxMove:       maxMove,
+		window:        window,
+		cooldown:      cooldown,
+		deleteOffers:  deleteOffers,
+		widenSpread:   widenSpread,
+		widenDuration: widenDuration,
+		feed:          feed,
+		now:           now,
+		samples:       []priceSample{},
+	}, nil
+}
+
+// HasFeed returns true if the circuit breaker uses a reference feed instead of the mid price of the market
+func (c *CircuitBreaker) HasFeed() bool {
+	return c.feed != nil
+}
+
+// FeedPrice fetches the price from the reference feed
+func (c *CircuitBreaker) FeedPrice() (float64, error) {
+	return c.feed.GetPrice()
+}
+
+// ShouldDeleteOffers returns true if offers should be deleted while the circuit breaker is halted
+func (c *CircuitBreaker) ShouldDeleteOffers() bool {
+	return c.deleteOffers
+}
+
+// Update records the price and returns true if quoting is halted, along with a non-empty reason when this price caused the halt
+func (c *CircuitBreaker) Update(price float64) (bool, string) {
+	now := c.now()
+
+	// drop samples that have fallen out of the window
+	firstInWindow := 0
+	for firstInWindow < len(c.samples) && now.Sub(c.samples[firstInWindow].time) > c.window {
+		firstInWindow++
+	}
+	c.samples = append(c.samples[firstInWindow:], priceSample{time: now, price: price})
+
+	reason := ""
+	for _, s := range c.samples {
+		if s.price <= 0.0 {
+			continue
+		}
+		move := math.Abs(price-s.price) / s.price
+		if move > c.maxMove {
+			reason = fmt.Sprintf("price moved by %.4f from %.10f to %.10f within %s which exceeds the max move of %.4f", move, s.price, price, now.Sub(s.time), c.maxMove)
+			break
+		}
+	}
+
+	if reason != "" {
+		// a move during the cooldown extends it
+		c.haltedUntil = now.Add(c.cooldown)
+		c.widenUntil = c.haltedUntil.Add(c.widenDuration)
+		// start over from the latest price so we do not keep tripping on the same move once the cooldown is over
+		c.samples = []priceSample{priceSample{time: now, price: price}}
+		return true, reason
+	}
+	return now.Before(c.haltedUntil), ""
+}
+
+// Widening returns the fraction by which spreads should be widened, which is 0.0 when not recovering from a halt. It is applied by the
+// level providers of the strategy so the widened levels are the targets that resting offers are compared against
+func (c *CircuitBreaker) Widening() float64 {
+	now := c.now()
+	if now.Before(c.haltedUntil) || !now.Before(c.widenUntil) {
+		return 0.0
+	}
+	return c.widenSpread
+}
@@ -0,0 +1,43 @@
+package trader
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	c, e := makeCircuitBreaker(0.05, time.Minute, 5*time.Minute, false, 0.01, 10*time.Minute, nil, func() time.Time { return now })
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	// each step advances the clock by the elapsed duration before updating the price
+	steps := []struct {
+		elapsed      time.Duration
+		price        float64
+		wantHalted   bool
+		wantTripped  bool
+		wantWidening float64
+	}{
+		{elapsed: 0, price: 1.00, wantHalted: false, wantTripped: false, wantWidening: 0.0},
+		{elapsed: 30 * time.Second, price: 1.04, wantHalted: false, wantTripped: false, wantWidening: 0.0},
+		// slow moves outside the window do not trip the circuit breaker
+		{elapsed: 50 * time.Second, price: 1.08, wantHalted: false, wantTripped: false, wantWidening: 0.0},
+		{elapsed: 10 * time.Second, price: 1.20, wantHalted: true, wantTripped: true, wantWidening: 0.0},
+		{elapsed: 4 * time.Minute, price: 1.21, wantHalted: true, wantTripped: false, wantWidening: 0.0},
+		// resumes after the cooldown with widened spreads
+		{elapsed: 2 * time.Minute, price: 1.21, wantHalted: false, wantTripped: false, wantWidening: 0.01},
+		{elapsed: 10 * time.Minute, price: 1.21, wantHalted: false, wantTripped: false, wantWidening: 0.0},
+	}
+
+	for i, s := range steps {
+		now = now.Add(s.elapsed)
+		isHalted, reason := c.Update(s.price)
+		assert.Equal(t, s.wantHalted, isHalted, "step %d", i)
+		assert.Equal(t, s.wantTripped, reason != "", "step %d", i)
+		assert.Equal(t, s.wantWidening, c.Widening(), "step %d", i)
+	}
+}
@@ -3,8 +3,10 @@ package trader
 import (
 	"fmt"
 
+	"github.com/stellar/go/keypair"
 	hProtocol "github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/kelp/support/postgresdb"
+	"github.com/stellar/kelp/support/signing"
 	"github.com/stellar/kelp/support/toml"
 	"github.com/stellar/kelp/support/utils"
 )
@@ -14,9 +16,22 @@ const XLM = "XLM"
 
 // FeeConfig represents input data for how to deal with network fees
 type FeeConfig struct {
-	CapacityTrigger float64 `valid:"-" toml:"CAPACITY_TRIGGER" json:"capacity_trigger"`     // trigger when "ledger_capacity_usage" in /fee_stats is >= this value
-	Percentile      uint8   `valid:"-" toml:"PERCENTILE" json:"percentile"`                 // percentile computation to use from /fee_stats (10, 20, ..., 90, 95, 99)
-	MaxOpFeeStroops uint64  `valid:"-" toml:"MAX_OP_FEE_STROOPS" json:"max_op_fee_stroops"` // max fee in stroops per operation to use
+	CapacityTrigger              float64 `valid:"-" toml:"CAPACITY_TRIGGER" json:"capacity_trigger"`                               // trigger when "ledger_capacity_usage" in /fee_stats is >= this value
+	Percentile                   uint8   `valid:"-" toml:"PERCENTILE" json:"percentile"`                                           // percentile computation to use from /fee_stats (10, 20, ..., 90, 95, 99)
+	MaxOpFeeStroops              uint64  `valid:"-" toml:"MAX_OP_FEE_STROOPS" json:"max_op_fee_stroops"`                           // max fee in stroops per operation to use
+	FeeBump                      bool    `valid:"-" toml:"FEE_BUMP" json:"fee_bump"`                                               // resubmit timed out or underpriced transactions as a fee bump transaction up to MAX_OP_FEE_STROOPS
+	DailyBudgetStroops           uint64  `valid:"-" toml:"DAILY_BUDGET_STROOPS" json:"daily_budget_stroops"`                       // max fees in stroops to spend per UTC day before pausing non-essential updates, 0 is unlimited
+	DailyBudgetPriorityThreshold float64 `valid:"-" toml:"DAILY_BUDGET_PRIORITY_THRESHOLD" json:"daily_budget_priority_threshold"` // submit only deletes and top-of-book updates when less than this fraction of the daily budget is left
+}
+
+// SignerConfig represents input data for how transactions are signed
+type SignerConfig struct {
+	Type                     string `valid:"-" toml:"TYPE" json:"type"`                                               // one of "seed" (default), "keystore" or "remote"
+	KeystorePath             string `valid:"-" toml:"KEYSTORE_PATH" json:"keystore_path"`                             // path to the encrypted keystore file, used by the "keystore" type
+	KeystorePassphraseEnvVar string `valid:"-" toml:"KEYSTORE_PASSPHRASE_ENV_VAR" json:"keystore_passphrase_env_var"` // environment variable that holds the keystore passphrase
+	RemoteURL                string `valid:"-" toml:"REMOTE_URL" json:"remote_url"`                                   // URL of the remote signing service, used by the "remote" type
+	RemoteAuthToken          string `valid:"-" toml:"REMOTE_AUTH_TOKEN" json:"remote_auth_token"`                     // bearer token sent to the remote signing service
+	RemoteTimeoutMillis      uint32 `valid:"-" toml:"REMOTE_TIMEOUT_MILLIS" json:"remote_timeout_millis"`             // timeout for calls to the remote signing service
 }
 
 // BotConfig represents the configuration params for the bot
@@ -34,12 +49,23 @@ type BotConfig struct {
 	SleepMode                          string     `valid:"-" toml:"SLEEP_MODE" json:"sleep_mode"`
 	DeleteCyclesThreshold              int64      `valid:"-" toml:"DELETE_CYCLES_THRESHOLD" json:"delete_cycles_threshold"`
 	SubmitMode                         string     `valid:"-" toml:"SUBMIT_MODE" json:"submit_mode"`
+	SdexUseManageBuyOffer              bool       `valid:"-" toml:"SDEX_USE_MANAGE_BUY_OFFER" json:"sdex_use_manage_buy_offer"`
+	ChannelSecretSeeds                 []string   `valid:"-" toml:"CHANNEL_SECRET_SEEDS" json:"channel_secret_seeds"`
+	ChannelAccounts                    []string   `valid:"-" toml:"CHANNEL_ACCOUNTS" json:"channel_accounts"`
+	TradingAccountAddress              string     `valid:"-" toml:"TRADING_ACCOUNT" json:"trading_account"`
+	SourceAccountAddress               string     `valid:"-" toml:"SOURCE_ACCOUNT" json:"source_account"`
 	FillTrackerSleepMillis             uint32     `valid:"-" toml:"FILL_TRACKER_SLEEP_MILLIS" json:"fill_tracker_sleep_millis"`
 	FillTrackerDeleteCyclesThreshold   int64      `valid:"-" toml:"FILL_TRACKER_DELETE_CYCLES_THRESHOLD" json:"fill_tracker_delete_cycles_threshold"`
+	FillTrackerStream                  bool       `valid:"-" toml:"FILL_TRACKER_STREAM" json:"fill_tracker_stream"`
 	SynchronizeStateLoadEnable         bool       `valid:"-" toml:"SYNCHRONIZE_STATE_LOAD_ENABLE"`
 	SynchronizeStateLoadMaxRetries     int        `valid:"-" toml:"SYNCHRONIZE_STATE_LOAD_MAX_RETRIES"`
 	FillTrackerLastTradeCursorOverride string     `valid:"-" toml:"FILL_TRACKER_LAST_TRADE_CURSOR_OVERRIDE"`
 	HorizonURL                         string     `valid:"-" toml:"HORIZON_URL" json:"horizon_url"`
+	HorizonURLs                        []string   `valid:"-" toml:"HORIZON_URLS" json:"horizon_urls"`
+	NetworkPassphrase                  string     `valid:"-" toml:"NETWORK_PASSPHRASE" json:"network_passphrase"`
+	HorizonMaxLedgerLag                int64      `valid:"-" toml:"HORIZON_MAX_LEDGER_LAG" json:"horizon_max_ledger_lag"`
+	HorizonMaxErrorRate                float64    `valid:"-" toml:"HORIZON_MAX_ERROR_RATE" json:"horizon_max_error_rate"`
+	HorizonHealthCheckMillis           int64      `valid:"-" toml:"HORIZON_HEALTH_CHECK_MILLIS" json:"horizon_health_check_millis"`
 	CcxtRestURL                        *string    `valid:"-" toml:"CCXT_REST_URL" json:"ccxt_rest_url"`
 	DollarValueFeedBaseAsset           string     `valid:"-" toml:"DOLLAR_VALUE_FEED_BASE_ASSET" json:"dollar_value_feed_base_asset"`
 	DollarValueFeedQuoteAsset          string     `valid:"-" toml:"DOLLAR_VALUE_FEED_QUOTE_ASSET" json:"dollar_value_feed_quote_asset"`
@@ -52,9 +78,27 @@ type BotConfig struct {
 	CentralizedMinQuoteVolumeOverride  *float64                 `valid:"-" toml:"CENTRALIZED_MIN_QUOTE_VOLUME_OVERRIDE" json:"centralized_min_quote_volume_override"`
 	PostgresDbConfig                   *postgresdb.Config       `valid:"-" toml:"POSTGRES_DB" json:"postgres_db"`
 	DbOverrideAccountID                string                   `valid:"-" toml:"DB_OVERRIDE__ACCOUNT_ID" json:"db_override__account_id"`
+	NotionalFeedType                   string                   `valid:"-" toml:"NOTIONAL_FEED_TYPE" json:"notional_feed_type"`
+	NotionalFeedURL                    string                   `valid:"-" toml:"NOTIONAL_FEED_URL" json:"notional_feed_url"`
 	Filters                            []string                 `valid:"-" toml:"FILTERS" json:"filters"`
+	FilterGroups                       map[string][]string      `valid:"-" toml:"FILTER_GROUPS" json:"filter_groups"`
+	FilterStateDir                     string                   `valid:"-" toml:"FILTER_STATE_DIR" json:"filter_state_dir"`
+	FilterStateMaxAgeSeconds           int64                    `valid:"-" toml:"FILTER_STATE_MAX_AGE_SECONDS" json:"filter_state_max_age_seconds"`
 	AlertType                          string                   `valid:"-" toml:"ALERT_TYPE" json:"alert_type"`
 	AlertAPIKey                        string                   `valid:"-" toml:"ALERT_API_KEY" json:"alert_api_key"`
+	KillSwitchMaxDrawdown              float64                  `valid:"-" toml:"KILL_SWITCH_MAX_DRAWDOWN" json:"kill_switch_max_drawdown"`
+	KillSwitchHighReset                string                   `valid:"-" toml:"KILL_SWITCH_HIGH_RESET" json:"kill_switch_high_reset"`
+	KillSwitchControlFile              string                   `valid:"-" toml:"KILL_SWITCH_CONTROL_FILE" json:"kill_switch_control_file"`
+	CircuitBreakerMaxMove              float64                  `valid:"-" toml:"CIRCUIT_BREAKER_MAX_MOVE" json:"circuit_breaker_max_move"`
+	CircuitBreakerWindowSeconds        int64                    `valid:"-" toml:"CIRCUIT_BREAKER_WINDOW_SECONDS" json:"circuit_breaker_window_seconds"`
+	CircuitBreakerCooldownSeconds      int64                    `valid:"-" toml:"CIRCUIT_BREAKER_COOLDOWN_SECONDS" json:"circuit_breaker_cooldown_seconds"`
+	CircuitBreakerDeleteOffers         bool                     `valid:"-" toml:"CIRCUIT_BREAKER_DELETE_OFFERS" json:"circuit_breaker_delete_offers"`
+	CircuitBreakerWidenSpread          float64                  `valid:"-" toml:"CIRCUIT_BREAKER_WIDEN_SPREAD" json:"circuit_breaker_widen_spread"`
+	CircuitBreakerWidenSeconds         int64                    `valid:"-" toml:"CIRCUIT_BREAKER_WIDEN_SECONDS" json:"circuit_breaker_widen_seconds"`
+	CircuitBreakerFeedType             string                   `valid:"-" toml:"CIRCUIT_BREAKER_FEED_TYPE" json:"circuit_breaker_feed_type"`
+	CircuitBreakerFeedURL              string                   `valid:"-" toml:"CIRCUIT_BREAKER_FEED_URL" json:"circuit_breaker_feed_url"`
+	MaxOpsPerMinute                    int                      `valid:"-" toml:"MAX_OPS_PER_MINUTE" json:"max_ops_per_minute"`
+	MaxTxsPerHour                      int                      `valid:"-" toml:"MAX_TXS_PER_HOUR" json:"max_txs_per_hour"`
 	MonitoringPort                     uint16                   `valid:"-" toml:"MONITORING_PORT" json:"monitoring_port"`
 	MonitoringTLSCert                  string                   `valid:"-" toml:"MONITORING_TLS_CERT" json:"monitoring_tls_cert"`
 	MonitoringTLSKey                   string                   `valid:"-" toml:"MONITORING_TLS_KEY" json:"monitoring_tls_key"`
@@ -65,13 +109,16 @@ type BotConfig struct {
 	ExchangeAPIKeys                    toml.ExchangeAPIKeysToml `valid:"-" toml:"EXCHANGE_API_KEYS" json:"exchange_api_keys"`
 	ExchangeParams                     toml.ExchangeParamsToml  `valid:"-" toml:"EXCHANGE_PARAMS" json:"exchange_params"`
 	ExchangeHeaders                    toml.ExchangeHeadersToml `valid:"-" toml:"EXCHANGE_HEADERS" json:"exchange_headers"`
+	PriceFeedHeaders                   toml.FeedHeadersToml     `valid:"-" toml:"PRICE_FEED_HEADERS" json:"price_feed_headers"`
+	Signer                             *SignerConfig            `valid:"-" toml:"SIGNER" json:"signer"`
 
 	// initialized later
-	tradingAccount *string
-	sourceAccount  *string // can be nil
-	assetBase      hProtocol.Asset
-	assetQuote     hProtocol.Asset
-	isTradingSdex  bool
+	tradingAccount  *string
+	sourceAccount   *string // can be nil
+	channelAccounts []string
+	assetBase       hProtocol.Asset
+	assetQuote      hProtocol.Asset
+	isTradingSdex   bool
 }
 
 // MakeBotConfig factory method for BotConfig
@@ -129,8 +176,11 @@ func (b BotConfig) String() string {
 		"EXCHANGE_API_KEYS":        utils.Hide,
 		"EXCHANGE_PARAMS":          utils.Hide,
 		"EXCHANGE_HEADERS":         utils.Hide,
+		"PRICE_FEED_HEADERS":       utils.Hide,
 		"SOURCE_SECRET_SEED":       utils.SecretKey2PublicKey,
 		"TRADING_SECRET_SEED":      utils.SecretKey2PublicKey,
+		"CHANNEL_SECRET_SEEDS":     utils.Hide,
+		"REMOTE_AUTH_TOKEN":        utils.Hide,
 		"ALERT_API_KEY":            utils.Hide,
 		"GOOGLE_CLIENT_ID":         utils.Hide,
 		"GOOGLE_CLIENT_SECRET":     utils.Hide,
@@ -151,6 +201,11 @@ func (b *BotConfig) SourceAccount() string {
 	return *b.sourceAccount
 }
 
+// ChannelAccountAddresses returns the addresses of the config's channel accounts, which is empty when not using channel accounts
+func (b *BotConfig) ChannelAccountAddresses() []string {
+	return b.channelAccounts
+}
+
 // AssetBase returns the config's assetBase
 func (b *BotConfig) AssetBase() hProtocol.Asset {
 	return b.assetBase
@@ -202,16 +257,85 @@ func (b *BotConfig) Init() error {
 	}
 	b.assetQuote = *asset
 
-	b.tradingAccount, e = utils.ParseSecret(b.TradingSecretSeed)
+	b.tradingAccount, e = parseAccount(b.TradingSecretSeed, b.TradingAccountAddress)
 	if e != nil {
-		return e
+		return fmt.Errorf("invalid trading account: %s", e)
 	}
 	if b.tradingAccount == nil {
 		return fmt.Errorf("no trading account specified")
 	}
 
-	b.sourceAccount, e = utils.ParseSecret(b.SourceSecretSeed)
-	return e
+	b.sourceAccount, e = parseAccount(b.SourceSecretSeed, b.SourceAccountAddress)
+	if e != nil {
+		return fmt.Errorf("invalid source account: %s", e)
+	}
+
+	b.channelAccounts, e = parseChannelAccounts(b.ChannelSecretSeeds, b.ChannelAccounts, b.isSeedSigner())
+	if e != nil {
+		return fmt.Errorf("invalid channel accounts: %s", e)
+	}
+	return nil
+}
+
+// isSeedSigner returns true when transactions are signed with the secret seeds in the config, which is the default signer
+func (b *BotConfig) isSeedSigner() bool {
+	return b.Signer == nil || b.Signer.Type == "" || b.Signer.Type == signing.SignerTypeSeed
+}
+
+// parseChannelAccounts returns the addresses of the channel accounts. Channel secret seeds are only allowed with the seed signer, other
+// signers need the channel accounts to be specified by address so their keys are not held in plaintext on the trading host
+func parseChannelAccounts(seeds []string, addresses []string, isSeedSigner bool) ([]string, error) {
+	if len(seeds) > 0 && len(addresses) > 0 {
+		return nil, fmt.Errorf("only one of CHANNEL_SECRET_SEEDS or CHANNEL_ACCOUNTS can be set")
+	}
+	if len(seeds) > 0 && !isSeedSigner {
+		return nil, fmt.Errorf("CHANNEL_SECRET_SEEDS can only be used with the seed signer, set CHANNEL_ACCOUNTS instead and hold the keys of the channel accounts in the [SIGNER]")
+	}
+
+	accounts := []string{}
+	for i, s := range seeds {
+		kp, e := keypair.Parse(s)
+		if e != nil {
+			return nil, fmt.Errorf("cannot parse channel account seed at index %d: %s", i, e)
+		}
+		if _, ok := kp.(*keypair.Full); !ok {
+			return nil, fmt.Errorf("channel account at index %d in CHANNEL_SECRET_SEEDS was not a secret seed", i)
+		}
+		accounts = append(accounts, kp.Address())
+	}
+	for i, a := range addresses {
+		kp, e := keypair.Parse(a)
+		if e != nil {
+			return nil, fmt.Errorf("cannot parse channel account address at index %d: %s", i, e)
+		}
+		if _, ok := kp.(*keypair.FromAddress); !ok {
+			return nil, fmt.Errorf("channel account at index %d in CHANNEL_ACCOUNTS needs to be an address, not a secret seed", i)
+		}
+		accounts = append(accounts, kp.Address())
+	}
+	return accounts, nil
+}
+
+// parseAccount returns the account from the secret seed, or from the address when the seed is held by a signer instead of the config
+func parseAccount(secretSeed string, address string) (*string, error) {
+	account, e := utils.ParseSecret(secretSeed)
+	if e != nil {
+		return nil, e
+	}
+	if account == nil {
+		// ParseSecret also accepts public keys
+		return utils.ParseSecret(address)
+	}
+
+	if address != "" && address != *account {
+		return nil, fmt.Errorf("secret seed is for account %s which does not match the specified account address %s", *account, address)
+	}
+	return account, nil
+}
+
+// SignerSeeds returns the secret seeds specified in the config, which are used by the default signer
+func (b *BotConfig) SignerSeeds() []string {
+	return append([]string{b.SourceSecretSeed, b.TradingSecretSeed}, b.ChannelSecretSeeds...)
 }
 
 // SleepMode defines when the bot sleeps, before (begin) or after (end) of update cycle
@@ -0,0 +1,85 @@
+package trader
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/stellar/go/keypair"
+)
+
+func TestParseChannelAccounts(t *testing.T) {
+	kp1, e := keypair.Random()
+	if !assert.NoError(t, e) {
+		return
+	}
+	kp2, e := keypair.Random()
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	testCases := []struct {
+		name         string
+		seeds        []string
+		addresses    []string
+		isSeedSigner bool
+		wantAccounts []string
+		wantErr      bool
+	}{
+		{
+			name:         "no channels",
+			isSeedSigner: true,
+			wantAccounts: []string{},
+		}, {
+			name:         "seeds with the seed signer",
+			seeds:        []string{kp1.Seed(), kp2.Seed()},
+			isSeedSigner: true,
+			wantAccounts: []string{kp1.Address(), kp2.Address()},
+		}, {
+			name:         "seeds with another signer",
+			seeds:        []string{kp1.Seed()},
+			isSeedSigner: false,
+			wantErr:      true,
+		}, {
+			name:         "addresses with another signer",
+			addresses:    []string{kp1.Address(), kp2.Address()},
+			isSeedSigner: false,
+			wantAccounts: []string{kp1.Address(), kp2.Address()},
+		}, {
+			name:         "addresses with the seed signer",
+			addresses:    []string{kp1.Address()},
+			isSeedSigner: true,
+			wantAccounts: []string{kp1.Address()},
+		}, {
+			name:         "seeds and addresses",
+			seeds:        []string{kp1.Seed()},
+			addresses:    []string{kp2.Address()},
+			isSeedSigner: true,
+			wantErr:      true,
+		}, {
+			name:         "address in seeds",
+			seeds:        []string{kp1.Address()},
+			isSeedSigner: true,
+			wantErr:      true,
+		}, {
+			name:         "seed in addresses",
+			addresses:    []string{kp1.Seed()},
+			isSeedSigner: false,
+			wantErr:      true,
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			accounts, e := parseChannelAccounts(k.seeds, k.addresses, k.isSeedSigner)
+			if k.wantErr {
+				assert.Error(t, e)
+				return
+			}
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, k.wantAccounts, accounts)
+		})
+	}
+}
@@ -0,0 +1,148 @@
+package trader
+
+import (
+	"fmt"
+	"io/ioutil"
+	"log"
+	"os"
+	"strings"
+	"time"
+)
+
+// killSwitchStateTripped and killSwitchStateArmed are the values of the first line of the kill switch control file
+const (
+	killSwitchStateTripped = "tripped"
+	killSwitchStateArmed   = "armed"
+)
+
+// KillSwitchHighReset is how often the high of the account value is reset when computing the drawdown
+type KillSwitchHighReset string
+
+// type of KillSwitchHighReset
+const (
+	KillSwitchHighResetSession KillSwitchHighReset = "session"
+	KillSwitchHighResetDaily   KillSwitchHighReset = "daily"
+)
+
+// ParseKillSwitchHighReset converts a string to a KillSwitchHighReset, defaults to session when empty
+func ParseKillSwitchHighReset(s string) (KillSwitchHighReset, error) {
+	if s == "" || s == string(KillSwitchHighResetSession) {
+		return KillSwitchHighResetSession, nil
+	} else if s == string(KillSwitchHighResetDaily) {
+		return KillSwitchHighResetDaily, nil
+	}
+	return KillSwitchHighResetSession, fmt.Errorf("invalid kill switch high reset '%s', needs to be either '%s' or '%s'", s, KillSwitchHighResetSession, KillSwitchHighResetDaily)
+}
+
+// KillSwitch stops the bot from placing orders once the value of the account draws down too far from its high. Once tripped it stays
+// tripped (including across restarts) until it is re-armed by deleting the control file or changing its first line to "armed"
+type KillSwitch struct {
+	maxDrawdown     float64
+	highReset       KillSwitchHighReset
+	controlFilePath string
+	now             func() time.Time
+
+	// uninitialized runtime vars
+	high    float64
+	highDay string
+	tripped bool
+}
+
+// MakeKillSwitch is a factory method, maxDrawdown is a fraction of the high (ex: 0.1 for 10%)
+func MakeKillSwitch(maxDrawdown float64, highReset KillSwitchHighReset, controlFilePath string) (*KillSwitch, error) {
+	return makeKillSwitch(maxDrawdown, highReset, controlFilePath, time.Now)
+}
+
+func makeKillSwitch(maxDrawdown float64, highReset KillSwitchHighReset, controlFilePath string, now func() time.Time) (*KillSwitch, error) {
+	if maxDrawdown <= 0.0 || maxDrawdown >= 1.0 {
+		return nil, fmt.Errorf("max drawdown of the kill switch needs to be between 0.0 and 1.0 (exclusive), was %.4f", maxDrawdown)
+	}
+	if controlFilePath == "" {
+		return nil, fmt.Errorf("the kill switch needs a control file so it stays tripped across restarts")
+	}
+
+	k := &KillSwitch{
+		maxDrawdown:     maxDrawdown,
+		highReset:       highReset,
+		controlFilePath: controlFilePath,
+		now:             now,
+	}
+	state, e := k.readControlFile()
+	if e != nil {
+		return nil, fmt.Errorf("could not read kill switch control file: %s", e)
+	}
+	k.tripped = state == killSwitchStateTripped
+	if k.tripped {
+		log.Printf("kill switch: starting in the tripped state from control file '%s', no orders will be placed until it is re-armed\n", controlFilePath)
+	}
+	return k, nil
+}
+
+// readControlFile returns the first line of the control file, or an empty string if it does not exist
+func (k *KillSwitch) readControlFile() (string, error) {
+	bytes, e := ioutil.ReadFile(k.controlFilePath)
+	if e != nil {
+		if os.IsNotExist(e) {
+			return "", nil
+		}
+		return "", fmt.Errorf("could not read file '%s': %s", k.controlFilePath, e)
+	}
+	return strings.TrimSpace(strings.SplitN(string(bytes), "\n", 2)[0]), nil
+}
+
+// IsTripped returns true when orders should not be placed, the kill switch is re-armed here when the control file allows it
+func (k *KillSwitch) IsTripped() (bool, error) {
+	if !k.tripped {
+		return false, nil
+	}
+
+	state, e := k.readControlFile()
+	if e != nil {
+		return true, fmt.Errorf("could not read kill switch control file: %s", e)
+	}
+	if state == killSwitchStateTripped {
+		return true, nil
+	}
+
+	log.Printf("kill switch: re-armed from control file '%s' (state='%s'), resetting the high\n", k.controlFilePath, state)
+	k.tripped = false
+	k.high = 0.0
+	k.highDay = ""
+	return false, nil
+}
+
+// Update records the current value of the account and returns a non-empty reason when this value trips the kill switch
+func (k *KillSwitch) Update(value float64) (string, error) {
+	if k.tripped {
+		return "", nil
+	}
+
+	today := k.now().UTC().Format("2006-01-02")
+	if k.highReset == KillSwitchHighResetDaily && today != k.highDay {
+		if k.highDay != "" {
+			log.Printf("kill switch: new day %s, resetting the high (was %.8f on %s)\n", today, k.high, k.highDay)
+		}
+		k.high = 0.0
+	}
+	k.highDay = today
+	if value > k.high {
+		k.high = value
+	}
+
+	drawdown := 0.0
+	if k.high > 0.0 {
+		drawdown = (k.high - value) / k.high
+	}
+	log.Printf("kill switch: value=%.8f, high=%.8f, drawdown=%.4f, maxDrawdown=%.4f\n", value, k.high, drawdown, k.maxDrawdown)
+	if drawdown <= k.maxDrawdown {
+		return "", nil
+	}
+
+	reason := fmt.Sprintf("drawdown of %.4f from the %s high of %.8f to %.8f exceeds the max drawdown of %.4f", drawdown, k.highReset, k.high, value, k.maxDrawdown)
+	k.tripped = true
+	e := ioutil.WriteFile(k.controlFilePath, []byte(fmt.Sprintf("%s\n%s: %s\n", killSwitchStateTripped, k.now().UTC().Format(time.RFC3339), reason)), 0644)
+	if e != nil {
+		return reason, fmt.Errorf("kill switch tripped but could not write control file '%s': %s", k.controlFilePath, e)
+	}
+	return reason, nil
+}
@@ -0,0 +1,118 @@
+package trader
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestKillSwitch(t *testing.T) {
+	testCases := []struct {
+		name        string
+		highReset   KillSwitchHighReset
+		values      []float64
+		wantTripped []bool
+	}{
+		{
+			name:        "within drawdown",
+			highReset:   KillSwitchHighResetSession,
+			values:      []float64{100.0, 120.0, 109.0},
+			wantTripped: []bool{false, false, false},
+		}, {
+			name:        "exceeds drawdown from high",
+			highReset:   KillSwitchHighResetSession,
+			values:      []float64{100.0, 120.0, 107.0, 130.0},
+			wantTripped: []bool{false, false, true, true},
+		}, {
+			// each value is a day later than the previous value
+			name:        "daily high resets",
+			highReset:   KillSwitchHighResetDaily,
+			values:      []float64{120.0, 107.0, 96.0},
+			wantTripped: []bool{false, false, false},
+		}, {
+			name:        "session high does not reset",
+			highReset:   KillSwitchHighResetSession,
+			values:      []float64{120.0, 110.0, 100.0},
+			wantTripped: []bool{false, false, true},
+		},
+	}
+
+	for _, kase := range testCases {
+		t.Run(kase.name, func(t *testing.T) {
+			dir, e := ioutil.TempDir("", "kelp_kill_switch")
+			if !assert.NoError(t, e) {
+				return
+			}
+			defer os.RemoveAll(dir)
+
+			now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+			k, e := makeKillSwitch(0.1, kase.highReset, filepath.Join(dir, "control.txt"), func() time.Time { return now })
+			if !assert.NoError(t, e) {
+				return
+			}
+
+			for i, v := range kase.values {
+				_, e = k.Update(v)
+				if !assert.NoError(t, e) {
+					return
+				}
+				isTripped, e := k.IsTripped()
+				if !assert.NoError(t, e) {
+					return
+				}
+				assert.Equal(t, kase.wantTripped[i], isTripped, "value at index %d", i)
+				now = now.Add(24 * time.Hour)
+			}
+		})
+	}
+}
+
+func TestKillSwitch_ControlFile(t *testing.T) {
+	dir, e := ioutil.TempDir("", "kelp_kill_switch")
+	if !assert.NoError(t, e) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	controlFile := filepath.Join(dir, "control.txt")
+
+	k, e := MakeKillSwitch(0.1, KillSwitchHighResetSession, controlFile)
+	if !assert.NoError(t, e) {
+		return
+	}
+	_, e = k.Update(100.0)
+	assert.NoError(t, e)
+	reason, e := k.Update(50.0)
+	assert.NoError(t, e)
+	assert.NotEqual(t, "", reason)
+
+	// the tripped state is persisted across restarts
+	restarted, e := MakeKillSwitch(0.1, KillSwitchHighResetSession, controlFile)
+	if !assert.NoError(t, e) {
+		return
+	}
+	isTripped, e := restarted.IsTripped()
+	assert.NoError(t, e)
+	assert.True(t, isTripped)
+
+	// re-arming uses the current value as the new high
+	e = ioutil.WriteFile(controlFile, []byte("armed\n"), 0644)
+	if !assert.NoError(t, e) {
+		return
+	}
+	isTripped, e = restarted.IsTripped()
+	assert.NoError(t, e)
+	assert.False(t, isTripped)
+	reason, e = restarted.Update(50.0)
+	assert.NoError(t, e)
+	assert.Equal(t, "", reason)
+
+	// invalid configs
+	_, e = MakeKillSwitch(0.0, KillSwitchHighResetSession, controlFile)
+	assert.Error(t, e)
+	_, e = MakeKillSwitch(0.1, KillSwitchHighResetSession, "")
+	assert.Error(t, e)
+}
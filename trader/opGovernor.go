@@ -0,0 +1,314 @@
+package trader
+
+import (
+	"fmt"
+	"log"
+	"strconv"
+	"sync"
+	"time"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/support/utils"
+)
+
+// opPriority is the order in which ops are kept when the op governor cannot submit all of them, lower values are kept first
+type opPriority int
+
+// these are the priorities of ops
+const (
+	opPriorityDelete opPriority = iota
+	opPriorityTopOfBook
+	opPriorityOther
+)
+
+// opSubmission is a transaction submitted at a point in time
+type opSubmission struct {
+	time   time.Time
+	numOps int
+}
+
+// OpGovernor limits the number of ops submitted per minute and transactions submitted per hour, and holds back updates to deeper
+// levels when the daily fee budget is running low. When it cannot submit all the ops it keeps deletes first, since those reduce our
+// exposure, then the ops that update the best offer on each side of the book, and then the remaining ops in the order they were made.
+// Deletes are always submitted, even when that exceeds the limits
+type OpGovernor struct {
+	maxOpsPerMinute      int            // 0 is unlimited
+	maxTxsPerHour        int            // 0 is unlimited
+	feeBudgetThreshold   float64        // only deletes and top-of-book updates are submitted when less than this fraction of the fee budget is left
+	feeBudgetRemainingFn func() float64 // can be nil
+	now                  func() time.Time
+
+	// uses mutex for submissions since transactions can be recorded while they are submitted asynchronously
+	mutex       *sync.Mutex
+	submissions []opSubmission // submissions within the last hour
+}
+
+// MakeOpGovernor is a factory method, feeBudgetRemainingFn returns the fraction of the daily fee budget that has not been spent yet
+func MakeOpGovernor(
+	maxOpsPerMinute int,
+	maxTxsPerHour int,
+	feeBudgetThreshold float64,
+	feeBudgetRemainingFn func() float64,
+) (*OpGovernor, error) {
+	return makeOpGovernor(maxOpsPerMinute, maxTxsPerHour, feeBudgetThreshold, feeBudgetRemainingFn, time.Now)
+}
+
+func makeOpGovernor(
+	maxOpsPerMinute int,
+	maxTxsPerHour int,
+	feeBudgetThreshold float64,
+	feeBudgetRemainingFn func() float64,
+	now func() time.Time,
+) (*OpGovernor, error) {
+	if maxOpsPerMinute < 0 {
+		return nil, fmt.Errorf("max ops per minute needs to be >= 0, was %d", maxOpsPerMinute)
+	}
+	if maxTxsPerHour < 0 {
+		return nil, fmt.Errorf("max txs per hour needs to be >= 0, was %d", maxTxsPerHour)
+	}
+	if feeBudgetThreshold < 0.0 || feeBudgetThreshold >= 1.0 {
+		return nil, fmt.Errorf("fee budget threshold needs to be >= 0.0 and < 1.0, was %.4f", feeBudgetThreshold)
+	}
+
+	return &OpGovernor{
+		maxOpsPerMinute:      maxOpsPerMinute,
+		maxTxsPerHour:        maxTxsPerHour,
+		feeBudgetThreshold:   feeBudgetThreshold,
+		feeBudgetRemainingFn: feeBudgetRemainingFn,
+		now:                  now,
+		mutex:                &sync.Mutex{},
+		submissions:          []opSubmission{},
+	}, nil
+}
+
+// Record counts a transaction with numOps ops that was submitted to the network towards the limits, this is called for every
+// transaction that is submitted including fee bump transactions and transactions submitted from channel accounts
+func (g *OpGovernor) Record(numOps int) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	g.submissions = append(g.submissions, opSubmission{time: g.now(), numOps: numOps})
+}
+
+// Govern returns the ops that can be submitted now, which keeps them in the same order as they were passed in. The selling and buying
+// offers are our current offers on the book, which are used to find the ops that update the best offer on each side
+func (g *OpGovernor) Govern(
+	ops []txnbuild.Operation,
+	sellingOffers []hProtocol.Offer,
+	buyingOffers []hProtocol.Offer,
+	baseAsset hProtocol.Asset,
+	quoteAsset hProtocol.Asset,
+) ([]txnbuild.Operation, error) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	now := g.now()
+
+	// drop submissions that are older than the longest window
+	firstInWindow := 0
+	for firstInWindow < len(g.submissions) && now.Sub(g.submissions[firstInWindow].time) >= time.Hour {
+		firstInWindow++
+	}
+	g.submissions = g.submissions[firstInWindow:]
+
+	numOpsLastMinute := 0
+	for _, s := range g.submissions {
+		if now.Sub(s.time) < time.Minute {
+			numOpsLastMinute += s.numOps
+		}
+	}
+
+	maxPriority := opPriorityOther
+	if g.maxTxsPerHour > 0 && len(g.submissions) >= g.maxTxsPerHour {
+		log.Printf("op governor: submitted %d txs in the last hour which reaches the max of %d, submitting only delete ops\n", len(g.submissions), g.maxTxsPerHour)
+		maxPriority = opPriorityDelete
+	} else if g.feeBudgetRemainingFn != nil {
+		if remaining := g.feeBudgetRemainingFn(); remaining < g.feeBudgetThreshold {
+			log.Printf("op governor: %.4f of the daily fee budget is left which is below the threshold of %.4f, submitting only delete and top-of-book ops\n", remaining, g.feeBudgetThreshold)
+			maxPriority = opPriorityTopOfBook
+		}
+	}
+
+	opCapacity := -1
+	if g.maxOpsPerMinute > 0 {
+		opCapacity = g.maxOpsPerMinute - numOpsLastMinute
+		if opCapacity < 0 {
+			opCapacity = 0
+		}
+	}
+
+	priorities, e := opPriorities(ops, sellingOffers, buyingOffers, baseAsset, quoteAsset)
+	if e != nil {
+		return nil, fmt.Errorf("could not compute priorities of ops: %s", e)
+	}
+	keptOps := selectOpsByPriority(ops, priorities, maxPriority, opCapacity)
+	if len(keptOps) < len(ops) {
+		log.Printf("op governor: holding back %d of %d ops (submitted %d ops in the last minute, max is %d)\n", len(ops)-len(keptOps), len(ops), numOpsLastMinute, g.maxOpsPerMinute)
+	}
+	return keptOps, nil
+}
+
+// selectOpsByPriority keeps all delete ops and then as many ops of each following priority up to maxPriority as the capacity allows,
+// where a negative capacity is unlimited
+func selectOpsByPriority(ops []txnbuild.Operation, priorities []opPriority, maxPriority opPriority, opCapacity int) []txnbuild.Operation {
+	keep := make([]bool, len(ops))
+	numKept := 0
+	for p := opPriorityDelete; p <= maxPriority; p++ {
+		for i := range ops {
+			if priorities[i] != p {
+				continue
+			}
+			if p != opPriorityDelete && opCapacity >= 0 && numKept >= opCapacity {
+				break
+			}
+			keep[i] = true
+			numKept++
+		}
+	}
+
+	keptOps := []txnbuild.Operation{}
+	for i, op := range ops {
+		if keep[i] {
+			keptOps = append(keptOps, op)
+		}
+	}
+	return keptOps
+}
+
+// opPriorities returns the priority of each op. The top-of-book ops on each side are the ops that update our current best offer on that
+// side or that are priced at least as well as it, and the op with the best price on that side when we have no offers on that side
+func opPriorities(
+	ops []txnbuild.Operation,
+	sellingOffers []hProtocol.Offer,
+	buyingOffers []hProtocol.Offer,
+	baseAsset hProtocol.Asset,
+	quoteAsset hProtocol.Asset,
+) ([]opPriority, error) {
+	bestAsk, e := bestOwnOffer(sellingOffers, true)
+	if e != nil {
+		return nil, fmt.Errorf("could not find our best selling offer: %s", e)
+	}
+	bestBid, e := bestOwnOffer(buyingOffers, false)
+	if e != nil {
+		return nil, fmt.Errorf("could not find our best buying offer: %s", e)
+	}
+
+	priorities := make([]opPriority, len(ops))
+	bestAskOpIdx, bestBidOpIdx := -1, -1
+	var bestAskOpPrice, bestBidOpPrice float64
+	for i, op := range ops {
+		priorities[i] = opPriorityOther
+
+		var amount string
+		var offerID int64
+		switch o := op.(type) {
+		case *txnbuild.ManageSellOffer:
+			amount, offerID = o.Amount, o.OfferID
+		case *txnbuild.ManageBuyOffer:
+			amount, offerID = o.Amount, o.OfferID
+		default:
+			continue
+		}
+		if isDeleteAmount(amount) {
+			priorities[i] = opPriorityDelete
+			continue
+		}
+
+		isSell, price, e := opSidePrice(op, baseAsset, quoteAsset)
+		if e != nil {
+			return nil, e
+		}
+		if isSell && bestAsk != nil {
+			if offerID == bestAsk.id || price <= bestAsk.price {
+				priorities[i] = opPriorityTopOfBook
+			}
+		} else if !isSell && bestBid != nil {
+			if offerID == bestBid.id || price >= bestBid.price {
+				priorities[i] = opPriorityTopOfBook
+			}
+		} else if isSell && (bestAskOpIdx < 0 || price < bestAskOpPrice) {
+			bestAskOpIdx, bestAskOpPrice = i, price
+		} else if !isSell && (bestBidOpIdx < 0 || price > bestBidOpPrice) {
+			bestBidOpIdx, bestBidOpPrice = i, price
+		}
+	}
+
+	if bestAskOpIdx >= 0 {
+		priorities[bestAskOpIdx] = opPriorityTopOfBook
+	}
+	if bestBidOpIdx >= 0 {
+		priorities[bestBidOpIdx] = opPriorityTopOfBook
+	}
+	return priorities, nil
+}
+
+// ownOffer is one of our offers with its price in units of the quote asset per unit of the base asset
+type ownOffer struct {
+	id    int64
+	price float64
+}
+
+// bestOwnOffer returns our offer with the best price on one side of the book, or nil if there are no offers
+func bestOwnOffer(offers []hProtocol.Offer, isSell bool) (*ownOffer, error) {
+	var best *ownOffer
+	for _, o := range offers {
+		price, e := strconv.ParseFloat(o.Price, 64)
+		if e != nil {
+			return nil, fmt.Errorf("could not parse price of offer %d (%s): %s", o.ID, o.Price, e)
+		}
+		if price <= 0.0 {
+			continue
+		}
+		// the price of an offer that buys the base asset is in units of base per unit of quote
+		if !isSell {
+			price = 1 / price
+		}
+
+		if best == nil || (isSell && price < best.price) || (!isSell && price > best.price) {
+			best = &ownOffer{id: o.ID, price: price}
+		}
+	}
+	return best, nil
+}
+
+// opSidePrice returns whether the offer op sells the base asset and its price in units of the quote asset per unit of the base asset
+func opSidePrice(op txnbuild.Operation, baseAsset hProtocol.Asset, quoteAsset hProtocol.Asset) (bool, float64, error) {
+	var isSell, isPriceInverted bool
+	var priceString string
+	var e error
+	switch o := op.(type) {
+	case *txnbuild.ManageSellOffer:
+		isSell, e = utils.IsSelling(baseAsset, quoteAsset, o.Selling, o.Buying)
+		if e != nil {
+			return false, 0.0, fmt.Errorf("error when running the isSelling check for op '%+v': %s", *o, e)
+		}
+		// the price of a ManageSellOffer is in units of the buying asset per unit of the selling asset
+		isPriceInverted = !isSell
+		priceString = o.Price
+	case *txnbuild.ManageBuyOffer:
+		// a buy offer that buys the base asset is selling quote, so we check it the other way around
+		isBuyingBase, e := utils.IsSelling(baseAsset, quoteAsset, o.Buying, o.Selling)
+		if e != nil {
+			return false, 0.0, fmt.Errorf("error when running the isSelling check for op '%+v': %s", *o, e)
+		}
+		// the price of a ManageBuyOffer is in units of the selling asset per unit of the buying asset
+		isSell = !isBuyingBase
+		isPriceInverted = isSell
+		priceString = o.Price
+	default:
+		return false, 0.0, fmt.Errorf("op is not an offer: %+v", op)
+	}
+
+	price, e := strconv.ParseFloat(priceString, 64)
+	if e != nil {
+		return false, 0.0, fmt.Errorf("could not convert price (%s) to float: %s", priceString, e)
+	}
+	if price <= 0.0 {
+		return false, 0.0, fmt.Errorf("invalid price of op: %s", priceString)
+	}
+	if isPriceInverted {
+		price = 1 / price
+	}
+	return isSell, price, nil
+}
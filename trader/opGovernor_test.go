@@ -0,0 +1,135 @@
+package trader
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	hProtocol "github.com/stellar/go/protocols/horizon"
+	"github.com/stellar/go/txnbuild"
+	"github.com/stellar/kelp/support/utils"
+)
+
+var testGovernorQuote = txnbuild.CreditAsset{Code: "QUOTE", Issuer: "GBGQAGAMK6W6FH6AGGZ2BI2MY5TA5VJEHU2DQRFXACMAZHNRD3SXEV6Z"}
+
+// makeGovernorSellOp sells the base asset at a price in units of quote per unit of base
+func makeGovernorSellOp(price float64) *txnbuild.ManageSellOffer {
+	return &txnbuild.ManageSellOffer{Selling: txnbuild.NativeAsset{}, Buying: testGovernorQuote, Amount: "10.0000000", Price: fmt.Sprintf("%.7f", price)}
+}
+
+// makeGovernorBuyOp buys the base asset at a price in units of quote per unit of base
+func makeGovernorBuyOp(price float64) *txnbuild.ManageSellOffer {
+	return &txnbuild.ManageSellOffer{Selling: testGovernorQuote, Buying: txnbuild.NativeAsset{}, Amount: "10.0000000", Price: fmt.Sprintf("%.7f", 1/price)}
+}
+
+func TestOpPriorities(t *testing.T) {
+	base := utils.Asset2Asset2(txnbuild.NativeAsset{})
+	quote := utils.Asset2Asset2(testGovernorQuote)
+	ops := []txnbuild.Operation{
+		// buys base at 0.95 quote per base
+		&txnbuild.ManageBuyOffer{Selling: testGovernorQuote, Buying: txnbuild.NativeAsset{}, Amount: "10.0000000", Price: "0.9500000"},
+		makeGovernorBuyOp(0.9),
+		// sells base at 1.05 quote per base
+		&txnbuild.ManageBuyOffer{Selling: txnbuild.NativeAsset{}, Buying: testGovernorQuote, Amount: "10.0000000", Price: fmt.Sprintf("%.7f", 1/1.05)},
+		makeGovernorSellOp(1.1),
+		&txnbuild.ManageSellOffer{Selling: txnbuild.NativeAsset{}, Buying: testGovernorQuote, Amount: "0", Price: "1.2000000", OfferID: 1},
+	}
+	// updates our current best bid (offer 11) to a worse price
+	updateBestBidOp := makeGovernorBuyOp(0.85)
+	updateBestBidOp.OfferID = 11
+
+	testCases := []struct {
+		name           string
+		ops            []txnbuild.Operation
+		sellingOffers  []hProtocol.Offer
+		buyingOffers   []hProtocol.Offer
+		wantPriorities []opPriority
+	}{
+		{
+			name:           "best op on each side without offers",
+			ops:            ops,
+			wantPriorities: []opPriority{opPriorityTopOfBook, opPriorityOther, opPriorityTopOfBook, opPriorityOther, opPriorityDelete},
+		}, {
+			name: "ops at least as good as the best offers",
+			ops:  ops,
+			// our best ask is at 1.1 and our best bid is at 0.9, offer prices on the buying side are in units of base per unit of quote
+			sellingOffers:  []hProtocol.Offer{{ID: 10, Price: "1.1000000"}, {ID: 12, Price: "1.2000000"}},
+			buyingOffers:   []hProtocol.Offer{{ID: 11, Price: fmt.Sprintf("%.7f", 1/0.9)}, {ID: 13, Price: fmt.Sprintf("%.7f", 1/0.8)}},
+			wantPriorities: []opPriority{opPriorityTopOfBook, opPriorityTopOfBook, opPriorityTopOfBook, opPriorityTopOfBook, opPriorityDelete},
+		}, {
+			name: "ops behind the best offers",
+			ops:  ops,
+			// our best ask is at 1.0 and our best bid is at 0.97
+			sellingOffers:  []hProtocol.Offer{{ID: 10, Price: "1.0000000"}},
+			buyingOffers:   []hProtocol.Offer{{ID: 11, Price: fmt.Sprintf("%.7f", 1/0.97)}},
+			wantPriorities: []opPriority{opPriorityOther, opPriorityOther, opPriorityOther, opPriorityOther, opPriorityDelete},
+		}, {
+			name:           "op that updates the best offer",
+			ops:            []txnbuild.Operation{makeGovernorBuyOp(0.8), updateBestBidOp},
+			buyingOffers:   []hProtocol.Offer{{ID: 11, Price: fmt.Sprintf("%.7f", 1/0.9)}},
+			wantPriorities: []opPriority{opPriorityOther, opPriorityTopOfBook},
+		},
+	}
+
+	for _, k := range testCases {
+		t.Run(k.name, func(t *testing.T) {
+			priorities, e := opPriorities(k.ops, k.sellingOffers, k.buyingOffers, base, quote)
+			if !assert.NoError(t, e) {
+				return
+			}
+			assert.Equal(t, k.wantPriorities, priorities)
+		})
+	}
+}
+
+func TestOpGovernor(t *testing.T) {
+	now := time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)
+	feeBudgetRemaining := 1.0
+	g, e := makeOpGovernor(4, 3, 0.2, func() float64 { return feeBudgetRemaining }, func() time.Time { return now })
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	ops := []txnbuild.Operation{
+		&txnbuild.ManageSellOffer{Selling: txnbuild.NativeAsset{}, Buying: testGovernorQuote, Amount: "0", Price: "1.3000000", OfferID: 1},
+		makeGovernorSellOp(1.2),
+		makeGovernorSellOp(1.1),
+		makeGovernorBuyOp(0.9),
+		makeGovernorBuyOp(0.8),
+	}
+
+	// each step advances the clock by the elapsed duration before governing the ops, and records the kept ops as a transaction
+	steps := []struct {
+		elapsed            time.Duration
+		feeBudgetRemaining float64
+		wantIndexes        []int
+	}{
+		// deletes, then the top of the book, then the rest in order up to 4 ops per minute
+		{elapsed: 0, feeBudgetRemaining: 1.0, wantIndexes: []int{0, 1, 2, 3}},
+		// no capacity left in this minute so only deletes go through
+		{elapsed: 10 * time.Second, feeBudgetRemaining: 1.0, wantIndexes: []int{0}},
+		// the fee budget is tight so only deletes and the top of the book go through
+		{elapsed: time.Minute, feeBudgetRemaining: 0.1, wantIndexes: []int{0, 2, 3}},
+		// reached 3 txs in the last hour so only deletes go through
+		{elapsed: time.Minute, feeBudgetRemaining: 1.0, wantIndexes: []int{0}},
+		{elapsed: time.Hour, feeBudgetRemaining: 1.0, wantIndexes: []int{0, 1, 2, 3}},
+	}
+
+	for i, s := range steps {
+		now = now.Add(s.elapsed)
+		feeBudgetRemaining = s.feeBudgetRemaining
+		actual, e := g.Govern(ops, nil, nil, utils.Asset2Asset2(txnbuild.NativeAsset{}), utils.Asset2Asset2(testGovernorQuote))
+		if !assert.NoError(t, e, "step %d", i) {
+			return
+		}
+
+		wantOps := []txnbuild.Operation{}
+		for _, idx := range s.wantIndexes {
+			wantOps = append(wantOps, ops[idx])
+		}
+		assert.Equal(t, wantOps, actual, "step %d", i)
+		g.Record(len(actual))
+	}
+}
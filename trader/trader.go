@@ -46,6 +46,9 @@ type Trader struct {
 	fixedIterations                *uint64
 	dataKey                        *model.BotKey
 	alert                          api.Alert
+	killSwitch                     *KillSwitch     // can be nil
+	circuitBreaker                 *CircuitBreaker // can be nil
+	opGovernor                     *OpGovernor     // can be nil
 	metricsTracker                 *plugins.MetricsTracker
 	startTime                      time.Time
 
@@ -85,6 +88,9 @@ func MakeTrader(
 	fixedIterations *uint64,
 	dataKey *model.BotKey,
 	alert api.Alert,
+	killSwitch *KillSwitch,
+	circuitBreaker *CircuitBreaker,
+	opGovernor *OpGovernor,
 	metricsTracker *plugins.MetricsTracker,
 	startTime time.Time,
 ) *Trader {
@@ -111,6 +117,9 @@ func MakeTrader(
 		fixedIterations:                fixedIterations,
 		dataKey:                        dataKey,
 		alert:                          alert,
+		killSwitch:                     killSwitch,
+		circuitBreaker:                 circuitBreaker,
+		opGovernor:                     opGovernor,
 		metricsTracker:                 metricsTracker,
 		startTime:                      startTime,
 		// initialized runtime vars
@@ -401,10 +410,29 @@ func (t *Trader) update() plugins.UpdateLoopResult {
 		}
 	}
 
+	if t.killSwitch != nil && t.checkKillSwitch() {
+		return plugins.UpdateLoopResult{
+			Success:            false,
+			NumPruneOps:        numPruneOps,
+			NumUpdateOpsDelete: numUpdateOpsDelete,
+			NumUpdateOpsUpdate: numUpdateOpsUpdate,
+			NumUpdateOpsCreate: numUpdateOpsCreate,
+		}
+	}
+
 	pair := &model.TradingPair{
 		Base:  model.FromHorizonAsset(t.assetBase),
 		Quote: model.FromHorizonAsset(t.assetQuote),
 	}
+	if t.circuitBreaker != nil && t.checkCircuitBreaker(pair) {
+		return plugins.UpdateLoopResult{
+			Success:            false,
+			NumPruneOps:        numPruneOps,
+			NumUpdateOpsDelete: numUpdateOpsDelete,
+			NumUpdateOpsUpdate: numUpdateOpsUpdate,
+			NumUpdateOpsCreate: numUpdateOpsCreate,
+		}
+	}
 	log.Printf("orderConstraints for trading pair %s: %s", pair, t.exchangeShim.GetOrderConstraints(pair))
 
 	// TODO 2 streamline the request data instead of caching
@@ -533,6 +561,28 @@ func (t *Trader) update() plugins.UpdateLoopResult {
 		}
 	}
 
+	if t.sdex != nil && t.sdex.IsFeeBudgetExhausted() {
+		// deleting offers reduces our exposure so we still allow those, everything else waits until the fee budget resets
+		deleteOps := keepDeleteOps(ops)
+		log.Printf("daily fee budget is exhausted, pausing non-essential updates: submitting only the %d delete ops out of %d ops\n", len(deleteOps), len(ops))
+		ops = deleteOps
+	}
+
+	if t.opGovernor != nil {
+		ops, e = t.opGovernor.Govern(ops, t.sellingAOffers, t.buyingAOffers, t.assetBase, t.assetQuote)
+		if e != nil {
+			log.Println(e)
+			t.deleteAllOffers(false)
+			return plugins.UpdateLoopResult{
+				Success:            false,
+				NumPruneOps:        numPruneOps,
+				NumUpdateOpsDelete: numUpdateOpsDelete,
+				NumUpdateOpsUpdate: numUpdateOpsUpdate,
+				NumUpdateOpsCreate: numUpdateOpsCreate,
+			}
+		}
+	}
+
 	log.Printf("created %d operations to update existing offers\n*****************trader.update - details: %s", len(ops), ops)
 	if len(ops) > 0 {
 		//if creating submitting an offer with no ooffer id, swap it out with a passivesell
@@ -636,6 +686,129 @@ func (t *Trader) setBalances(baseBalance *api.Balance, quoteBalance *api.Balance
 	}
 }
 
+// accountValue returns the value of the base and quote balances using the dollar value feeds
+func (t *Trader) accountValue() (float64, error) {
+	baseUsdPrice, e := t.valueBaseFeed.GetPrice()
+	if e != nil {
+		return 0.0, fmt.Errorf("could not get price of base asset from dollar value feed: %s", e)
+	}
+	quoteUsdPrice, e := t.valueQuoteFeed.GetPrice()
+	if e != nil {
+		return 0.0, fmt.Errorf("could not get price of quote asset from dollar value feed: %s", e)
+	}
+	return (t.maxAssetA * baseUsdPrice) + (t.maxAssetB * quoteUsdPrice), nil
+}
+
+// checkKillSwitch returns true when the kill switch is tripped and no orders should be placed in this update cycle
+func (t *Trader) checkKillSwitch() bool {
+	isTripped, e := t.killSwitch.IsTripped()
+	if e != nil {
+		log.Printf("kill switch: %s\n", e)
+	}
+
+	if !isTripped {
+		value, e := t.accountValue()
+		if e != nil {
+			// a missing value should not trip the kill switch, the strategy will fail on its own if the feeds are broken
+			log.Printf("kill switch: unable to compute the account value, skipping drawdown check: %s\n", e)
+			return false
+		}
+
+		reason, e := t.killSwitch.Update(value)
+		if e != nil {
+			log.Printf("kill switch: %s\n", e)
+		}
+		if reason == "" {
+			return false
+		}
+
+		log.Printf("kill switch: tripped, deleting all offers and refusing to place new orders until re-armed: %s\n", reason)
+		if t.alert != nil {
+			e = t.alert.Trigger(fmt.Sprintf("kelp kill switch tripped: %s", reason), map[string]interface{}{
+				"tradingPair": fmt.Sprintf("%s/%s", utils.Asset2String(t.assetBase), utils.Asset2String(t.assetQuote)),
+				"value":       value,
+			})
+			if e != nil {
+				log.Printf("kill switch: unable to trigger alert: %s\n", e)
+			}
+		}
+	} else {
+		log.Printf("kill switch: tripped, not placing any orders until re-armed\n")
+	}
+
+	if len(t.sellingAOffers) > 0 || len(t.buyingAOffers) > 0 {
+		// the bot keeps running so it can be re-armed from the control file, offers left over on a failure are pulled in the next cycle
+		e = t.pullAllOffers()
+		if e != nil {
+			log.Printf("kill switch: %s\n", e)
+		}
+	}
+	return true
+}
+
+// checkCircuitBreaker returns true when the circuit breaker is halted and no orders should be placed in this update cycle
+func (t *Trader) checkCircuitBreaker(pair *model.TradingPair) bool {
+	price, e := t.circuitBreakerPrice(pair)
+	if e != nil {
+		// a missing price does not halt quoting, the strategy will fail on its own if the feeds are broken
+		log.Printf("circuit breaker: unable to fetch price, skipping check: %s\n", e)
+		return false
+	}
+
+	isHalted, reason := t.circuitBreaker.Update(price)
+	if !isHalted {
+		return false
+	}
+	if reason != "" {
+		log.Printf("circuit breaker: tripped, halting quoting until %s: %s\n", t.circuitBreaker.haltedUntil.Format(time.RFC3339), reason)
+	} else {
+		log.Printf("circuit breaker: halted, not quoting until %s\n", t.circuitBreaker.haltedUntil.Format(time.RFC3339))
+	}
+
+	if t.circuitBreaker.ShouldDeleteOffers() && (len(t.sellingAOffers) > 0 || len(t.buyingAOffers) > 0) {
+		e = t.pullAllOffers()
+		if e != nil {
+			log.Println(e)
+			t.deleteAllOffers(false)
+		}
+	}
+	return true
+}
+
+// circuitBreakerPrice is the price from the reference feed of the circuit breaker, or the mid price of the market if it has no feed
+func (t *Trader) circuitBreakerPrice(pair *model.TradingPair) (float64, error) {
+	if t.circuitBreaker.HasFeed() {
+		return t.circuitBreaker.FeedPrice()
+	}
+
+	ob, e := t.exchangeShim.GetOrderBook(pair, 1)
+	if e != nil {
+		return 0.0, fmt.Errorf("could not fetch orderbook: %s", e)
+	}
+	topBid, topAsk := ob.TopBid(), ob.TopAsk()
+	if topBid == nil || topAsk == nil {
+		return 0.0, fmt.Errorf("orderbook needs both bids and asks to compute the mid price")
+	}
+	return (topBid.Price.AsFloat() + topAsk.Price.AsFloat()) / 2, nil
+}
+
+// pullAllOffers deletes all offers for the bot without exiting, unlike deleteAllOffers
+func (t *Trader) pullAllOffers() error {
+	dOps := []txnbuild.Operation{}
+	dOps = append(dOps, t.sdex.DeleteAllOffers(t.sellingAOffers)...)
+	dOps = append(dOps, t.sdex.DeleteAllOffers(t.buyingAOffers)...)
+	log.Printf("created %d operations to pull all offers\n", len(dOps))
+
+	// to delete offers the submitMode doesn't matter, so use api.SubmitModeBoth as the default
+	e := t.exchangeShim.SubmitOps(api.ConvertOperation2TM(dOps), api.SubmitModeBoth, nil)
+	if e != nil {
+		return fmt.Errorf("could not submit ops to pull all offers: %s", e)
+	}
+	t.sellingAOffers = []hProtocol.Offer{}
+	t.buyingAOffers = []hProtocol.Offer{}
+	return nil
+}
+
 func (t *Trader) getExistingOffers() ([]hProtocol.Offer /*sellingAOffers*/, []hProtocol.Offer /*buyingAOffers*/, error) {
 	offers, e := t.exchangeShim.LoadOffersHack()
 	if e != nil {
@@ -679,6 +852,33 @@ func countOfferChangeTypes(offers []*txnbuild.ManageSellOffer) (int /*numDelete*
 	return numDelete, numUpdate, numCreate, nil
 }
 
+// keepDeleteOps returns only the ops that delete an existing offer
+func keepDeleteOps(ops []txnbuild.Operation) []txnbuild.Operation {
+	deleteOps := []txnbuild.Operation{}
+	for _, op := range ops {
+		var amount string
+		switch o := op.(type) {
+		case *txnbuild.ManageSellOffer:
+			amount = o.Amount
+		case *txnbuild.ManageBuyOffer:
+			amount = o.Amount
+		default:
+			continue
+		}
+
+		if isDeleteAmount(amount) {
+			deleteOps = append(deleteOps, op)
+		}
+	}
+	return deleteOps
+}
+
+// isDeleteAmount returns true if the amount of an op is zero, which deletes the offer
+func isDeleteAmount(amount string) bool {
+	opAmount, e := strconv.ParseFloat(amount, 64)
+	return e == nil && opAmount == 0
+}
+
 func countSellOfferChangeTypes(offers []build.TransactionMutator) (int /*numDelete*/, int /*numUpdate*/, int /*numCreate*/, error) {
 	numDelete, numUpdate, numCreate := 0, 0, 0
 	for i, o := range offers {
@@ -697,6 +897,11 @@ func countSellOfferChangeTypes(offers []build.TransactionMutator) (int /*numDele
 			} else {
 				mso = api.ConvertMOB2MSO(*mob)
 			}
+		} else if mbob, ok := o.(api.ManageBuyOfferBuilder); ok {
+			// only the amount and offerID are used below so we can count the buy offer using the same fields
+			mso = &txnbuild.ManageSellOffer{Amount: mbob.Op.Amount, OfferID: mbob.Op.OfferID}
+		} else if mbob, ok := o.(*api.ManageBuyOfferBuilder); ok {
+			mso = &txnbuild.ManageSellOffer{Amount: mbob.Op.Amount, OfferID: mbob.Op.OfferID}
 		}
 
 		if passiveSellOffer != nil {
@@ -2,15 +2,20 @@ package trader
 
 import (
 	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 
+	"github.com/stellar/go/build"
 	hProtocol "github.com/stellar/go/protocols/horizon"
 	"github.com/stellar/go/txnbuild"
 	"github.com/stellar/kelp/api"
 	"github.com/stellar/kelp/model"
+	"github.com/stellar/kelp/plugins"
 )
 
 func TestIsStateSynchronized(t *testing.T) {
@@ -346,3 +351,80 @@ func createTestMSO(msoType string) *txnbuild.ManageSellOffer {
 	}
 	return &mso
 }
+
+// testOffersExchangeShim serves a fixed set of offers until they are deleted, any other method of the ExchangeShim panics
+type testOffersExchangeShim struct {
+	api.ExchangeShim
+	offers       []hProtocol.Offer
+	submitErr    error
+	submittedOps int
+}
+
+func (s *testOffersExchangeShim) SubmitOps(ops []build.TransactionMutator, submitMode api.SubmitMode, asyncCallback func(hash string, e error)) error {
+	if s.submitErr != nil {
+		return s.submitErr
+	}
+	s.submittedOps += len(ops)
+	s.offers = []hProtocol.Offer{}
+	return nil
+}
+
+func (s *testOffersExchangeShim) GetBalanceHack(asset hProtocol.Asset) (*api.Balance, error) {
+	return &api.Balance{Balance: 100.0, Trust: 100.0}, nil
+}
+
+func (s *testOffersExchangeShim) LoadOffersHack() ([]hProtocol.Offer, error) {
+	return s.offers, nil
+}
+
+func TestUpdateKillSwitchTripped(t *testing.T) {
+	dir, e := ioutil.TempDir("", "kelp_kill_switch")
+	if !assert.NoError(t, e) {
+		return
+	}
+	defer os.RemoveAll(dir)
+	controlFile := filepath.Join(dir, "control.txt")
+	e = ioutil.WriteFile(controlFile, []byte("tripped\n"), 0644)
+	if !assert.NoError(t, e) {
+		return
+	}
+	killSwitch, e := MakeKillSwitch(0.1, KillSwitchHighResetSession, controlFile)
+	if !assert.NoError(t, e) {
+		return
+	}
+
+	base := hProtocol.Asset{Type: "native"}
+	quote := hProtocol.Asset{Type: "credit_alphanum12", Code: "QUOTE", Issuer: "GBGQAGAMK6W6FH6AGGZ2BI2MY5TA5VJEHU2DQRFXACMAZHNRD3SXEV6Z"}
+	shim := &testOffersExchangeShim{
+		offers: []hProtocol.Offer{
+			hProtocol.Offer{ID: 1, Selling: base, Buying: quote, Amount: "10.0", Price: "2.0"},
+			hProtocol.Offer{ID: 2, Selling: quote, Buying: base, Amount: "10.0", Price: "0.4"},
+		},
+		submitErr: fmt.Errorf("submit failed"),
+	}
+	// the strategy and the circuit breaker are nil so the test panics if the update cycle is not skipped
+	trader := &Trader{
+		assetBase:    base,
+		assetQuote:   quote,
+		sdex:         &plugins.SDEX{},
+		exchangeShim: shim,
+		killSwitch:   killSwitch,
+	}
+
+	// a failure to delete the offers does not exit, the offers are pulled in the next cycle
+	result := trader.update()
+	assert.False(t, result.Success)
+	assert.Equal(t, 0, shim.submittedOps)
+	assert.Equal(t, 2, len(shim.offers))
+
+	shim.submitErr = nil
+	result = trader.update()
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, shim.submittedOps)
+	assert.Equal(t, 0, len(shim.offers))
+
+	// cycles are skipped without submitting anything while there are no offers left
+	result = trader.update()
+	assert.False(t, result.Success)
+	assert.Equal(t, 2, shim.submittedOps)
+}